@@ -0,0 +1,38 @@
+// Command mockoidc runs a local OpenID Connect provider that signs everyone in as one user,
+// so the social login flow can be exercised without a real identity provider.
+//
+//	go run ./cmd/mockoidc -addr :9999 -email jane@example.com
+package main
+
+import (
+	"Blog_API/pkg/oidc"
+	"flag"
+	"log"
+	"net/http"
+)
+
+func main() {
+	addr := flag.String("addr", ":9999", "listen address")
+	issuer := flag.String("issuer", "http://localhost:9999", "issuer URL, must match the provider config of the API")
+	clientID := flag.String("client-id", "blog-api", "client id the API is configured with")
+	subject := flag.String("sub", "mock-user-1", "subject of the signed in user")
+	email := flag.String("email", "mock.user@example.com", "email of the signed in user")
+	emailVerified := flag.Bool("email-verified", true, "whether the email is reported as verified")
+	givenName := flag.String("given-name", "Mock", "given name of the signed in user")
+	familyName := flag.String("family-name", "User", "family name of the signed in user")
+	flag.Parse()
+
+	provider, err := oidc.NewMockProvider(*issuer, *clientID, oidc.MockUser{
+		Subject:       *subject,
+		Email:         *email,
+		EmailVerified: *emailVerified,
+		GivenName:     *givenName,
+		FamilyName:    *familyName,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("mock OIDC provider %s listening on %s", *issuer, *addr)
+	log.Fatal(http.ListenAndServe(*addr, provider))
+}
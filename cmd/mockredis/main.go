@@ -0,0 +1,23 @@
+// Command mockredis runs a local stand-in for Redis that speaks enough of its protocol for the
+// response cache, so CACHEBACKEND=redis can be tried without a Redis server.
+//
+//	go run ./cmd/mockredis -addr :6379
+package main
+
+import (
+	"Blog_API/pkg/cache"
+	"flag"
+	"log"
+)
+
+func main() {
+	addr := flag.String("addr", ":6379", "listen address")
+	capacity := flag.Int("capacity", 10000, "keys kept before the least recently used ones are evicted")
+	password := flag.String("password", "", "password clients have to AUTH with, none when empty")
+	flag.Parse()
+
+	server := cache.NewServer(*capacity, *password)
+
+	log.Printf("mock Redis listening on %s", *addr)
+	log.Fatal(server.ListenAndServe(*addr))
+}
@@ -0,0 +1,39 @@
+// Command reconcile recounts the comments and reactions of every blog post, the reactions per type
+// too, against the counters stored on the posts and lists the counters that drifted. It reads the
+// same configuration as the server. With -fix the drifted counters are set to the recount.
+//
+//	go run ./cmd/reconcile -fix
+package main
+
+import (
+	"Blog_API/pkg/config"
+	"Blog_API/pkg/connection"
+	"Blog_API/pkg/repositories"
+	"Blog_API/pkg/services"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+func main() {
+	fix := flag.Bool("fix", false, "set the drifted counters to the recount")
+	flag.Parse()
+
+	config.SetConfig()
+	blogRepo := repositories.NewBlogRepo(connection.GetDB())
+
+	discrepancies, err := services.ReconcileCounters(blogRepo, *fix)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, discrepancy := range discrepancies {
+		fmt.Printf("%s\t%s\tstored %d\tcounted %d\n", discrepancy.BlogPostID, services.CounterName(discrepancy), discrepancy.Stored, discrepancy.Actual)
+	}
+
+	// A check without -fix fails when anything drifted, so it can gate a script
+	if len(discrepancies) > 0 && !*fix {
+		os.Exit(1)
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	_ "Blog_API/docs"
 	"Blog_API/pkg/containers"
+	"Blog_API/pkg/utils/consts"
 	"Blog_API/pkg/utils/response"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -24,7 +25,13 @@ import (
 // @BasePath /blog_api/v1
 func main() {
 	e := echo.New()
-	e.Use(middleware.CORS())
+	// The client address is the peer of the connection, X-Forwarded-For is set by the client and
+	// only believed from the proxies in TRUSTEDPROXIES
+	e.IPExtractor = echo.ExtractIPDirect()
+	// Browsers only hand ETag to scripts when it is exposed, and writes need it back as If-Match
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		ExposeHeaders: []string{consts.HeaderETag},
+	}))
 	e.Pre(m.RemoveTrailingSlash())
 	e.Use(m.LoggerWithConfig(m.LoggerConfig{
 		Format:           `${time_custom} ${remote_ip} ${host} ${method} ${uri} ${status} ${latency_human} ${bytes_in} ${bytes_out} "${user_agent}"` + "\n",
@@ -0,0 +1,12 @@
+package cache
+
+import "time"
+
+// Store is a key value store for cached responses. Implementations are safe for concurrent use;
+// a miss is reported with false, errors are left for failures of the backend itself.
+type Store interface {
+	Get(key string) ([]byte, bool, error)
+	// Set stores the value, a non-positive ttl keeps it until it is evicted or deleted
+	Set(key string, value []byte, ttl time.Duration) error
+	Delete(keys ...string) error
+}
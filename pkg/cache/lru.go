@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time // zero when the entry does not expire
+}
+
+// LRU is an in-memory Store holding at most capacity entries, the least recently used entry is
+// evicted first. Expired entries are dropped when they are read or reach the back of the list.
+type LRU struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // front is the most recently used
+	entries  map[string]*list.Element
+	now      func() time.Time
+}
+
+func NewLRU(capacity int) *LRU {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LRU{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+// Get implements Store.
+func (l *LRU) Get(key string) ([]byte, bool, error) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.lookup(key)
+	if !ok {
+		return nil, false, nil
+	}
+
+	return append([]byte(nil), entry.value...), true, nil
+}
+
+// Set implements Store.
+func (l *LRU) Set(key string, value []byte, ttl time.Duration) error {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.set(key, append([]byte(nil), value...), ttl)
+
+	return nil
+}
+
+// Delete implements Store.
+func (l *LRU) Delete(keys ...string) error {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := l.entries[key]; ok {
+			l.remove(element)
+		}
+	}
+
+	return nil
+}
+
+// Len returns the number of entries held, expired ones that were not dropped yet included
+func (l *LRU) Len() int {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.order.Len()
+}
+
+// Flush drops all entries
+func (l *LRU) Flush() {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.order.Init()
+	l.entries = make(map[string]*list.Element)
+}
+
+// lookup returns the live entry of key and marks it as most recently used, the caller holds the lock
+func (l *LRU) lookup(key string) (*lruEntry, bool) {
+
+	element, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && !l.now().Before(entry.expiresAt) {
+		l.remove(element)
+		return nil, false
+	}
+
+	l.order.MoveToFront(element)
+	return entry, true
+}
+
+// set stores the value and evicts from the back until the capacity is respected, the caller holds the lock
+func (l *LRU) set(key string, value []byte, ttl time.Duration) {
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = l.now().Add(ttl)
+	}
+
+	if element, ok := l.entries[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		l.order.MoveToFront(element)
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+
+	for l.order.Len() > l.capacity {
+		l.remove(l.order.Back())
+	}
+}
+
+func (l *LRU) remove(element *list.Element) {
+	l.order.Remove(element)
+	delete(l.entries, element.Value.(*lruEntry).key)
+}
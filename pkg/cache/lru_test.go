@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUEvictionOrder(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		capacity int
+		steps    func(l *LRU)
+		want     []string // keys still held
+		evicted  []string
+	}{
+		{
+			name:     "the oldest entry goes first",
+			capacity: 2,
+			steps: func(l *LRU) {
+				l.Set("a", []byte("1"), 0)
+				l.Set("b", []byte("2"), 0)
+				l.Set("c", []byte("3"), 0)
+			},
+			want:    []string{"b", "c"},
+			evicted: []string{"a"},
+		},
+		{
+			name:     "a read makes an entry the most recently used",
+			capacity: 2,
+			steps: func(l *LRU) {
+				l.Set("a", []byte("1"), 0)
+				l.Set("b", []byte("2"), 0)
+				l.Get("a")
+				l.Set("c", []byte("3"), 0)
+			},
+			want:    []string{"a", "c"},
+			evicted: []string{"b"},
+		},
+		{
+			name:     "overwriting an entry makes it the most recently used",
+			capacity: 2,
+			steps: func(l *LRU) {
+				l.Set("a", []byte("1"), 0)
+				l.Set("b", []byte("2"), 0)
+				l.Set("a", []byte("3"), 0)
+				l.Set("c", []byte("4"), 0)
+			},
+			want:    []string{"a", "c"},
+			evicted: []string{"b"},
+		},
+		{
+			name:     "a miss does not change the order",
+			capacity: 2,
+			steps: func(l *LRU) {
+				l.Set("a", []byte("1"), 0)
+				l.Set("b", []byte("2"), 0)
+				l.Get("x")
+				l.Set("c", []byte("3"), 0)
+			},
+			want:    []string{"b", "c"},
+			evicted: []string{"a", "x"},
+		},
+		{
+			name:     "a capacity below one holds one entry",
+			capacity: 0,
+			steps: func(l *LRU) {
+				l.Set("a", []byte("1"), 0)
+				l.Set("b", []byte("2"), 0)
+			},
+			want:    []string{"b"},
+			evicted: []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			l := NewLRU(tt.capacity)
+			tt.steps(l)
+
+			if l.Len() != len(tt.want) {
+				t.Fatalf("Len() = %d, want %d", l.Len(), len(tt.want))
+			}
+			for _, key := range tt.want {
+				if _, ok, _ := l.Get(key); !ok {
+					t.Errorf("Get(%q) missed, want a hit", key)
+				}
+			}
+			for _, key := range tt.evicted {
+				if _, ok, _ := l.Get(key); ok {
+					t.Errorf("Get(%q) hit, want it evicted", key)
+				}
+			}
+		})
+	}
+}
+
+func TestLRUExpiry(t *testing.T) {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewLRU(4)
+	l.now = func() time.Time { return now }
+
+	l.Set("short", []byte("1"), time.Minute)
+	l.Set("forever", []byte("2"), 0)
+
+	now = now.Add(59 * time.Second)
+	if _, ok, _ := l.Get("short"); !ok {
+		t.Fatal("Get(short) missed before its ttl")
+	}
+
+	now = now.Add(time.Second)
+	if _, ok, _ := l.Get("short"); ok {
+		t.Fatal("Get(short) hit once its ttl was up")
+	}
+	if l.Len() != 1 {
+		t.Fatalf("Len() = %d after the expired entry was read, want 1", l.Len())
+	}
+
+	now = now.Add(24 * time.Hour)
+	if _, ok, _ := l.Get("forever"); !ok {
+		t.Fatal("Get(forever) missed, an entry without ttl does not expire")
+	}
+}
+
+func TestLRUCopiesValues(t *testing.T) {
+
+	l := NewLRU(1)
+
+	value := []byte("abc")
+	l.Set("a", value, 0)
+	value[0] = 'x'
+
+	got, _, _ := l.Get("a")
+	if string(got) != "abc" {
+		t.Fatalf("Get(a) = %q after the caller changed its slice, want %q", got, "abc")
+	}
+
+	got[0] = 'y'
+	if again, _, _ := l.Get("a"); string(again) != "abc" {
+		t.Fatalf("Get(a) = %q after the returned slice was changed, want %q", again, "abc")
+	}
+}
@@ -0,0 +1,193 @@
+package cache
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	redisDialTimeout = 2 * time.Second
+	redisIOTimeout   = 2 * time.Second
+	redisIdleConns   = 8
+)
+
+// RedisOptions configures the connection of a Redis store
+type RedisOptions struct {
+	Addr     string
+	Password string // sent with AUTH when set
+	DB       int    // selected with SELECT when not zero
+}
+
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+// Redis is a Store speaking the Redis protocol (RESP) to a Redis server or anything compatible,
+// such as the stand-in of NewServer. Connections are dialed on demand and kept for reuse; one
+// that fails is dropped rather than handed out again.
+type Redis struct {
+	options RedisOptions
+	idle    chan *redisConn
+}
+
+func NewRedis(options RedisOptions) *Redis {
+	return &Redis{
+		options: options,
+		idle:    make(chan *redisConn, redisIdleConns),
+	}
+}
+
+// Get implements Store.
+func (r *Redis) Get(key string) ([]byte, bool, error) {
+
+	reply, err := r.do("GET", key)
+	if err != nil {
+		return nil, false, err
+	}
+	if reply == nil {
+		return nil, false, nil
+	}
+
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redis GET %s: unexpected reply %T", key, reply)
+	}
+
+	return value, true, nil
+}
+
+// Set implements Store.
+func (r *Redis) Set(key string, value []byte, ttl time.Duration) error {
+
+	args := []string{"SET", key, string(value)}
+	if ttl > 0 {
+		milliseconds := ttl.Milliseconds()
+		if milliseconds < 1 {
+			milliseconds = 1
+		}
+		args = append(args, "PX", strconv.FormatInt(milliseconds, 10))
+	}
+
+	_, err := r.do(args...)
+	return err
+}
+
+// Delete implements Store.
+func (r *Redis) Delete(keys ...string) error {
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	_, err := r.do(append([]string{"DEL"}, keys...)...)
+	return err
+}
+
+// Ping checks that the server can be reached
+func (r *Redis) Ping() error {
+	_, err := r.do("PING")
+	return err
+}
+
+// Close drops the idle connections, connections in use are closed when they are returned
+func (r *Redis) Close() {
+	for {
+		select {
+		case c := <-r.idle:
+			c.conn.Close()
+		default:
+			return
+		}
+	}
+}
+
+// do sends one command and reads its reply, error replies are returned as RedisError
+func (r *Redis) do(args ...string) (interface{}, error) {
+
+	c, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := roundTrip(c, args...)
+	if err != nil {
+		c.conn.Close()
+		return nil, err
+	}
+	r.put(c)
+
+	if redisErr, ok := reply.(RedisError); ok {
+		return nil, redisErr
+	}
+
+	return reply, nil
+}
+
+func (r *Redis) get() (*redisConn, error) {
+
+	select {
+	case c := <-r.idle:
+		return c, nil
+	default:
+	}
+
+	conn, err := net.DialTimeout("tcp", r.options.Addr, redisDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	c := &redisConn{conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}
+
+	if r.options.Password != "" {
+		if err := expectOK(c, "AUTH", r.options.Password); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if r.options.DB != 0 {
+		if err := expectOK(c, "SELECT", strconv.Itoa(r.options.DB)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (r *Redis) put(c *redisConn) {
+	select {
+	case r.idle <- c:
+	default:
+		c.conn.Close()
+	}
+}
+
+func roundTrip(c *redisConn, args ...string) (interface{}, error) {
+
+	if err := c.conn.SetDeadline(time.Now().Add(redisIOTimeout)); err != nil {
+		return nil, err
+	}
+	if err := writeCommand(c.w, args...); err != nil {
+		return nil, err
+	}
+
+	return readReply(c.r)
+}
+
+// expectOK runs a setup command of a fresh connection
+func expectOK(c *redisConn, args ...string) error {
+
+	reply, err := roundTrip(c, args...)
+	if err != nil {
+		return err
+	}
+	if redisErr, ok := reply.(RedisError); ok {
+		return fmt.Errorf("redis %s: %w", args[0], redisErr)
+	}
+
+	return nil
+}
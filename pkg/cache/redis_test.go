@@ -0,0 +1,241 @@
+package cache
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// startServer runs a stand-in on a free local port for the length of the test
+func startServer(t *testing.T, capacity int, password string) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+
+	server := NewServer(capacity, password)
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+
+	return listener.Addr().String()
+}
+
+type redisStep struct {
+	op    string // set, get or del
+	keys  []string
+	value string
+	ttl   time.Duration
+	sleep time.Duration // before the step
+
+	want    string
+	wantHit bool
+}
+
+func TestRedisCommands(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		steps []redisStep
+	}{
+		{
+			name: "get of a missing key misses",
+			steps: []redisStep{
+				{op: "get", keys: []string{"a"}},
+			},
+		},
+		{
+			name: "set then get",
+			steps: []redisStep{
+				{op: "set", keys: []string{"a"}, value: "1"},
+				{op: "get", keys: []string{"a"}, want: "1", wantHit: true},
+			},
+		},
+		{
+			name: "set overwrites",
+			steps: []redisStep{
+				{op: "set", keys: []string{"a"}, value: "1"},
+				{op: "set", keys: []string{"a"}, value: "2"},
+				{op: "get", keys: []string{"a"}, want: "2", wantHit: true},
+			},
+		},
+		{
+			name: "values are binary safe",
+			steps: []redisStep{
+				{op: "set", keys: []string{"a"}, value: "line\r\n$3\r\n*\x00"},
+				{op: "get", keys: []string{"a"}, want: "line\r\n$3\r\n*\x00", wantHit: true},
+			},
+		},
+		{
+			name: "empty value is a hit",
+			steps: []redisStep{
+				{op: "set", keys: []string{"a"}, value: ""},
+				{op: "get", keys: []string{"a"}, want: "", wantHit: true},
+			},
+		},
+		{
+			name: "del removes every key given",
+			steps: []redisStep{
+				{op: "set", keys: []string{"a"}, value: "1"},
+				{op: "set", keys: []string{"b"}, value: "2"},
+				{op: "set", keys: []string{"c"}, value: "3"},
+				{op: "del", keys: []string{"a", "b", "missing"}},
+				{op: "get", keys: []string{"a"}},
+				{op: "get", keys: []string{"b"}},
+				{op: "get", keys: []string{"c"}, want: "3", wantHit: true},
+			},
+		},
+		{
+			name: "set with a ttl is sent as PX and expires",
+			steps: []redisStep{
+				{op: "set", keys: []string{"a"}, value: "1", ttl: 50 * time.Millisecond},
+				{op: "get", keys: []string{"a"}, want: "1", wantHit: true},
+				{op: "get", keys: []string{"a"}, sleep: 100 * time.Millisecond},
+			},
+		},
+		{
+			name: "a ttl under a millisecond is rounded up",
+			steps: []redisStep{
+				{op: "set", keys: []string{"a"}, value: "1", ttl: time.Microsecond},
+				{op: "get", keys: []string{"a"}, sleep: 20 * time.Millisecond},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			store := NewRedis(RedisOptions{Addr: startServer(t, 16, "")})
+			defer store.Close()
+
+			for i, step := range tt.steps {
+				time.Sleep(step.sleep)
+
+				switch step.op {
+				case "set":
+					if err := store.Set(step.keys[0], []byte(step.value), step.ttl); err != nil {
+						t.Fatalf("step %d: Set(%q) error: %v", i, step.keys[0], err)
+					}
+				case "del":
+					if err := store.Delete(step.keys...); err != nil {
+						t.Fatalf("step %d: Delete(%v) error: %v", i, step.keys, err)
+					}
+				case "get":
+					value, hit, err := store.Get(step.keys[0])
+					if err != nil {
+						t.Fatalf("step %d: Get(%q) error: %v", i, step.keys[0], err)
+					}
+					if hit != step.wantHit || string(value) != step.want {
+						t.Fatalf("step %d: Get(%q) = %q, %v, want %q, %v", i, step.keys[0], value, hit, step.want, step.wantHit)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRedisAuth(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		serverPassword string
+		clientPassword string
+		wantErr        bool
+	}{
+		{name: "no password on either side", serverPassword: "", clientPassword: ""},
+		{name: "right password", serverPassword: "secret", clientPassword: "secret"},
+		{name: "missing password", serverPassword: "secret", clientPassword: "", wantErr: true},
+		{name: "wrong password", serverPassword: "secret", clientPassword: "guess", wantErr: true},
+		{name: "password the server does not expect", serverPassword: "", clientPassword: "secret", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			store := NewRedis(RedisOptions{Addr: startServer(t, 16, tt.serverPassword), Password: tt.clientPassword})
+			defer store.Close()
+
+			err := store.Set("a", []byte("1"), 0)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if value, hit, err := store.Get("a"); err != nil || !hit || string(value) != "1" {
+				t.Fatalf("Get() = %q, %v, %v, want %q, true, nil", value, hit, err, "1")
+			}
+		})
+	}
+}
+
+func TestRedisSelect(t *testing.T) {
+
+	store := NewRedis(RedisOptions{Addr: startServer(t, 16, ""), DB: 3})
+	defer store.Close()
+
+	if err := store.Ping(); err != nil {
+		t.Fatalf("Ping() with a database selected: %v", err)
+	}
+}
+
+func TestRedisServerEviction(t *testing.T) {
+
+	store := NewRedis(RedisOptions{Addr: startServer(t, 2, "")})
+	defer store.Close()
+
+	for _, key := range []string{"a", "b"} {
+		if err := store.Set(key, []byte(key), 0); err != nil {
+			t.Fatalf("Set(%q): %v", key, err)
+		}
+	}
+
+	// a is read last, so b is the least recently used when c comes in
+	if _, hit, _ := store.Get("a"); !hit {
+		t.Fatal("Get(a) missed")
+	}
+	if err := store.Set("c", []byte("c"), 0); err != nil {
+		t.Fatalf("Set(c): %v", err)
+	}
+
+	for key, wantHit := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, hit, err := store.Get(key); err != nil || hit != wantHit {
+			t.Errorf("Get(%q) hit = %v, %v, want %v", key, hit, err, wantHit)
+		}
+	}
+}
+
+func TestRedisErrorReply(t *testing.T) {
+
+	store := NewRedis(RedisOptions{Addr: startServer(t, 16, "")})
+	defer store.Close()
+
+	_, err := store.do("NOPE")
+	var redisErr RedisError
+	if !errors.As(err, &redisErr) {
+		t.Fatalf("do(NOPE) error = %v, want a RedisError", err)
+	}
+
+	// the error reply leaves the connection usable
+	if err := store.Ping(); err != nil {
+		t.Fatalf("Ping() after an error reply: %v", err)
+	}
+}
+
+func TestRedisUnreachable(t *testing.T) {
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	store := NewRedis(RedisOptions{Addr: addr})
+	if err := store.Ping(); err == nil {
+		t.Fatal("Ping() of a closed port succeeded")
+	}
+}
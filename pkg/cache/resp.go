@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// maxBulkLength bounds the size of a single bulk string read off the wire
+const maxBulkLength = 512 << 20
+
+var errProtocol = errors.New("resp: protocol error")
+
+// RedisError is an error reply of the server, such as a wrong type or an unknown command
+type RedisError string
+
+func (e RedisError) Error() string { return string(e) }
+
+// writeCommand writes args as a RESP array of bulk strings, the form every command is sent in
+func writeCommand(w *bufio.Writer, args ...string) error {
+
+	if _, err := fmt.Fprintf(w, "*%d\r\n", len(args)); err != nil {
+		return err
+	}
+	for _, arg := range args {
+		if err := writeBulk(w, []byte(arg)); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+func writeBulk(w *bufio.Writer, value []byte) error {
+	if _, err := fmt.Fprintf(w, "$%d\r\n", len(value)); err != nil {
+		return err
+	}
+	if _, err := w.Write(value); err != nil {
+		return err
+	}
+	_, err := w.WriteString("\r\n")
+	return err
+}
+
+// readReply reads one RESP value. Simple strings and bulk strings come back as []byte, a null
+// bulk string or array as nil, integers as int64, arrays as []interface{} and error replies as
+// RedisError.
+func readReply(r *bufio.Reader) (interface{}, error) {
+
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errProtocol
+	}
+
+	switch line[0] {
+	case '+':
+		return []byte(line[1:]), nil
+	case '-':
+		return RedisError(line[1:]), nil
+	case ':':
+		n, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return nil, errProtocol
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n > maxBulkLength {
+			return nil, errProtocol
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		value := make([]byte, n+2)
+		if _, err := io.ReadFull(r, value); err != nil {
+			return nil, err
+		}
+		if value[n] != '\r' || value[n+1] != '\n' {
+			return nil, errProtocol
+		}
+		return value[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, errProtocol
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		values := make([]interface{}, n)
+		for i := range values {
+			if values[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	}
+
+	return nil, errProtocol
+}
+
+// readLine reads up to CRLF and returns the line without it
+func readLine(r *bufio.Reader) (string, error) {
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", errProtocol
+	}
+
+	return line[:len(line)-2], nil
+}
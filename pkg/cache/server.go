@@ -0,0 +1,281 @@
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is a small stand-in for Redis that answers the commands the Redis store uses (PING,
+// AUTH, SELECT, GET, SET with EX/PX, DEL, DBSIZE, FLUSHDB/FLUSHALL and QUIT) from an LRU.
+// It lets the Redis backend be run and exercised locally without a Redis server.
+type Server struct {
+	store    *LRU
+	password string
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+}
+
+// NewServer creates a stand-in holding at most capacity keys, clients have to AUTH with the
+// password when it is not empty
+func NewServer(capacity int, password string) *Server {
+	return &Server{
+		store:    NewLRU(capacity),
+		password: password,
+		conns:    make(map[net.Conn]struct{}),
+	}
+}
+
+// ListenAndServe listens on addr and serves until Close is called
+func (s *Server) ListenAndServe(addr string) error {
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(listener)
+}
+
+// Serve accepts connections on listener until Close is called
+func (s *Server) Serve(listener net.Listener) error {
+
+	s.mu.Lock()
+	s.listener = listener
+	s.mu.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops accepting connections and closes the open ones
+func (s *Server) Close() error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn := range s.conns {
+		conn.Close()
+	}
+
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Close()
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	authenticated := s.password == ""
+
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				log.Printf("cache server: dropping %s: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		name := strings.ToUpper(args[0])
+		switch {
+		case name == "QUIT":
+			writeSimple(w, "+OK")
+			w.Flush()
+			return
+		case name == "AUTH":
+			authenticated = s.auth(w, args)
+		case !authenticated:
+			writeSimple(w, "-NOAUTH Authentication required.")
+		default:
+			s.execute(w, name, args[1:])
+		}
+
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) auth(w *bufio.Writer, args []string) bool {
+
+	// AUTH password and AUTH username password, the username is not checked
+	if len(args) < 2 || len(args) > 3 {
+		writeSimple(w, "-ERR wrong number of arguments for 'auth' command")
+		return s.password == ""
+	}
+	if s.password == "" || args[len(args)-1] != s.password {
+		writeSimple(w, "-WRONGPASS invalid username-password pair")
+		return s.password == ""
+	}
+
+	writeSimple(w, "+OK")
+	return true
+}
+
+func (s *Server) execute(w *bufio.Writer, name string, args []string) {
+
+	switch name {
+	case "PING":
+		if len(args) == 1 {
+			writeBulk(w, []byte(args[0]))
+			return
+		}
+		writeSimple(w, "+PONG")
+	case "SELECT":
+		if len(args) != 1 {
+			writeArity(w, name)
+			return
+		}
+		if _, err := strconv.Atoi(args[0]); err != nil {
+			writeSimple(w, "-ERR value is not an integer or out of range")
+			return
+		}
+		writeSimple(w, "+OK")
+	case "GET":
+		if len(args) != 1 {
+			writeArity(w, name)
+			return
+		}
+		value, ok, _ := s.store.Get(args[0])
+		if !ok {
+			writeSimple(w, "$-1")
+			return
+		}
+		writeBulk(w, value)
+	case "SET":
+		s.set(w, args)
+	case "DEL":
+		if len(args) == 0 {
+			writeArity(w, name)
+			return
+		}
+		deleted := 0
+		for _, key := range args {
+			if _, ok, _ := s.store.Get(key); ok {
+				deleted++
+			}
+		}
+		s.store.Delete(args...)
+		writeSimple(w, fmt.Sprintf(":%d", deleted))
+	case "DBSIZE":
+		writeSimple(w, fmt.Sprintf(":%d", s.store.Len()))
+	case "FLUSHDB", "FLUSHALL":
+		s.store.Flush()
+		writeSimple(w, "+OK")
+	default:
+		writeSimple(w, fmt.Sprintf("-ERR unknown command '%s'", strings.ToLower(name)))
+	}
+}
+
+// set handles SET key value [EX seconds | PX milliseconds]
+func (s *Server) set(w *bufio.Writer, args []string) {
+
+	if len(args) != 2 && len(args) != 4 {
+		writeSimple(w, "-ERR syntax error")
+		return
+	}
+
+	var ttl time.Duration
+	if len(args) == 4 {
+		n, err := strconv.ParseInt(args[3], 10, 64)
+		if err != nil || n <= 0 {
+			writeSimple(w, "-ERR invalid expire time in 'set' command")
+			return
+		}
+		switch strings.ToUpper(args[2]) {
+		case "EX":
+			ttl = time.Duration(n) * time.Second
+		case "PX":
+			ttl = time.Duration(n) * time.Millisecond
+		default:
+			writeSimple(w, "-ERR syntax error")
+			return
+		}
+	}
+
+	s.store.Set(args[0], []byte(args[1]), ttl)
+	writeSimple(w, "+OK")
+}
+
+// readCommand reads a command sent as a RESP array of bulk strings, or as an inline command
+// typed by hand into a telnet session
+func readCommand(r *bufio.Reader) ([]string, error) {
+
+	first, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	if first[0] != '*' {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		return strings.Fields(line), nil
+	}
+
+	reply, err := readReply(r)
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok {
+		return nil, errProtocol
+	}
+
+	args := make([]string, len(values))
+	for i, value := range values {
+		bulk, ok := value.([]byte)
+		if !ok {
+			return nil, errProtocol
+		}
+		args[i] = string(bulk)
+	}
+
+	return args, nil
+}
+
+func writeSimple(w *bufio.Writer, line string) {
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+func writeArity(w *bufio.Writer, name string) {
+	writeSimple(w, fmt.Sprintf("-ERR wrong number of arguments for '%s' command", strings.ToLower(name)))
+}
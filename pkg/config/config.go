@@ -1,9 +1,11 @@
 package config
 
 import (
+	"encoding/json"
 	"github.com/kraken-io/kraken-go"
 	"github.com/spf13/viper"
 	"log"
+	"os"
 )
 
 // Config is a struct that holds the configuration of the application
@@ -14,9 +16,54 @@ type Config struct {
 	DBName          string `mapstructure:"DBNAME"`
 	Port            string `mapstructure:"PORT"`
 	JWTSecret       string `mapstructure:"JWTSECRET"`
+	JWTKeysFile     string `mapstructure:"JWTKEYSFILE"` // JSON manifest of RS256/EdDSA keys, see signing.LoadKeyFile
 	KrakenAPIKey    string `mapstructure:"KRAKENAPIKEY"`
 	KrakenAPISecret string `mapstructure:"KRAKENAPISECRET"`
 	AppKey          string `mapstructure:"APPKEY"`
+	RevocationStore string `mapstructure:"REVOCATIONSTORE"` // "database" (default) or "memory"
+	AppBaseURL      string `mapstructure:"APPBASEURL"`      // base of the links put in mails
+	Mailer          string `mapstructure:"MAILER"`          // "smtp" (default), "file" or "memory"
+	MailFrom        string `mapstructure:"MAILFROM"`
+	MailDir         string `mapstructure:"MAILDIR"` // where the file mailer writes messages
+	SMTPHost        string `mapstructure:"SMTPHOST"`
+	SMTPPort        string `mapstructure:"SMTPPORT"`
+	SMTPUser        string `mapstructure:"SMTPUSER"`
+	SMTPPass        string `mapstructure:"SMTPPASS"`
+
+	RequireVerifiedEmail bool `mapstructure:"REQUIREVERIFIEDEMAIL"` // block unverified users from posting and commenting
+
+	LoginLockoutThreshold int `mapstructure:"LOGINLOCKOUTTHRESHOLD"` // consecutive failed logins that lock an account
+	LoginLockoutMinutes   int `mapstructure:"LOGINLOCKOUTMINUTES"`
+
+	CacheControlPublic  string `mapstructure:"CACHECONTROLPUBLIC"`  // Cache-Control of the public blog reads, e.g. "public, max-age=60, s-maxage=300"
+	CacheControlPrivate string `mapstructure:"CACHECONTROLPRIVATE"` // Cache-Control of reads that depend on who asks
+
+	CacheBackend    string `mapstructure:"CACHEBACKEND"` // response cache of the blog reads: "memory" (default), "redis" or "none"
+	CacheAddr       string `mapstructure:"CACHEADDR"`    // host:port of the Redis server
+	CachePassword   string `mapstructure:"CACHEPASSWORD"`
+	CacheDB         int    `mapstructure:"CACHEDB"`
+	CacheSize       int    `mapstructure:"CACHESIZE"` // entries kept by the memory backend
+	CacheTTLSeconds int    `mapstructure:"CACHETTLSECONDS"`
+
+	SearchBackend string `mapstructure:"SEARCHBACKEND"` // full-text search of the posts: "memory" (default) or "mysql"
+
+	TrustedProxies string `mapstructure:"TRUSTEDPROXIES"` // comma separated CIDRs of the reverse proxies whose X-Forwarded-For is believed
+
+	OIDCProvidersFile string         `mapstructure:"OIDCPROVIDERSFILE"` // JSON list of OpenID Connect providers, see OIDCProvider
+	OIDCProviders     []OIDCProvider `mapstructure:"-"`
+}
+
+// OIDCProvider configures one OpenID Connect login provider, endpoints are discovered from the issuer
+//
+//	[{"name": "google", "issuer": "https://accounts.google.com", "client_id": "...",
+//	  "client_secret": "...", "redirect_url": "http://localhost:8080/blog_api/v1/user/oidc/google/callback"}]
+type OIDCProvider struct {
+	Name         string   `json:"name"`
+	Issuer       string   `json:"issuer"`
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret"`
+	RedirectURL  string   `json:"redirect_url"`
+	Scopes       []string `json:"scopes"` // defaults to openid, email and profile
 }
 
 // Global var to access from any package
@@ -38,6 +85,16 @@ func InitConfig() *Config {
 	if err := viper.Unmarshal(&config); err != nil {
 		log.Fatal("Unable to decode into struct", err)
 	}
+
+	if config.OIDCProvidersFile != "" {
+		raw, err := os.ReadFile(config.OIDCProvidersFile)
+		if err != nil {
+			log.Fatal("Error reading OIDC providers file ", err)
+		}
+		if err := json.Unmarshal(raw, &config.OIDCProviders); err != nil {
+			log.Fatal("Unable to decode OIDC providers file ", err)
+		}
+	}
 	return config
 
 }
@@ -30,9 +30,26 @@ func Connect() {
 // Creating New table in foodstore database
 func Migrate() {
 	db.Migrator().AutoMigrate(models.User{})
+	db.SetupJoinTable(&models.BlogPost{}, "Tags", &models.BlogPostTag{})
 	db.Migrator().AutoMigrate(models.BlogPost{})
+	db.Migrator().AutoMigrate(models.Category{})
+	db.Migrator().AutoMigrate(models.Tag{})
+	db.Migrator().AutoMigrate(models.BlogPostTag{})
+	db.Migrator().AutoMigrate(models.BlogPostDailyView{})
+	db.Migrator().AutoMigrate(models.BlogPostReactionCount{})
+	db.Migrator().AutoMigrate(models.BlogSlugHistory{})
+	db.Migrator().AutoMigrate(models.BlogRevision{})
 	db.Migrator().AutoMigrate(models.Comment{})
 	db.Migrator().AutoMigrate(models.Reaction{})
+	db.Migrator().AutoMigrate(models.ReactionType{})
+	db.Migrator().AutoMigrate(models.RevokedToken{})
+	db.Migrator().AutoMigrate(models.RefreshToken{})
+	db.Migrator().AutoMigrate(models.UserToken{})
+	db.Migrator().AutoMigrate(models.LoginAttempt{})
+	db.Migrator().AutoMigrate(models.RecoveryCode{})
+	db.Migrator().AutoMigrate(models.UserIdentity{})
+	db.Migrator().AutoMigrate(models.OIDCAuthState{})
+	db.Migrator().AutoMigrate(models.APIKey{})
 }
 
 // Calling to connect function to initalize connection
@@ -1,16 +1,42 @@
 package containers
 
 import (
+	"Blog_API/pkg/cache"
 	"Blog_API/pkg/config"
 	"Blog_API/pkg/connection"
 	"Blog_API/pkg/controllers"
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/jobs"
+	"Blog_API/pkg/mailer"
+	"Blog_API/pkg/middlewares"
+	"Blog_API/pkg/oidc"
+	"Blog_API/pkg/ratelimit"
 	"Blog_API/pkg/repositories"
 	"Blog_API/pkg/routes"
+	"Blog_API/pkg/search"
 	"Blog_API/pkg/services"
+	"Blog_API/pkg/signing"
+	"Blog_API/pkg/utils/consts"
+	blogconsts "Blog_API/pkg/utils/consts/blog"
+	mfaconsts "Blog_API/pkg/utils/consts/mfa"
+	oidcconsts "Blog_API/pkg/utils/consts/oidc"
+	tokenconsts "Blog_API/pkg/utils/consts/token"
+	userconsts "Blog_API/pkg/utils/consts/user"
+	"Blog_API/pkg/views"
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 // Serve is a function that returns a new instance of echo.Echo
@@ -19,26 +45,269 @@ func Serve(e *echo.Echo) {
 	// Config initialization
 	config.SetConfig()
 
+	// Behind a reverse proxy the client address comes from the X-Forwarded-For it appends
+	if config.LocalConfig.TrustedProxies != "" {
+		e.IPExtractor = newIPExtractor(config.LocalConfig.TrustedProxies)
+	}
+
+	// JWT key initialization
+	signing.SetKeySet(config.LocalConfig.JWTKeysFile, config.LocalConfig.JWTSecret, tokenconsts.AccessTokenTTL)
+
 	// Database initialization
 	db := connection.GetDB()
 
 	// Repository initialization
 	userRepo := repositories.NewUserRepo(db)
 	blogRepo := repositories.NewBlogRepo(db)
+	tokenRepo := repositories.NewTokenRepo(db)
+	userTokenRepo := repositories.NewUserTokenRepo(db)
+	mfaRepo := repositories.NewMFARepo(db)
+	oidcRepo := repositories.NewOIDCRepo(db)
+	apiKeyRepo := repositories.NewAPIKeyRepo(db)
+	mail := newMailer()
+	revocationStore := newRevocationStore(db)
+
+	// Middleware initialization
+	middlewares.SetRevocationStore(revocationStore)
+
+	loginPolicy := newLoginPolicy()
+	ipLoginBackoff := ratelimit.NewBackoff(loginPolicy.IP)
+	mfaBackoff := ratelimit.NewBackoff(ratelimit.Policy{
+		FreeAttempts: mfaconsts.MFAFreeAttempts,
+		BaseDelay:    mfaconsts.MFABaseDelay,
+		MaxDelay:     mfaconsts.MFAMaxDelay,
+		Forget:       mfaconsts.MFAForgetAfter,
+	})
+
+	// Background jobs
+	jobs.Every("purge-revoked-tokens", consts.RevokedTokenPurgeInterval, revocationStore.PurgeExpired)
+	jobs.Every("prune-login-backoff", userconsts.LoginBackoffPruneTime, ipLoginBackoff.Prune)
+	jobs.Every("purge-oidc-states", oidcconsts.AuthStatePurgeInterval, oidcRepo.PurgeExpiredAuthStates)
+	jobs.Every("prune-mfa-backoff", mfaconsts.MFABackoffPruneInterval, mfaBackoff.Prune)
+
+	// Posts created before slugs existed get one
+	if err := services.BackfillBlogSlugs(blogRepo); err != nil {
+		log.Println("error backfilling blog slugs:", err)
+	}
+
+	// Posts created before categories existed only have the name of one
+	if err := services.MigrateCategories(blogRepo); err != nil {
+		log.Println("error migrating blog categories:", err)
+	}
+
+	// Posts created before the lifecycle existed only have is_published
+	if err := blogRepo.BackfillPostStatuses(); err != nil {
+		log.Println("error backfilling blog statuses:", err)
+	}
+
+	// The reaction types used to be fixed in the code, they start out as the catalog
+	if err := services.SeedReactionTypes(blogRepo); err != nil {
+		log.Println("error seeding reaction types:", err)
+	}
+
+	// Reactions made before the per type counts existed are counted once
+	if counted, err := blogRepo.BackfillReactionTypeCounts(); err != nil {
+		log.Println("error backfilling blog reaction counts:", err)
+	} else if counted > 0 {
+		log.Printf("backfilled %d blog reaction counts", counted)
+	}
 
 	// Service initialization
-	userService := services.SetUserService(userRepo)
-	blogService := services.NewBlogService(blogRepo, userService)
+	verificationService := services.NewVerificationService(userRepo, userTokenRepo, mail, config.LocalConfig.AppBaseURL)
+	userService := services.SetUserService(userRepo, revocationStore, verificationService, loginPolicy, ipLoginBackoff)
+	tokenService := services.NewTokenService(tokenRepo, userRepo, revocationStore)
+	mfaService := services.NewMFAService(mfaRepo, userRepo, tokenService, mfaBackoff)
+	oidcService := services.NewOIDCService(oidcRepo, userRepo, oidc.NewRegistry(config.LocalConfig.OIDCProviders))
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, userRepo)
+	passwordService := services.NewPasswordService(userRepo, userTokenRepo, tokenService, mail, config.LocalConfig.AppBaseURL)
+	blogService := newBlogCache(services.NewBlogService(blogRepo, userService, newSearchIndex(db, blogRepo), views.NewRecorder(blogconsts.ViewDedupWindow), config.LocalConfig.RequireVerifiedEmail))
+
+	jobs.Every("publish-scheduled-posts", blogconsts.PublishSchedulerInterval, func() error {
+		_, err := blogService.PublishScheduledPosts()
+		return err
+	})
+	jobs.Every("flush-blog-views", blogconsts.ViewFlushInterval, blogService.FlushViews)
+	jobs.Every("reconcile-blog-counters", blogconsts.CounterReconcileInterval, func() error {
+		_, err := services.ReconcileCounters(blogRepo, true)
+		return err
+	})
+
+	middlewares.SetCachePolicies(config.LocalConfig.CacheControlPublic, config.LocalConfig.CacheControlPrivate)
+
+	// API keys are checked by the middleware, so it can only be set up after the service
+	middlewares.SetAPIKeyService(apiKeyService)
 
 	// Controller initialization
-	userController := controllers.SetUserController(userService)
+	userController := controllers.SetUserController(userService, tokenService, mfaService)
 	blogController := controllers.NewBlogController(blogService)
+	wellKnownController := controllers.NewWellKnownController(signing.LocalKeySet)
+	passwordController := controllers.NewPasswordController(passwordService)
+	verificationController := controllers.NewVerificationController(verificationService)
+	mfaController := controllers.NewMFAController(mfaService)
+	oidcController := controllers.NewOIDCController(oidcService, tokenService, mfaService)
+	apiKeyController := controllers.NewAPIKeyController(apiKeyService)
 
 	user := routes.NewUserRoutes(e, userController)
 	user.InitUserRoutes()
+	mfa := routes.NewMFARoutes(e, mfaController)
+	mfa.InitMFARoutes()
+	social := routes.NewOIDCRoutes(e, oidcController)
+	social.InitOIDCRoutes()
+	apiKeys := routes.NewAPIKeyRoutes(e, apiKeyController)
+	apiKeys.InitAPIKeyRoutes()
+	password := routes.NewPasswordRoutes(e, passwordController)
+	password.InitPasswordRoutes()
+	verification := routes.NewVerificationRoutes(e, verificationController)
+	verification.InitVerificationRoutes()
 	blog := routes.NewBlogRoutes(e, blogController)
 	blog.InitBlogRoutes()
+	wellKnown := routes.NewWellKnownRoutes(e, wellKnownController)
+	wellKnown.InitWellKnownRoutes()
+
+	// On SIGINT or SIGTERM the requests in flight are finished first, then the views they recorded
+	// are written, the recorder only keeps them in memory
+	stop, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
 
 	// Starting Server
-	log.Fatal(e.Start(fmt.Sprintf(":%s", config.LocalConfig.Port)))
+	go func() {
+		if err := e.Start(fmt.Sprintf(":%s", config.LocalConfig.Port)); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+	<-stop.Done()
+
+	ctx, cancelShutdown := context.WithTimeout(context.Background(), consts.ShutdownTimeout)
+	defer cancelShutdown()
+	if err := e.Shutdown(ctx); err != nil {
+		log.Println("error shutting down the server:", err)
+	}
+
+	if err := blogService.FlushViews(); err != nil {
+		log.Println("error flushing blog views:", err)
+	}
+}
+
+// newRevocationStore picks the revocation store backend from the configuration
+func newRevocationStore(db *gorm.DB) domain.RevocationStore {
+	if config.LocalConfig.RevocationStore == consts.MemoryStore {
+		return repositories.NewMemoryRevocationStore()
+	}
+	return repositories.NewRevocationRepo(db)
+}
+
+// newSearchIndex picks the search backend from the configuration and gets it ready to answer
+func newSearchIndex(db *gorm.DB, blogRepo domain.BlogRepository) domain.SearchIndex {
+
+	if config.LocalConfig.SearchBackend == consts.MySQLSearch {
+		if err := repositories.CreateFullTextIndexes(db); err != nil {
+			log.Println("error creating the full-text indexes:", err)
+		}
+		return repositories.NewFullTextSearchRepo(db)
+	}
+
+	// The memory index starts out empty
+	index := search.NewMemoryIndex()
+	if err := services.BuildSearchIndex(blogRepo, index); err != nil {
+		log.Println("error building the search index:", err)
+	}
+	return index
+}
+
+// newBlogCache puts the configured response cache in front of the blog service
+func newBlogCache(blogService domain.BlogService) domain.BlogService {
+	conf := config.LocalConfig
+
+	ttl := consts.DefaultCacheTTL
+	if conf.CacheTTLSeconds > 0 {
+		ttl = time.Duration(conf.CacheTTLSeconds) * time.Second
+	}
+
+	switch conf.CacheBackend {
+	case consts.NoCache:
+		return blogService
+	case consts.RedisCache:
+		addr := conf.CacheAddr
+		if addr == "" {
+			addr = consts.DefaultCacheAddr
+		}
+		store := cache.NewRedis(cache.RedisOptions{Addr: addr, Password: conf.CachePassword, DB: conf.CacheDB})
+		// An unreachable server only costs the cache, the service answers every read itself
+		if err := store.Ping(); err != nil {
+			log.Printf("error reaching the Redis cache at %s: %v", addr, err)
+		}
+		return services.NewCachedBlogService(blogService, store, ttl)
+	default:
+		size := consts.DefaultCacheSize
+		if conf.CacheSize > 0 {
+			size = conf.CacheSize
+		}
+		return services.NewCachedBlogService(blogService, cache.NewLRU(size), ttl)
+	}
+}
+
+// newMailer picks the mail backend from the configuration
+func newMailer() domain.Mailer {
+	conf := config.LocalConfig
+	switch conf.Mailer {
+	case consts.FileMailer:
+		dir := conf.MailDir
+		if dir == "" {
+			dir = consts.DefaultMailDir
+		}
+		return mailer.NewFileMailer(dir, conf.MailFrom)
+	case consts.MemoryMailer:
+		return mailer.NewMemoryMailer()
+	default:
+		return mailer.NewSMTPMailer(conf.SMTPHost, conf.SMTPPort, conf.SMTPUser, conf.SMTPPass, conf.MailFrom)
+	}
+}
+
+// newIPExtractor reads the client address from X-Forwarded-For, skipping the hops of the trusted
+// proxies only. The private ranges Echo trusts by default are not, a client on them could claim
+// any address.
+func newIPExtractor(trustedProxies string) echo.IPExtractor {
+
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, cidr := range strings.Split(trustedProxies, ",") {
+		_, ipRange, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			log.Fatalf("invalid trusted proxy range %q: %v", cidr, err)
+		}
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+// newLoginPolicy builds the failed login protection from the defaults and the configured lockout
+func newLoginPolicy() ratelimit.LoginPolicy {
+	conf := config.LocalConfig
+
+	policy := ratelimit.LoginPolicy{
+		Account: ratelimit.Policy{
+			FreeAttempts: userconsts.FreeLoginAttempts,
+			BaseDelay:    userconsts.LoginBackoffBaseDelay,
+			MaxDelay:     userconsts.LoginBackoffMaxDelay,
+			Forget:       userconsts.LoginFailuresForgetTime,
+		},
+		IP: ratelimit.Policy{
+			FreeAttempts: userconsts.IPFreeLoginAttempts,
+			BaseDelay:    userconsts.LoginBackoffBaseDelay,
+			MaxDelay:     userconsts.LoginBackoffMaxDelay,
+			Forget:       userconsts.LoginFailuresForgetTime,
+		},
+		LockoutThreshold: userconsts.LockoutThreshold,
+		LockoutDuration:  userconsts.LockoutDuration,
+	}
+
+	if conf.LoginLockoutThreshold > 0 {
+		policy.LockoutThreshold = conf.LoginLockoutThreshold
+	}
+
+	if conf.LoginLockoutMinutes > 0 {
+		policy.LockoutDuration = time.Duration(conf.LoginLockoutMinutes) * time.Minute
+	}
+
+	return policy
 }
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/types"
+	"Blog_API/pkg/utils/consts"
+	apikeyconsts "Blog_API/pkg/utils/consts/apikey"
+	userconsts "Blog_API/pkg/utils/consts/user"
+	"Blog_API/pkg/utils/response"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Parent struct to implement interface binding
+type apiKeyController struct {
+	svc domain.APIKeyService
+}
+
+// Interface binding
+func NewAPIKeyController(svc domain.APIKeyService) domain.APIKeyController {
+	return &apiKeyController{
+		svc: svc,
+	}
+}
+
+// CreateAPIKey implements domain.APIKeyController.
+// @Summary Create a personal API key
+// @Description Creates an API key for scripted access, optionally limited to scopes and an expiry. The key is only returned once.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param request body types.APIKeyRequest true "API Key Request"
+// @Success 200 {object} types.APIKeyCreatedResp "created api key"
+// @Failure 400 {string} string "invalid scope"
+// @Failure 409 {string} string "too many api keys"
+// @Router /user/api-keys [post]
+func (ctr *apiKeyController) CreateAPIKey(c echo.Context) error {
+
+	userID, parseErr := uuid.Parse(c.Get(userconsts.UserID).(string))
+	if parseErr != nil {
+		return response.ErrorResponse(c, parseErr, consts.InvalidDataRequest)
+	}
+
+	req := types.APIKeyRequest{}
+	if err := c.Bind(&req); err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	if validationErr := req.Validate(); validationErr != nil {
+		return response.ErrorResponse(c, validationErr, consts.ValidationError)
+	}
+
+	key, err := ctr.svc.CreateAPIKey(userID.String(), req)
+	if err != nil {
+		return response.ErrorResponse(c, err, apikeyconsts.ErrorCreatingAPIKey)
+	}
+
+	return response.SuccessResponse(c, apikeyconsts.APIKeyCreatedSuccessfully, key)
+}
+
+// GetAPIKeys implements domain.APIKeyController.
+// @Summary List personal API keys
+// @Description Lists the API keys of the logged in user, including revoked and expired ones
+// @Tags User
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Success 200 {array} types.APIKeyResp "api keys"
+// @Router /user/api-keys [get]
+func (ctr *apiKeyController) GetAPIKeys(c echo.Context) error {
+
+	userID, parseErr := uuid.Parse(c.Get(userconsts.UserID).(string))
+	if parseErr != nil {
+		return response.ErrorResponse(c, parseErr, consts.InvalidDataRequest)
+	}
+
+	keys, err := ctr.svc.GetAPIKeys(userID.String())
+	if err != nil {
+		return response.ErrorResponse(c, err, apikeyconsts.ErrorGettingAPIKeys)
+	}
+
+	return response.SuccessResponse(c, apikeyconsts.APIKeysFetchSuccessfully, keys)
+}
+
+// RevokeAPIKey implements domain.APIKeyController.
+// @Summary Revoke a personal API key
+// @Description Revokes an API key of the logged in user, it is rejected from then on
+// @Tags User
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param key_id query string true "API key ID"
+// @Success 200 {string} string "api key revoked successfully"
+// @Failure 404 {string} string "api key not found"
+// @Router /user/api-keys [delete]
+func (ctr *apiKeyController) RevokeAPIKey(c echo.Context) error {
+
+	userID, parseErr := uuid.Parse(c.Get(userconsts.UserID).(string))
+	if parseErr != nil {
+		return response.ErrorResponse(c, parseErr, consts.InvalidDataRequest)
+	}
+
+	keyID, parseErr := uuid.Parse(c.QueryParam(apikeyconsts.APIKeyIDParam))
+	if parseErr != nil {
+		return response.ErrorResponse(c, errors.New(apikeyconsts.APIKeyIDRequired), consts.InvalidDataRequest)
+	}
+
+	if err := ctr.svc.RevokeAPIKey(userID.String(), keyID.String()); err != nil {
+		return response.ErrorResponse(c, err, apikeyconsts.ErrorRevokingAPIKey)
+	}
+
+	return response.SuccessResponse(c, apikeyconsts.APIKeyRevokedSuccessfully, nil)
+}
@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"Blog_API/pkg/domain"
+	"Blog_API/pkg/listing"
 	"Blog_API/pkg/types"
+	"Blog_API/pkg/utils"
 	"Blog_API/pkg/utils/consts"
 	blogconsts "Blog_API/pkg/utils/consts/blog"
 	userconsts "Blog_API/pkg/utils/consts/user"
@@ -11,7 +13,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"net/http"
-	"strconv"
+	"net/url"
 	"strings"
 )
 
@@ -60,7 +62,7 @@ func (ctr *blogController) CreateBlogPost(c echo.Context) error {
 		return response.ErrorResponse(c, validationErr, consts.ValidationError)
 	}
 
-	blog, err := ctr.svc.CreateBlogPost(reqBlogPost, userID)
+	blog, err := ctr.service(c).CreateBlogPost(reqBlogPost, userID)
 	if err != nil {
 		return response.ErrorResponse(c, err, blogconsts.ErrorCreatingBlog)
 	}
@@ -70,47 +72,151 @@ func (ctr *blogController) CreateBlogPost(c echo.Context) error {
 
 // GetBlogPost implements domain.BlogController.
 // @Summary Get a blog post
-// @Description Get a blog post
+// @Description Get a blog post by ID or slug, an old slug of a renamed post redirects to the current one. Drafts, scheduled and archived posts are only visible to their author and editors.
 // @Tags Blog
 // @Accept json
 // @Produce json
-// @Param blog_id query string true "Blog ID"
+// @Param Authorization header string false "Bearer <token>"
+// @Param blog_id query string true "Blog ID or slug"
 // @Success 200 {object} types.BlogResp "blog fetched successfully"
+// @Success 301 {string} string "moved to the current slug"
 // @Failure 400 {string} string "invalid data request"
+// @Failure 404 {string} string "blog not found"
 // @Failure 500 {string} string "error getting blog"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
+// @Param If-Modified-Since header string false "Last-Modified of the copy the client holds"
+// @Success 304 {string} string "not modified"
 // @Router /blog/get [get]
 func (ctr *blogController) GetBlogPost(c echo.Context) error {
 
-	reqBlogID, err := extractBlogID(c)
+	reqBlogID := strings.TrimSpace(c.QueryParam(blogconsts.BlogID))
+	if reqBlogID == "" {
+		return response.ErrorResponse(c, errors.New(blogconsts.BlogIDRequired), consts.InvalidDataRequest)
+	}
+
+	// Anything that is not a UUID is taken as a slug
+	if blogID, parseErr := uuid.Parse(reqBlogID); parseErr == nil {
+
+		// The validators come from a few small queries, a client that is up to date never has the post loaded.
+		// Only published posts have them, the others are checked for the viewer first
+		if validators, err := ctr.service(c).GetBlogPostValidators(blogID.String()); err == nil && utils.NotModified(c, validators.ETag, validators.LastModified) {
+			return c.NoContent(http.StatusNotModified)
+		}
+
+		blogPost, err := ctr.service(c).GetBlogPost(viewerID(c), blogID.String())
+		if err != nil {
+			return response.ErrorResponse(c, err, blogconsts.ErrorGettingBlog)
+		}
+
+		// A revalidation that ends in 304 is not a read, on every path, so views only count full responses
+		if utils.NotModified(c, utils.RepresentationETag(blogPost.Version, blogPost.LastModified), blogPost.LastModified) {
+			return c.NoContent(http.StatusNotModified)
+		}
+		ctr.service(c).RecordView(blogPost, viewer(c))
+
+		return response.SuccessResponse(c, blogconsts.BlogFetchSuccessfully, blogPost)
+	}
+
+	blogPost, moved, err := ctr.service(c).GetBlogPostBySlug(viewerID(c), reqBlogID)
 	if err != nil {
-		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+		return response.ErrorResponse(c, err, blogconsts.ErrorGettingBlog)
 	}
 
-	if reqBlogID == "" {
-		return response.ErrorResponse(c, errors.New(blogconsts.BlogIDRequired), consts.InvalidDataRequest)
+	if moved {
+		query := c.Request().URL.Query()
+		query.Set(blogconsts.BlogID, blogPost.Slug)
+		return c.Redirect(http.StatusMovedPermanently, c.Request().URL.Path+"?"+query.Encode())
+	}
+
+	if utils.NotModified(c, utils.RepresentationETag(blogPost.Version, blogPost.LastModified), blogPost.LastModified) {
+		return c.NoContent(http.StatusNotModified)
 	}
+	ctr.service(c).RecordView(blogPost, viewer(c))
 
-	blogPost, err := ctr.svc.GetBlogPost(reqBlogID)
+	return response.SuccessResponse(c, blogconsts.BlogFetchSuccessfully, blogPost)
+}
+
+// GetBlogPostBySlug implements domain.BlogController.
+// @Summary Get a blog post by its permalink
+// @Description Get a blog post by slug, an old slug of a renamed post redirects to the current one
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Param Authorization header string false "Bearer <token>"
+// @Param slug path string true "Slug"
+// @Success 200 {object} types.BlogResp "blog fetched successfully"
+// @Success 301 {string} string "moved to the current slug"
+// @Failure 404 {string} string "blog not found"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
+// @Param If-Modified-Since header string false "Last-Modified of the copy the client holds"
+// @Success 304 {string} string "not modified"
+// @Router /blog/post/{slug} [get]
+func (ctr *blogController) GetBlogPostBySlug(c echo.Context) error {
+
+	slug, err := url.PathUnescape(c.Param(blogconsts.Slug))
+	if err != nil || strings.TrimSpace(slug) == "" {
+		return response.ErrorResponse(c, errors.New(blogconsts.SlugRequired), consts.InvalidDataRequest)
+	}
+
+	blogPost, moved, err := ctr.service(c).GetBlogPostBySlug(viewerID(c), slug)
 	if err != nil {
 		return response.ErrorResponse(c, err, blogconsts.ErrorGettingBlog)
 	}
 
+	if moved {
+		path := c.Request().URL.Path
+		location := path[:strings.LastIndex(path, "/")+1] + url.PathEscape(blogPost.Slug)
+		return c.Redirect(http.StatusMovedPermanently, location)
+	}
+
+	if utils.NotModified(c, utils.RepresentationETag(blogPost.Version, blogPost.LastModified), blogPost.LastModified) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	ctr.service(c).RecordView(blogPost, viewer(c))
+
 	return response.SuccessResponse(c, blogconsts.BlogFetchSuccessfully, blogPost)
 }
 
 // GetBlogPosts implements domain.BlogController.
 // @Summary Get all blog posts
-// @Description Get all blog posts
+// @Description Get a page of the published blog posts, sorted and filtered. The posts come with the counts of their comments and reactions, not the comments and reactions themselves.
 // @Tags Blog
 // @Accept json
 // @Produce json
-// @Success 200 {array} types.BlogResp "blogs fetched successfully"
+// @Param offset query int false "Posts to skip"
+// @Param limit query int false "Posts per page, 20 by default and at most 100"
+// @Param cursor query string false "next_cursor of the previous page, instead of offset"
+// @Param sort query string false "published_at (default), views, reactions_count or comments_count"
+// @Param order query string false "desc (default) or asc"
+// @Param author query string false "User ID of the author"
+// @Param from query string false "Published on or after, a date (2006-01-02) or an RFC 3339 time"
+// @Param to query string false "Published on or before, a date counts as its whole day"
+// @Param category query string false "Slug or ID of a category, its subcategories are included"
+// @Param status query string false "Only published is accepted"
+// @Success 200 {object} types.BlogPage "blogs fetched successfully"
 // @Failure 400 {string} string "invalid data request"
 // @Failure 500 {string} string "error getting blogs"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
+// @Param If-Modified-Since header string false "Last-Modified of the copy the client holds"
+// @Success 304 {string} string "not modified"
 // @Router /blog/getAll [get]
 func (ctr *blogController) GetBlogPosts(c echo.Context) error {
 
-	blogPosts, err := ctr.svc.GetBlogPosts()
+	query, err := bindListQuery(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	notModified, err := ctr.listNotModified(c, query.Category, query)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorGettingBlogs)
+	}
+
+	if notModified {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	blogPosts, err := ctr.service(c).GetBlogPosts(query)
 	if err != nil {
 		return response.ErrorResponse(c, err, blogconsts.ErrorGettingBlogs)
 	}
@@ -118,18 +224,61 @@ func (ctr *blogController) GetBlogPosts(c echo.Context) error {
 	return response.SuccessResponse(c, blogconsts.BlogsFetchSuccessfully, blogPosts)
 }
 
+// SearchBlogPosts implements domain.BlogController.
+// @Summary Search blog posts
+// @Description Full-text search of the published posts in their title, description, content and comments. Every word and "quoted phrase" of q has to match; matches in the title weigh the most. Each hit comes with snippets of the fields that match, the matches wrapped in <mark>.
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Param q query string true "Words and quoted phrases to search for"
+// @Param offset query int false "Hits to skip"
+// @Param limit query int false "Hits per page, 20 by default and at most 100"
+// @Success 200 {object} types.SearchPage "search completed successfully"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 500 {string} string "error searching blogs"
+// @Router /blog/search [get]
+func (ctr *blogController) SearchBlogPosts(c echo.Context) error {
+
+	query := types.SearchQuery{}
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &query); err != nil {
+		return response.ErrorResponse(c, utils.NewStatusError(http.StatusBadRequest, err.Error()), consts.InvalidDataRequest)
+	}
+
+	if err := query.Validate(); err != nil {
+		return response.ErrorResponse(c, utils.NewStatusError(http.StatusBadRequest, err.Error()), consts.InvalidDataRequest)
+	}
+
+	hits, err := ctr.service(c).SearchBlogPosts(query)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorSearchingBlogs)
+	}
+
+	return response.SuccessResponse(c, blogconsts.BlogsFoundSuccessfully, hits)
+}
+
 // GetBlogPostsBasedOnCategory implements domain.BlogController.
 // @Summary Get blog posts based on category
-// @Description Get blog posts based on category
+// @Description Get a page of the published blog posts of a category, paged, sorted and filtered like /blog/getAll
 // @Tags Blog
 // @Accept json
 // @Produce json
-// @Security BearerAuth
-// @Param Authorization header string true "Bearer <token>"
-// @Param category query string true "Category"
-// @Success 200 {array} types.BlogResp "blogs fetched successfully"
+// @Param category query string true "Slug or ID of the category, its subcategories are included"
+// @Param offset query int false "Posts to skip"
+// @Param limit query int false "Posts per page, 20 by default and at most 100"
+// @Param cursor query string false "next_cursor of the previous page, instead of offset"
+// @Param sort query string false "published_at (default), views, reactions_count or comments_count"
+// @Param order query string false "desc (default) or asc"
+// @Param author query string false "User ID of the author"
+// @Param from query string false "Published on or after, a date (2006-01-02) or an RFC 3339 time"
+// @Param to query string false "Published on or before, a date counts as its whole day"
+// @Param status query string false "Only published is accepted"
+// @Success 200 {object} types.BlogPage "blogs fetched successfully"
 // @Failure 400 {string} string "invalid data request"
 // @Failure 500 {string} string "error getting blogs"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
+// @Param If-Modified-Since header string false "Last-Modified of the copy the client holds"
+// @Success 304 {string} string "not modified"
+// @Failure 404 {string} string "category not found"
 // @Router /blog/get/category [get]
 func (ctr *blogController) GetBlogPostsBasedOnCategory(c echo.Context) error {
 
@@ -138,7 +287,21 @@ func (ctr *blogController) GetBlogPostsBasedOnCategory(c echo.Context) error {
 		return response.ErrorResponse(c, errors.New(blogconsts.CategoryRequired), consts.InvalidDataRequest)
 	}
 
-	blogPosts, err := ctr.svc.GetBlogPostsBasedOnCategory(category)
+	query, err := bindListQuery(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	notModified, err := ctr.listNotModified(c, category, query)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorGettingBlogs)
+	}
+
+	if notModified {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	blogPosts, err := ctr.service(c).GetBlogPostsBasedOnCategory(category, query)
 	if err != nil {
 		return response.ErrorResponse(c, err, blogconsts.ErrorGettingBlogs)
 	}
@@ -148,15 +311,22 @@ func (ctr *blogController) GetBlogPostsBasedOnCategory(c echo.Context) error {
 
 // GetBlogPostsOfUser implements domain.BlogController.
 // @Summary Get all blog posts of a user
-// @Description Get all blog posts of a user
+// @Description Get a page of the posts of the signed in user in every status, paged, sorted and filtered like /blog/getAll
 // @Tags Blog
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer <token>"
-// @Param blog_id query string true "Blog ID"
-// @Param user_id query string true "User ID"
 // @Param blog_ids query string false "Blog IDs"
-// @Success 200 {array} types.BlogResp "blogs fetched successfully"
+// @Param offset query int false "Posts to skip"
+// @Param limit query int false "Posts per page, 20 by default and at most 100"
+// @Param cursor query string false "next_cursor of the previous page, instead of offset"
+// @Param sort query string false "published_at (default), views, reactions_count or comments_count"
+// @Param order query string false "desc (default) or asc"
+// @Param from query string false "Published on or after, a date (2006-01-02) or an RFC 3339 time"
+// @Param to query string false "Published on or before, a date counts as its whole day"
+// @Param category query string false "Slug or ID of a category, its subcategories are included"
+// @Param status query string false "draft, scheduled, published or archived"
+// @Success 200 {object} types.BlogPage "blogs fetched successfully"
 // @Failure 400 {string} string "invalid data request"
 // @Failure 500 {string} string "error getting blogs"
 // @Router /blog/get/user [get]
@@ -167,7 +337,12 @@ func (ctr *blogController) GetBlogPostsOfUser(c echo.Context) error {
 		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
 	}
 
-	blogPosts, err := ctr.svc.GetBlogPostsOfUser(userID, blogIDs)
+	query, err := bindListQuery(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	blogPosts, err := ctr.service(c).GetBlogPostsOfUser(userID, blogIDs, query)
 	if err != nil {
 		return response.ErrorResponse(c, err, blogconsts.ErrorGettingBlogs)
 	}
@@ -183,11 +358,14 @@ func (ctr *blogController) GetBlogPostsOfUser(c echo.Context) error {
 // @Produce json
 // @Security BearerAuth
 // @Param Authorization header string true "Bearer <token>"
+// @Param If-Match header string true "ETag of the version being changed, or *"
 // @Param blog_id query string true "Blog ID"
 // @Param blogPost body types.UpdateBlogPostRequest true "update blog post request"
 // @Success 200 {object} types.BlogResp "blog updated successfully"
 // @Failure 400 {string} string "invalid data request"
 // @Failure 500 {string} string "error updating blog"
+// @Failure 412 {string} string "the resource was changed by someone else"
+// @Failure 428 {string} string "If-Match header is required"
 // @Router /blog/update [put]
 func (ctr *blogController) UpdateBlogPost(c echo.Context) error {
 
@@ -209,14 +387,116 @@ func (ctr *blogController) UpdateBlogPost(c echo.Context) error {
 		return response.ErrorResponse(c, err, consts.ValidationError)
 	}
 
-	blog, err := ctr.svc.UpdateBlogPost(userID, reqBlogID, updateBlogReq)
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.PreconditionFailed)
+	}
+
+	blog, err := ctr.service(c).UpdateBlogPost(userID, reqBlogID, version, updateBlogReq)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorUpdatingBlog)
+	}
+
+	utils.SetVersionETag(c, blog.Version)
+
+	return response.SuccessResponse(c, blogconsts.BlogUpdatedSuccessfully, blog)
+}
+
+// PatchBlogPost implements domain.BlogController.
+// @Summary Partially update a blog post
+// @Description Apply a JSON merge patch (RFC 7396) to a blog post, only the fields in the patch are changed and null clears a field. The patched post is validated as a whole.
+// @Tags Blog
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param If-Match header string true "ETag of the version being changed, or *"
+// @Param blog_id query string true "Blog ID"
+// @Param patch body types.BlogPostPatch true "merge patch"
+// @Success 200 {object} types.BlogResp "blog updated successfully"
+// @Failure 400 {string} string "invalid merge patch"
+// @Failure 403 {string} string "you are not authorized to update this blog"
+// @Failure 415 {string} string "merge patch must be sent as application/merge-patch+json"
+// @Failure 422 {string} string "the patched blog is not valid"
+// @Failure 412 {string} string "the resource was changed by someone else"
+// @Failure 428 {string} string "If-Match header is required"
+// @Router /blog/update [patch]
+func (ctr *blogController) PatchBlogPost(c echo.Context) error {
+
+	userID, reqBlogID, err := extractUserIDAndReqBlogID(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	patch, err := readMergePatch(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.PreconditionFailed)
+	}
+
+	blog, err := ctr.service(c).PatchBlogPost(userID, reqBlogID, version, patch)
 	if err != nil {
 		return response.ErrorResponse(c, err, blogconsts.ErrorUpdatingBlog)
 	}
 
+	utils.SetVersionETag(c, blog.Version)
+
 	return response.SuccessResponse(c, blogconsts.BlogUpdatedSuccessfully, blog)
 }
 
+// ChangeBlogPostStatus implements domain.BlogController.
+// @Summary Change the status of a blog post
+// @Description Move a blog post between draft, scheduled, published and archived, scheduling needs a publish_at in the future
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param If-Match header string true "ETag of the version being changed, or *"
+// @Param blog_id query string true "Blog ID"
+// @Param status body types.BlogStatusRequest true "blog status request"
+// @Success 200 {object} types.BlogResp "blog status changed successfully"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 403 {string} string "you are not authorized to update this blog"
+// @Failure 409 {string} string "invalid status transition"
+// @Failure 412 {string} string "the resource was changed by someone else"
+// @Failure 428 {string} string "If-Match header is required"
+// @Router /blog/status [put]
+func (ctr *blogController) ChangeBlogPostStatus(c echo.Context) error {
+
+	userID, reqBlogID, err := extractUserIDAndReqBlogID(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	statusReq := types.BlogStatusRequest{}
+	if err := c.Bind(&statusReq); err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	if err := statusReq.Validate(); err != nil {
+		return response.ErrorResponse(c, err, consts.ValidationError)
+	}
+
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.PreconditionFailed)
+	}
+
+	blog, err := ctr.service(c).ChangeBlogPostStatus(userID, reqBlogID, version, statusReq)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorChangingBlogStatus)
+	}
+
+	utils.SetVersionETag(c, blog.Version)
+
+	return response.SuccessResponse(c, blogconsts.BlogStatusChanged, blog)
+}
+
 // DeleteBlogPost implements domain.BlogController.
 // @Summary Delete a blog post
 // @Description Delete a blog post
@@ -225,10 +505,13 @@ func (ctr *blogController) UpdateBlogPost(c echo.Context) error {
 // @Produce json
 // @Security BearerAuth
 // @Param Authorization header string true "Bearer <token>"
+// @Param If-Match header string true "ETag of the version being changed, or *"
 // @Param blog_id query string true "Blog ID"
 // @Success 200 {string} string "blog deleted successfully"
 // @Failure 400 {string} string "invalid data request"
 // @Failure 500 {string} string "error deleting blog"
+// @Failure 412 {string} string "the resource was changed by someone else"
+// @Failure 428 {string} string "If-Match header is required"
 // @Router /blog/delete [delete]
 func (ctr *blogController) DeleteBlogPost(c echo.Context) error {
 
@@ -241,7 +524,12 @@ func (ctr *blogController) DeleteBlogPost(c echo.Context) error {
 		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
 	}
 
-	if err := ctr.svc.DeleteBlogPost(userID, reqBlogID); err != nil {
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.PreconditionFailed)
+	}
+
+	if err := ctr.service(c).DeleteBlogPost(userID, reqBlogID, version); err != nil {
 		return response.ErrorResponse(c, err, blogconsts.ErrorDeletingBlog)
 	}
 
@@ -257,7 +545,7 @@ func (ctr *blogController) DeleteBlogPost(c echo.Context) error {
 // @Security BearerAuth
 // @Param Authorization header string true "Bearer <token>"
 // @Param blog_id query string true "Blog ID"
-// @Param reaction_id query uint64 true "Reaction ID"
+// @Param reaction_id query string true "Reaction ID or code"
 // @Success 200 {object} types.BlogResp "reaction added successfully"
 // @Failure 400 {string} string "invalid data request"
 // @Failure 500 {string} string "error adding or removing reaction"
@@ -274,8 +562,11 @@ func (ctr *blogController) AddAndRemoveReaction(c echo.Context) error {
 	}
 
 	reqReactionID, err := extractReqReactionID(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
 
-	resp, err := ctr.svc.AddAndRemoveReaction(userID, reqBlogID, reqReactionID)
+	resp, err := ctr.service(c).AddAndRemoveReaction(userID, reqBlogID, reqReactionID)
 	if err != nil {
 		return response.ErrorResponse(c, err, blogconsts.ErrorAddingRemovingReaction)
 	}
@@ -317,7 +608,7 @@ func (ctr *blogController) AddComment(c echo.Context) error {
 		return response.ErrorResponse(c, validationErr, consts.ValidationError)
 	}
 
-	resp, err := ctr.svc.AddComment(userID, reqBlogID, reqComment)
+	resp, err := ctr.service(c).AddComment(userID, reqBlogID, reqComment)
 	if err != nil {
 		return response.ErrorResponse(c, err, blogconsts.ErrorAddingComment)
 	}
@@ -352,11 +643,16 @@ func (ctr *blogController) GetComments(c echo.Context) error {
 
 	reqCommentIDs := extractReqCommentIDs(c)
 
-	comments, err := ctr.svc.GetComments(userID, reqBlogID, reqCommentIDs)
+	comments, err := ctr.service(c).GetComments(userID, reqBlogID, reqCommentIDs)
 	if err != nil {
 		return response.ErrorResponse(c, err, blogconsts.ErrorGettingComments)
 	}
 
+	// A single comment is a resource of its own that can be updated with If-Match
+	if len(comments) == 1 {
+		utils.SetVersionETag(c, comments[0].Version)
+	}
+
 	return response.SuccessResponse(c, blogconsts.CommentsFetchSuccessfully, comments)
 }
 
@@ -368,11 +664,14 @@ func (ctr *blogController) GetComments(c echo.Context) error {
 // @Produce json
 // @Security BearerAuth
 // @Param Authorization header string true "Bearer <token>"
+// @Param If-Match header string true "ETag of the version being changed, or *"
 // @Param blog_id query string true "Blog ID"
 // @Param comment_id query string true "Comment ID"
 // @Success 200 {string} string "comment deleted successfully"
 // @Failure 400 {string} string "invalid data request"
 // @Failure 500 {string} string "error deleting comment"
+// @Failure 412 {string} string "the resource was changed by someone else"
+// @Failure 428 {string} string "If-Match header is required"
 // @Router /blog/comment [delete]
 func (ctr *blogController) DeleteComment(c echo.Context) error {
 
@@ -394,7 +693,12 @@ func (ctr *blogController) DeleteComment(c echo.Context) error {
 		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
 	}
 
-	if err := ctr.svc.DeleteComment(userID, reqBlogID, reqCommentID); err != nil {
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.PreconditionFailed)
+	}
+
+	if err := ctr.service(c).DeleteComment(userID, reqBlogID, reqCommentID, version); err != nil {
 		return response.ErrorResponse(c, err, blogconsts.ErrorDeletingComment)
 	}
 
@@ -409,12 +713,15 @@ func (ctr *blogController) DeleteComment(c echo.Context) error {
 // @Produce json
 // @Security BearerAuth
 // @Param Authorization header string true "Bearer <token>"
+// @Param If-Match header string true "ETag of the version being changed, or *"
 // @Param blog_id query string true "Blog ID"
 // @Param comment_id query string true "Comment ID"
 // @Param comment body types.Comment true "Comment"
 // @Success 200 {object} types.BlogResp "comment updated successfully"
 // @Failure 400 {string} string "invalid data request"
 // @Failure 500 {string} string "error updating comment"
+// @Failure 412 {string} string "the resource was changed by someone else"
+// @Failure 428 {string} string "If-Match header is required"
 // @Router /blog/comment [put]
 func (ctr *blogController) UpdateComment(c echo.Context) error {
 
@@ -445,7 +752,12 @@ func (ctr *blogController) UpdateComment(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, err.Error())
 	}
 
-	resp, err := ctr.svc.UpdateComment(userID, reqBlogID, reqCommentID, reqComment)
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.PreconditionFailed)
+	}
+
+	resp, err := ctr.service(c).UpdateComment(userID, reqBlogID, reqCommentID, version, reqComment)
 	if err != nil {
 		return response.ErrorResponse(c, err, blogconsts.ErrorUpdatingComment)
 	}
@@ -481,24 +793,67 @@ func extractUserIDAndReqBlogID(ctx echo.Context) (string, string, error) {
 	return userID.String(), reqBlogID.String(), nil
 }
 
-func extractUserID(ctx echo.Context) (string, error) {
+// bindListQuery reads the paging, sorting and filters of a listing from the query string
+func bindListQuery(ctx echo.Context) (types.BlogListQuery, error) {
 
-	userID, parseErr := uuid.Parse(ctx.Get(userconsts.UserID).(string))
-	if parseErr != nil {
-		return "", response.ErrorResponse(ctx, parseErr, consts.InvalidDataRequest)
+	query := types.BlogListQuery{}
+	if err := (&echo.DefaultBinder{}).BindQueryParams(ctx, &query); err != nil {
+		return query, utils.NewStatusError(http.StatusBadRequest, err.Error())
 	}
 
-	return userID.String(), nil
+	if err := query.Validate(); err != nil {
+		return query, utils.NewStatusError(http.StatusBadRequest, err.Error())
+	}
+
+	return query, nil
 }
 
-func extractBlogID(ctx echo.Context) (string, error) {
+// listNotModified sets the validators of a listing and tells whether the client already holds it.
+// Views are not an edit and leave the validators as they are, so a listing sorted by them has none
+// and is always sent in full.
+func (ctr *blogController) listNotModified(ctx echo.Context, category string, query types.BlogListQuery) (bool, error) {
 
-	reqBlogID, parseErr := uuid.Parse(ctx.QueryParam(blogconsts.BlogID))
+	if query.Sort == listing.SortViews {
+		return false, nil
+	}
+
+	validators, err := ctr.service(ctx).GetBlogPostsValidators(category)
+	if err != nil {
+		return false, err
+	}
+
+	return utils.NotModified(ctx, validators.ETag, validators.LastModified), nil
+}
+
+// service narrows the blog service to the scopes of the API key the request was made with, if any
+func (ctr *blogController) service(ctx echo.Context) domain.BlogService {
+	scopes, _ := ctx.Get(userconsts.APIKeyScopes).([]string)
+	return ctr.svc.WithScopes(scopes)
+}
+
+// viewerID is the authenticated user of a route behind OptionalAuth, empty for anonymous requests
+func viewerID(ctx echo.Context) string {
+	userID, _ := ctx.Get(userconsts.UserID).(string)
+	return userID
+}
+
+// viewer is who is reading, views are counted here rather than in the service so cached reads count too
+func viewer(ctx echo.Context) types.Viewer {
+	return types.Viewer{
+		UserID:    viewerID(ctx),
+		IP:        ctx.RealIP(),
+		UserAgent: ctx.Request().UserAgent(),
+	}
+}
+
+func extractUserID(ctx echo.Context) (string, error) {
+
+	userID, parseErr := uuid.Parse(ctx.Get(userconsts.UserID).(string))
 	if parseErr != nil {
 		return "", response.ErrorResponse(ctx, parseErr, consts.InvalidDataRequest)
 	}
 
-	return reqBlogID.String(), nil
+	return userID.String(), nil
 }
 
 func checkUserIDAndBlogIDIsEmptyOrNot(userID, reqBlogID string) error {
@@ -533,11 +888,12 @@ func checkReqCommentIDIsEmptyOrNot(reqCommentID string) error {
 	return nil
 }
 
-func extractReqReactionID(ctx echo.Context) (uint64, error) {
+// extractReqReactionID reads the reaction type, by its id or by its code
+func extractReqReactionID(ctx echo.Context) (string, error) {
 
-	reqReactionID, parseErr := strconv.ParseUint(ctx.QueryParam(blogconsts.ReactionID), 10, 64)
-	if parseErr != nil {
-		return 0, response.ErrorResponse(ctx, parseErr, consts.InvalidDataRequest)
+	reqReactionID := strings.TrimSpace(ctx.QueryParam(blogconsts.ReactionID))
+	if reqReactionID == "" {
+		return "", utils.NewStatusError(http.StatusBadRequest, blogconsts.ReactionIDRequired)
 	}
 
 	return reqReactionID, nil
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"Blog_API/pkg/types"
+	"Blog_API/pkg/utils"
+	"Blog_API/pkg/utils/consts"
+	blogconsts "Blog_API/pkg/utils/consts/blog"
+	"Blog_API/pkg/utils/response"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strings"
+)
+
+// GetCategories implements domain.BlogController.
+// @Summary List categories
+// @Description List the categories as a tree, each with the number of published posts filed directly under it and with those of its subcategories included
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Success 200 {array} types.CategoryResp "categories fetched successfully"
+// @Failure 500 {string} string "error getting categories"
+// @Router /blog/categories [get]
+func (ctr *blogController) GetCategories(c echo.Context) error {
+
+	categories, err := ctr.service(c).GetCategories()
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorGettingCategories)
+	}
+
+	return response.SuccessResponse(c, blogconsts.CategoriesFetchSuccessfully, categories)
+}
+
+// CreateCategory implements domain.BlogController.
+// @Summary Create a category
+// @Description Create a category, at the top level or under a parent. The slug is made from the name when left out.
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param request body types.CategoryRequest true "Category"
+// @Success 200 {object} types.CategoryResp "category created successfully"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 403 {string} string "you are not authorized to manage categories"
+// @Failure 409 {string} string "the slug is taken by another category"
+// @Failure 500 {string} string "error creating category"
+// @Router /blog/categories [post]
+func (ctr *blogController) CreateCategory(c echo.Context) error {
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	req := types.CategoryRequest{}
+	if err := c.Bind(&req); err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	if err := req.Validate(); err != nil {
+		return response.ErrorResponse(c, utils.NewStatusError(http.StatusBadRequest, err.Error()), consts.InvalidDataRequest)
+	}
+
+	category, err := ctr.service(c).CreateCategory(userID, req)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorCreatingCategory)
+	}
+
+	return response.SuccessResponse(c, blogconsts.CategoryCreatedSuccessfully, category)
+}
+
+// UpdateCategory implements domain.BlogController.
+// @Summary Update a category
+// @Description Rename, describe or move a category. Posts follow a new slug, their ids are returned.
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param category_id query string true "Category ID"
+// @Param request body types.CategoryRequest true "Category"
+// @Success 200 {object} types.CategoryChangeResp "category updated successfully"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 403 {string} string "you are not authorized to manage categories"
+// @Failure 404 {string} string "category not found"
+// @Failure 409 {string} string "the slug is taken by another category"
+// @Failure 500 {string} string "error updating category"
+// @Router /blog/categories [put]
+func (ctr *blogController) UpdateCategory(c echo.Context) error {
+
+	userID, categoryID, err := extractUserIDAndCategoryID(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	req := types.CategoryRequest{}
+	if err := c.Bind(&req); err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	if err := req.Validate(); err != nil {
+		return response.ErrorResponse(c, utils.NewStatusError(http.StatusBadRequest, err.Error()), consts.InvalidDataRequest)
+	}
+
+	resp, err := ctr.service(c).UpdateCategory(userID, categoryID, req)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorUpdatingCategory)
+	}
+
+	return response.SuccessResponse(c, blogconsts.CategoryUpdatedSuccessfully, resp)
+}
+
+// DeleteCategory implements domain.BlogController.
+// @Summary Delete a category
+// @Description Delete a category, its subcategories move up to its parent. A category with posts needs move_to, the category its posts are moved to.
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param category_id query string true "Category ID"
+// @Param move_to query string false "ID or slug of the category the posts move to"
+// @Success 200 {object} types.CategoryChangeResp "category deleted successfully"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 403 {string} string "you are not authorized to manage categories"
+// @Failure 404 {string} string "category not found"
+// @Failure 409 {string} string "the category has posts"
+// @Failure 500 {string} string "error deleting category"
+// @Router /blog/categories [delete]
+func (ctr *blogController) DeleteCategory(c echo.Context) error {
+
+	userID, categoryID, err := extractUserIDAndCategoryID(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	resp, err := ctr.service(c).DeleteCategory(userID, categoryID, strings.TrimSpace(c.QueryParam(blogconsts.MoveTo)))
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorDeletingCategory)
+	}
+
+	return response.SuccessResponse(c, blogconsts.CategoryDeletedSuccessfully, resp)
+}
+
+func extractUserIDAndCategoryID(ctx echo.Context) (string, string, error) {
+
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	categoryID := strings.TrimSpace(ctx.QueryParam(blogconsts.CategoryID))
+	if categoryID == "" {
+		return "", "", utils.NewStatusError(http.StatusBadRequest, blogconsts.CategoryIDRequired)
+	}
+
+	return userID, categoryID, nil
+}
@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/types"
+	"Blog_API/pkg/utils/consts"
+	mfaconsts "Blog_API/pkg/utils/consts/mfa"
+	userconsts "Blog_API/pkg/utils/consts/user"
+	"Blog_API/pkg/utils/response"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Parent struct to implement interface binding
+type mfaController struct {
+	svc domain.MFAService
+}
+
+// Interface binding
+func NewMFAController(svc domain.MFAService) domain.MFAController {
+	return &mfaController{
+		svc: svc,
+	}
+}
+
+// Enroll implements domain.MFAController.
+// @Summary Start two-factor enrollment
+// @Description Generates a TOTP secret for the logged in user, it only takes effect after confirmation
+// @Tags User
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Success 200 {object} types.TOTPEnrollmentResp "secret and provisioning uri"
+// @Failure 409 {string} string "two-factor authentication is already enabled"
+// @Router /user/2fa/enroll [post]
+func (ctr *mfaController) Enroll(c echo.Context) error {
+
+	userID, parseErr := uuid.Parse(c.Get(userconsts.UserID).(string))
+	if parseErr != nil {
+		return response.ErrorResponse(c, parseErr, consts.InvalidDataRequest)
+	}
+
+	enrollment, err := ctr.svc.Enroll(userID.String())
+	if err != nil {
+		return response.ErrorResponse(c, err, mfaconsts.ErrorEnrollingTOTP)
+	}
+
+	return response.SuccessResponse(c, mfaconsts.TOTPEnrollmentStarted, enrollment)
+}
+
+// ConfirmEnrollment implements domain.MFAController.
+// @Summary Confirm two-factor enrollment
+// @Description Enables two-factor authentication with a code from the authenticator app and returns recovery codes, shown only once
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param request body types.TOTPConfirmRequest true "TOTP Confirm Request"
+// @Success 200 {object} types.RecoveryCodesResp "recovery codes"
+// @Failure 401 {string} string "invalid two-factor code"
+// @Failure 429 {string} string "too many invalid two-factor codes"
+// @Router /user/2fa/confirm [post]
+func (ctr *mfaController) ConfirmEnrollment(c echo.Context) error {
+
+	userID, parseErr := uuid.Parse(c.Get(userconsts.UserID).(string))
+	if parseErr != nil {
+		return response.ErrorResponse(c, parseErr, consts.InvalidDataRequest)
+	}
+
+	req := types.TOTPConfirmRequest{}
+	if err := c.Bind(&req); err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	if validationErr := req.Validate(); validationErr != nil {
+		return response.ErrorResponse(c, validationErr, consts.ValidationError)
+	}
+
+	codes, err := ctr.svc.ConfirmEnrollment(userID.String(), req.Code)
+	if err != nil {
+		return response.ErrorResponse(c, err, mfaconsts.ErrorConfirmingTOTP)
+	}
+
+	return response.SuccessResponse(c, mfaconsts.TOTPEnabledSuccessfully, codes)
+}
+
+// LoginWithMFA implements domain.MFAController.
+// @Summary Complete a two-factor login
+// @Description Exchanges the mfa token returned by login and a TOTP or recovery code for a token pair
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body types.MFALoginRequest true "MFA Login Request"
+// @Success 200 {object} types.TokenResp "token pair"
+// @Failure 401 {string} string "invalid two-factor code"
+// @Failure 429 {string} string "too many invalid two-factor codes"
+// @Router /user/login/2fa [post]
+func (ctr *mfaController) LoginWithMFA(c echo.Context) error {
+
+	req := types.MFALoginRequest{}
+	if err := c.Bind(&req); err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	if validationErr := req.Validate(); validationErr != nil {
+		return response.ErrorResponse(c, validationErr, consts.ValidationError)
+	}
+
+	tokens, err := ctr.svc.CompleteLoginChallenge(req, deviceInfo(c, req.DeviceID))
+	if err != nil {
+		return response.ErrorResponse(c, err, mfaconsts.ErrorVerifyingMFA)
+	}
+
+	return response.SuccessResponse(c, userconsts.LoginSuccessful, tokens)
+}
+
+// Disable implements domain.MFAController.
+// @Summary Disable two-factor authentication
+// @Description Turns two-factor authentication off after checking a TOTP or recovery code, all recovery codes are deleted
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param request body types.MFACodeRequest true "MFA Code Request"
+// @Success 200 {string} string "two-factor authentication disabled"
+// @Failure 401 {string} string "invalid two-factor code"
+// @Router /user/2fa/disable [post]
+func (ctr *mfaController) Disable(c echo.Context) error {
+
+	userID, parseErr := uuid.Parse(c.Get(userconsts.UserID).(string))
+	if parseErr != nil {
+		return response.ErrorResponse(c, parseErr, consts.InvalidDataRequest)
+	}
+
+	req := types.MFACodeRequest{}
+	if err := c.Bind(&req); err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	if validationErr := req.Validate(); validationErr != nil {
+		return response.ErrorResponse(c, validationErr, consts.ValidationError)
+	}
+
+	if err := ctr.svc.Disable(userID.String(), req); err != nil {
+		return response.ErrorResponse(c, err, mfaconsts.ErrorDisablingTOTP)
+	}
+
+	return response.SuccessResponse(c, mfaconsts.TOTPDisabledSuccessfully, nil)
+}
+
+// RegenerateRecoveryCodes implements domain.MFAController.
+// @Summary Regenerate recovery codes
+// @Description Replaces all recovery codes after checking a TOTP or recovery code
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param request body types.MFACodeRequest true "MFA Code Request"
+// @Success 200 {object} types.RecoveryCodesResp "recovery codes"
+// @Failure 401 {string} string "invalid two-factor code"
+// @Router /user/2fa/recovery-codes [post]
+func (ctr *mfaController) RegenerateRecoveryCodes(c echo.Context) error {
+
+	userID, parseErr := uuid.Parse(c.Get(userconsts.UserID).(string))
+	if parseErr != nil {
+		return response.ErrorResponse(c, parseErr, consts.InvalidDataRequest)
+	}
+
+	req := types.MFACodeRequest{}
+	if err := c.Bind(&req); err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	if validationErr := req.Validate(); validationErr != nil {
+		return response.ErrorResponse(c, validationErr, consts.ValidationError)
+	}
+
+	codes, err := ctr.svc.RegenerateRecoveryCodes(userID.String(), req)
+	if err != nil {
+		return response.ErrorResponse(c, err, mfaconsts.ErrorGeneratingRecoveryCode)
+	}
+
+	return response.SuccessResponse(c, mfaconsts.RecoveryCodesRegenerated, codes)
+}
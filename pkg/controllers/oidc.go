@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/utils"
+	"Blog_API/pkg/utils/consts"
+	mfaconsts "Blog_API/pkg/utils/consts/mfa"
+	oidcconsts "Blog_API/pkg/utils/consts/oidc"
+	userconsts "Blog_API/pkg/utils/consts/user"
+	"Blog_API/pkg/utils/response"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// Parent struct to implement interface binding
+type oidcController struct {
+	svc      domain.OIDCService
+	tokenSvc domain.TokenService
+	mfaSvc   domain.MFAService
+}
+
+// Interface binding
+func NewOIDCController(svc domain.OIDCService, tokenSvc domain.TokenService, mfaSvc domain.MFAService) domain.OIDCController {
+	return &oidcController{
+		svc:      svc,
+		tokenSvc: tokenSvc,
+		mfaSvc:   mfaSvc,
+	}
+}
+
+// GetProviders implements domain.OIDCController.
+// @Summary List social login providers
+// @Description Returns the names of the configured OpenID Connect providers
+// @Tags User
+// @Produce json
+// @Success 200 {array} string "provider names"
+// @Router /user/oidc/providers [get]
+func (ctr *oidcController) GetProviders(c echo.Context) error {
+	return response.SuccessResponse(c, oidcconsts.ProvidersFetchSuccessfully, ctr.svc.Providers())
+}
+
+// BeginLogin implements domain.OIDCController.
+// @Summary Start a social login
+// @Description Redirects to the provider with an authorization code request protected by state, nonce and PKCE
+// @Tags User
+// @Param provider path string true "Provider name"
+// @Success 302 {string} string "redirect to the provider"
+// @Failure 404 {string} string "unknown login provider"
+// @Router /user/oidc/{provider}/login [get]
+func (ctr *oidcController) BeginLogin(c echo.Context) error {
+
+	provider := c.Param(oidcconsts.Provider)
+	if provider == "" {
+		return response.ErrorResponse(c, utils.NewStatusError(http.StatusBadRequest, oidcconsts.ProviderRequired), consts.InvalidDataRequest)
+	}
+
+	authURL, err := ctr.svc.BeginLogin(provider)
+	if err != nil {
+		return response.ErrorResponse(c, err, oidcconsts.ErrorStartingLogin)
+	}
+
+	return c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback implements domain.OIDCController.
+// @Summary Finish a social login
+// @Description Exchanges the authorization code, links or creates the account and returns a token pair, or an mfa token when two-factor authentication is enabled
+// @Tags User
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success 200 {object} types.TokenResp "token pair"
+// @Failure 400 {string} string "invalid or expired login state"
+// @Failure 401 {string} string "login with the provider failed"
+// @Failure 409 {string} string "local email not verified"
+// @Router /user/oidc/{provider}/callback [get]
+func (ctr *oidcController) Callback(c echo.Context) error {
+
+	// The provider reports a denied or failed authentication in the error parameter
+	if providerErr := c.QueryParam("error"); providerErr != "" {
+		return response.ErrorResponse(c, utils.NewStatusError(http.StatusUnauthorized, providerErr), oidcconsts.ProviderLoginFailed)
+	}
+
+	code, state := c.QueryParam("code"), c.QueryParam("state")
+	if code == "" || state == "" {
+		return response.ErrorResponse(c, utils.NewStatusError(http.StatusBadRequest, oidcconsts.CodeAndStateRequired), consts.InvalidDataRequest)
+	}
+
+	userID, err := ctr.svc.CompleteLogin(c.Param(oidcconsts.Provider), code, state)
+	if err != nil {
+		return response.ErrorResponse(c, err, oidcconsts.ErrorCompletingLogin)
+	}
+
+	challenge, mfaRequired, mfaErr := ctr.mfaSvc.BeginLoginChallenge(userID)
+	if mfaErr != nil {
+		return response.ErrorResponse(c, mfaErr, userconsts.ErrorGeneratingToken)
+	}
+
+	if mfaRequired {
+		return response.SuccessResponse(c, mfaconsts.MFARequired, challenge)
+	}
+
+	tokens, err := ctr.tokenSvc.IssueTokenPair(userID, deviceInfo(c, ""))
+	if err != nil {
+		return response.ErrorResponse(c, err, userconsts.ErrorGeneratingToken)
+	}
+
+	return response.SuccessResponse(c, userconsts.LoginSuccessful, tokens)
+}
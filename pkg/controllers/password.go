@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/types"
+	"Blog_API/pkg/utils/consts"
+	passwordconsts "Blog_API/pkg/utils/consts/password"
+	"Blog_API/pkg/utils/response"
+	"github.com/labstack/echo/v4"
+)
+
+// Parent struct to implement interface binding
+type passwordController struct {
+	svc domain.PasswordService
+}
+
+// Interface binding
+func NewPasswordController(svc domain.PasswordService) domain.PasswordController {
+	return &passwordController{
+		svc: svc,
+	}
+}
+
+// ForgotPassword implements domain.PasswordController.
+// @Summary Request a password reset
+// @Description Mails a single-use password reset token, the response is the same whether or not the email is registered
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body types.ForgotPasswordRequest true "Forgot Password Request"
+// @Success 200 {string} string "password reset requested"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 500 {string} string "error requesting password reset"
+// @Router /user/password/forgot [post]
+func (ctr *passwordController) ForgotPassword(c echo.Context) error {
+
+	req := types.ForgotPasswordRequest{}
+	if err := c.Bind(&req); err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	if validationErr := req.Validate(); validationErr != nil {
+		return response.ErrorResponse(c, validationErr, consts.ValidationError)
+	}
+
+	if err := ctr.svc.ForgotPassword(req.Email); err != nil {
+		return response.ErrorResponse(c, err, passwordconsts.ErrorRequestingPasswordReset)
+	}
+
+	return response.SuccessResponse(c, passwordconsts.PasswordResetRequested, nil)
+}
+
+// ResetPassword implements domain.PasswordController.
+// @Summary Reset a password
+// @Description Sets a new password using a mailed reset token and signs out every session of the user
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body types.ResetPasswordRequest true "Reset Password Request"
+// @Success 200 {string} string "password reset successfully"
+// @Failure 400 {string} string "invalid or expired reset token"
+// @Failure 500 {string} string "error resetting password"
+// @Router /user/password/reset [post]
+func (ctr *passwordController) ResetPassword(c echo.Context) error {
+
+	req := types.ResetPasswordRequest{}
+	if err := c.Bind(&req); err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	if validationErr := req.Validate(); validationErr != nil {
+		return response.ErrorResponse(c, validationErr, consts.ValidationError)
+	}
+
+	if err := ctr.svc.ResetPassword(req.Token, req.Password); err != nil {
+		return response.ErrorResponse(c, err, passwordconsts.ErrorResettingPassword)
+	}
+
+	return response.SuccessResponse(c, passwordconsts.PasswordResetSuccessfully, nil)
+}
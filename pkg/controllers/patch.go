@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"Blog_API/pkg/utils"
+	"Blog_API/pkg/utils/consts"
+	"github.com/labstack/echo/v4"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// readMergePatch returns the raw JSON merge patch of the request, plain application/json is accepted too
+func readMergePatch(c echo.Context) ([]byte, error) {
+
+	mediaType, _, err := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if err != nil || (mediaType != consts.MergePatchContentType && mediaType != echo.MIMEApplicationJSON) {
+		return nil, utils.NewStatusError(http.StatusUnsupportedMediaType, consts.UnsupportedPatchContentType)
+	}
+
+	patch, err := io.ReadAll(io.LimitReader(c.Request().Body, consts.MaxMergePatchSize+1))
+	if err != nil {
+		return nil, utils.NewStatusError(http.StatusBadRequest, err.Error())
+	}
+
+	if len(patch) > consts.MaxMergePatchSize {
+		return nil, utils.NewStatusError(http.StatusRequestEntityTooLarge, consts.MergePatchTooLarge)
+	}
+
+	return patch, nil
+}
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"Blog_API/pkg/types"
+	"Blog_API/pkg/utils"
+	"Blog_API/pkg/utils/consts"
+	blogconsts "Blog_API/pkg/utils/consts/blog"
+	"Blog_API/pkg/utils/response"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strings"
+)
+
+// GetReactors implements domain.BlogController.
+// @Summary List who reacted to a blog post
+// @Description List the users that reacted to a blog post with one reaction type, the latest first, with their display names
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Param blog_id query string true "Blog ID"
+// @Param reaction_id query string true "Reaction ID or code"
+// @Param offset query int false "Reactors to skip"
+// @Param limit query int false "Reactors per page, 20 by default and at most 100"
+// @Success 200 {object} types.ReactorPage "reactors fetched successfully"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 404 {string} string "blog not found"
+// @Failure 500 {string} string "error getting reactors"
+// @Router /blog/reactions [get]
+func (ctr *blogController) GetReactors(c echo.Context) error {
+
+	reqBlogID := strings.TrimSpace(c.QueryParam(blogconsts.BlogID))
+	if reqBlogID == "" {
+		return response.ErrorResponse(c, utils.NewStatusError(http.StatusBadRequest, blogconsts.BlogIDRequired), consts.InvalidDataRequest)
+	}
+
+	reqReactionID, err := extractReqReactionID(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	query := types.ReactorListQuery{}
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &query); err != nil {
+		return response.ErrorResponse(c, utils.NewStatusError(http.StatusBadRequest, err.Error()), consts.InvalidDataRequest)
+	}
+
+	if err := query.Validate(); err != nil {
+		return response.ErrorResponse(c, utils.NewStatusError(http.StatusBadRequest, err.Error()), consts.InvalidDataRequest)
+	}
+
+	reactors, err := ctr.service(c).GetReactors(viewerID(c), reqBlogID, reqReactionID, query)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorGettingReactors)
+	}
+
+	return response.SuccessResponse(c, blogconsts.ReactorsFetchSuccessfully, reactors)
+}
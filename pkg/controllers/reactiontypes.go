@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"Blog_API/pkg/types"
+	"Blog_API/pkg/utils"
+	"Blog_API/pkg/utils/consts"
+	blogconsts "Blog_API/pkg/utils/consts/blog"
+	"Blog_API/pkg/utils/response"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+// GetReactionTypes implements domain.BlogController.
+// @Summary List reaction types
+// @Description List the reaction catalog in the order of a picker. Retired types, which can no longer be given, are left out unless asked for.
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Param include_retired query bool false "Include the retired types"
+// @Success 200 {array} types.ReactionTypeResp "reaction types fetched successfully"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 500 {string} string "error getting reaction types"
+// @Router /blog/reaction-types [get]
+func (ctr *blogController) GetReactionTypes(c echo.Context) error {
+
+	includeRetired := false
+	if value := c.QueryParam(blogconsts.IncludeRetired); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return response.ErrorResponse(c, utils.NewStatusError(http.StatusBadRequest, err.Error()), consts.InvalidDataRequest)
+		}
+		includeRetired = parsed
+	}
+
+	reactionTypes, err := ctr.service(c).GetReactionTypes(includeRetired)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorGettingReactionTypes)
+	}
+
+	return response.SuccessResponse(c, blogconsts.ReactionTypesFetchSuccessfully, reactionTypes)
+}
+
+// CreateReactionType implements domain.BlogController.
+// @Summary Add a reaction type
+// @Description Add a type to the reaction catalog. It is placed after the others and enabled unless told otherwise.
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param request body types.ReactionTypeRequest true "Reaction type"
+// @Success 200 {object} types.ReactionTypeResp "reaction type created successfully"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 403 {string} string "you are not authorized to manage reaction types"
+// @Failure 409 {string} string "the code is taken by another reaction type"
+// @Failure 500 {string} string "error creating reaction type"
+// @Router /blog/reaction-types [post]
+func (ctr *blogController) CreateReactionType(c echo.Context) error {
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	req := types.ReactionTypeRequest{}
+	if err := c.Bind(&req); err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	if err := req.Validate(); err != nil {
+		return response.ErrorResponse(c, utils.NewStatusError(http.StatusBadRequest, err.Error()), consts.InvalidDataRequest)
+	}
+
+	reactionType, err := ctr.service(c).CreateReactionType(userID, req)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorCreatingReactionType)
+	}
+
+	return response.SuccessResponse(c, blogconsts.ReactionTypeCreated, reactionType)
+}
+
+// UpdateReactionType implements domain.BlogController.
+// @Summary Update a reaction type
+// @Description Change the label, emoji, position or enabled flag of a reaction type, a retired type is brought back by enabling it. The code stays as it is.
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param reaction_id query string true "Reaction ID or code"
+// @Param request body types.UpdateReactionTypeRequest true "Fields to change"
+// @Success 200 {object} types.ReactionTypeResp "reaction type updated successfully"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 403 {string} string "you are not authorized to manage reaction types"
+// @Failure 500 {string} string "error updating reaction type"
+// @Router /blog/reaction-types [put]
+func (ctr *blogController) UpdateReactionType(c echo.Context) error {
+
+	userID, reqReactionID, err := extractUserIDAndReactionID(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	req := types.UpdateReactionTypeRequest{}
+	if err := c.Bind(&req); err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	if err := req.Validate(); err != nil {
+		return response.ErrorResponse(c, utils.NewStatusError(http.StatusBadRequest, err.Error()), consts.InvalidDataRequest)
+	}
+
+	reactionType, err := ctr.service(c).UpdateReactionType(userID, reqReactionID, req)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorUpdatingReactionType)
+	}
+
+	return response.SuccessResponse(c, blogconsts.ReactionTypeUpdated, reactionType)
+}
+
+// RetireReactionType implements domain.BlogController.
+// @Summary Retire a reaction type
+// @Description Stop a reaction type from being given. The reactions made with it and their counts stay, and the users that made them can still take them back.
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param reaction_id query string true "Reaction ID or code"
+// @Success 200 {object} types.ReactionTypeResp "reaction type retired successfully"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 403 {string} string "you are not authorized to manage reaction types"
+// @Failure 500 {string} string "error retiring reaction type"
+// @Router /blog/reaction-types [delete]
+func (ctr *blogController) RetireReactionType(c echo.Context) error {
+
+	userID, reqReactionID, err := extractUserIDAndReactionID(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	reactionType, err := ctr.service(c).RetireReactionType(userID, reqReactionID)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorRetiringReactionType)
+	}
+
+	return response.SuccessResponse(c, blogconsts.ReactionTypeRetiredSuccessfully, reactionType)
+}
+
+func extractUserIDAndReactionID(ctx echo.Context) (string, string, error) {
+
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	reqReactionID, err := extractReqReactionID(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	return userID, reqReactionID, nil
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"Blog_API/pkg/utils"
+	"Blog_API/pkg/utils/consts"
+	blogconsts "Blog_API/pkg/utils/consts/blog"
+	"Blog_API/pkg/utils/response"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+// GetBlogRevisions implements domain.BlogController.
+// @Summary List the revisions of a blog post
+// @Description List the revisions of a blog post, newest first, without their text
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param blog_id query string true "Blog ID"
+// @Success 200 {array} types.BlogRevisionResp "revisions fetched successfully"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 403 {string} string "you are not authorized to see the revisions of this blog"
+// @Failure 404 {string} string "blog not found"
+// @Router /blog/revisions [get]
+func (ctr *blogController) GetBlogRevisions(c echo.Context) error {
+
+	userID, reqBlogID, err := extractUserIDAndReqBlogID(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	revisions, err := ctr.service(c).GetBlogRevisions(userID, reqBlogID)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorGettingRevisions)
+	}
+
+	return response.SuccessResponse(c, blogconsts.RevisionsFetchSuccessfully, revisions)
+}
+
+// GetBlogRevision implements domain.BlogController.
+// @Summary Get a revision of a blog post
+// @Description Get the full snapshot of a revision of a blog post
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param blog_id query string true "Blog ID"
+// @Param revision query int true "Revision number"
+// @Success 200 {object} types.BlogRevisionResp "revision fetched successfully"
+// @Failure 400 {string} string "invalid revision number"
+// @Failure 404 {string} string "revision not found"
+// @Router /blog/revision [get]
+func (ctr *blogController) GetBlogRevision(c echo.Context) error {
+
+	userID, reqBlogID, err := extractUserIDAndReqBlogID(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	number, err := parseRevisionNumber(c, blogconsts.Revision)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	revision, err := ctr.service(c).GetBlogRevision(userID, reqBlogID, number)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorGettingRevisions)
+	}
+
+	return response.SuccessResponse(c, blogconsts.RevisionFetchSuccessfully, revision)
+}
+
+// DiffBlogRevisions implements domain.BlogController.
+// @Summary Compare two revisions of a blog post
+// @Description Line by line diff of the fields that differ between two revisions
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param blog_id query string true "Blog ID"
+// @Param from query int true "Older revision number"
+// @Param to query int true "Newer revision number"
+// @Success 200 {object} types.BlogRevisionDiffResp "revisions compared successfully"
+// @Failure 400 {string} string "invalid revision number"
+// @Failure 404 {string} string "revision not found"
+// @Router /blog/revisions/diff [get]
+func (ctr *blogController) DiffBlogRevisions(c echo.Context) error {
+
+	userID, reqBlogID, err := extractUserIDAndReqBlogID(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	from, err := parseRevisionNumber(c, blogconsts.From)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	to, err := parseRevisionNumber(c, blogconsts.To)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	diff, err := ctr.service(c).DiffBlogRevisions(userID, reqBlogID, from, to)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorDiffingRevisions)
+	}
+
+	return response.SuccessResponse(c, blogconsts.RevisionsDiffSuccessfully, diff)
+}
+
+// RestoreBlogRevision implements domain.BlogController.
+// @Summary Restore a blog post to a revision
+// @Description Restore the title, content, description, category and photo of a revision, which is saved as a new revision
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param If-Match header string true "ETag of the version being changed, or *"
+// @Param blog_id query string true "Blog ID"
+// @Param revision query int true "Revision number"
+// @Success 200 {object} types.BlogResp "revision restored successfully"
+// @Failure 400 {string} string "invalid revision number"
+// @Failure 403 {string} string "you are not authorized to update this blog"
+// @Failure 404 {string} string "revision not found"
+// @Failure 412 {string} string "the resource was changed by someone else"
+// @Failure 428 {string} string "If-Match header is required"
+// @Router /blog/revisions/restore [post]
+func (ctr *blogController) RestoreBlogRevision(c echo.Context) error {
+
+	userID, reqBlogID, err := extractUserIDAndReqBlogID(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	number, err := parseRevisionNumber(c, blogconsts.Revision)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.PreconditionFailed)
+	}
+
+	blog, err := ctr.service(c).RestoreBlogRevision(userID, reqBlogID, version, number)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorRestoringRevision)
+	}
+
+	utils.SetVersionETag(c, blog.Version)
+
+	return response.SuccessResponse(c, blogconsts.RevisionRestoredSuccessfully, blog)
+}
+
+func parseRevisionNumber(c echo.Context, param string) (uint, error) {
+
+	number, err := strconv.ParseUint(c.QueryParam(param), 10, 32)
+	if err != nil || number == 0 {
+		return 0, utils.NewStatusError(http.StatusBadRequest, blogconsts.InvalidRevisionNumber)
+	}
+
+	return uint(number), nil
+}
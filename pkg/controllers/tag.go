@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"Blog_API/pkg/types"
+	"Blog_API/pkg/utils"
+	"Blog_API/pkg/utils/consts"
+	blogconsts "Blog_API/pkg/utils/consts/blog"
+	"Blog_API/pkg/utils/response"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+// GetBlogPostsByTags implements domain.BlogController.
+// @Summary Get blog posts by tags
+// @Description Get a page of the published blog posts carrying any or all of the given tags, paged, sorted and filtered like /blog/getAll
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Param tags query string true "Comma separated tags, e.g. go,mysql"
+// @Param match query string false "any (default) or all of the tags"
+// @Param offset query int false "Posts to skip"
+// @Param limit query int false "Posts per page, 20 by default and at most 100"
+// @Param cursor query string false "next_cursor of the previous page, instead of offset"
+// @Param sort query string false "published_at (default), views, reactions_count or comments_count"
+// @Param order query string false "desc (default) or asc"
+// @Param author query string false "User ID of the author"
+// @Param from query string false "Published on or after, a date (2006-01-02) or an RFC 3339 time"
+// @Param to query string false "Published on or before, a date counts as its whole day"
+// @Param category query string false "Slug or ID of a category, its subcategories are included"
+// @Success 200 {object} types.BlogPage "blogs fetched successfully"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 500 {string} string "error getting blogs"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
+// @Param If-Modified-Since header string false "Last-Modified of the copy the client holds"
+// @Success 304 {string} string "not modified"
+// @Router /blog/get/tag [get]
+func (ctr *blogController) GetBlogPostsByTags(c echo.Context) error {
+
+	query, err := bindListQuery(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	notModified, err := ctr.listNotModified(c, query.Category, query)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorGettingBlogs)
+	}
+
+	if notModified {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	blogPosts, err := ctr.service(c).GetBlogPostsByTags(query)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorGettingBlogs)
+	}
+
+	return response.SuccessResponse(c, blogconsts.BlogsFetchSuccessfully, blogPosts)
+}
+
+// GetTags implements domain.BlogController.
+// @Summary List tags
+// @Description List the tags of the published posts with the number of published posts carrying each, the most used first
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Param q query string false "Only the tags starting with it"
+// @Param offset query int false "Tags to skip"
+// @Param limit query int false "Tags per page, 20 by default and at most 100"
+// @Success 200 {object} types.TagPage "tags fetched successfully"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 500 {string} string "error getting tags"
+// @Router /blog/tags [get]
+func (ctr *blogController) GetTags(c echo.Context) error {
+
+	query := types.TagListQuery{}
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &query); err != nil {
+		return response.ErrorResponse(c, utils.NewStatusError(http.StatusBadRequest, err.Error()), consts.InvalidDataRequest)
+	}
+
+	if err := query.Validate(); err != nil {
+		return response.ErrorResponse(c, utils.NewStatusError(http.StatusBadRequest, err.Error()), consts.InvalidDataRequest)
+	}
+
+	tags, err := ctr.service(c).GetTags(query)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorGettingTags)
+	}
+
+	return response.SuccessResponse(c, blogconsts.TagsFetchSuccessfully, tags)
+}
+
+// SuggestTags implements domain.BlogController.
+// @Summary Autocomplete tags
+// @Description Suggest the tags in use that start with what was typed so far, the most used first
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Param q query string true "Start of the tag"
+// @Param limit query int false "Suggestions, 10 by default and at most 50"
+// @Success 200 {array} types.TagResp "tags fetched successfully"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 500 {string} string "error getting tags"
+// @Router /blog/tags/suggest [get]
+func (ctr *blogController) SuggestTags(c echo.Context) error {
+
+	prefix := c.QueryParam(blogconsts.Query)
+
+	limit := 0
+	if raw := c.QueryParam(blogconsts.Limit); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			return response.ErrorResponse(c, utils.NewStatusError(http.StatusBadRequest, consts.InvalidDataRequest), consts.InvalidDataRequest)
+		}
+		limit = parsed
+	}
+
+	tags, err := ctr.service(c).SuggestTags(prefix, limit)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorGettingTags)
+	}
+
+	return response.SuccessResponse(c, blogconsts.TagsFetchSuccessfully, tags)
+}
+
+// MergeTags implements domain.BlogController.
+// @Summary Merge tags
+// @Description Move the posts of the from tags to the into tag, created when missing, and delete the from tags. Names are normalized first, so "Go Lang" and "go-lang" are the same tag.
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param request body types.MergeTagsRequest true "Tags to merge"
+// @Success 200 {object} types.MergeTagsResp "tags merged successfully"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 403 {string} string "you are not authorized to manage tags"
+// @Failure 404 {string} string "tag not found"
+// @Failure 500 {string} string "error merging tags"
+// @Router /blog/tags/merge [post]
+func (ctr *blogController) MergeTags(c echo.Context) error {
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	req := types.MergeTagsRequest{}
+	if err := c.Bind(&req); err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	if err := req.Validate(); err != nil {
+		return response.ErrorResponse(c, utils.NewStatusError(http.StatusBadRequest, err.Error()), consts.InvalidDataRequest)
+	}
+
+	resp, err := ctr.service(c).MergeTags(userID, req)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorMergingTags)
+	}
+
+	return response.SuccessResponse(c, blogconsts.TagsMergedSuccessfully, resp)
+}
@@ -6,11 +6,12 @@ import (
 	"Blog_API/pkg/types"
 	"Blog_API/pkg/utils"
 	"Blog_API/pkg/utils/consts"
+	mfaconsts "Blog_API/pkg/utils/consts/mfa"
+	tokenconsts "Blog_API/pkg/utils/consts/token"
 	"Blog_API/pkg/utils/consts/user"
 	"Blog_API/pkg/utils/response"
 	"errors"
 	"fmt"
-	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/nfnt/resize"
@@ -23,25 +24,32 @@ import (
 )
 
 type userController struct {
-	svc domain.Service
+	svc      domain.Service
+	tokenSvc domain.TokenService
+	mfaSvc   domain.MFAService
 }
 
-func SetUserController(svc domain.Service) domain.Controller {
+func SetUserController(svc domain.Service, tokenSvc domain.TokenService, mfaSvc domain.MFAService) domain.Controller {
 	return &userController{
-		svc: svc,
+		svc:      svc,
+		tokenSvc: tokenSvc,
+		mfaSvc:   mfaSvc,
 	}
 }
 
 // Login godoc
 // @Summary User login
-// @Description Logs in a user and returns a JWT token
+// @Description Logs in a user and returns a short-lived JWT access token with a refresh token, or an mfa token to complete at /user/login/2fa when two-factor authentication is enabled
 // @Tags User
 // @Accept json
 // @Produce json
 // @Param login body types.LoginRequest true "Login Request"
-// @Success 200 {string} string "JWT Token"
+// @Success 200 {object} types.TokenResp "token pair"
+// @Success 200 {object} types.MFAChallengeResp "two-factor code required"
 // @Failure 400 {string} string "invalid data request"
 // @Failure 401 {string} string "invalid email or password"
+// @Failure 423 {string} string "account locked"
+// @Failure 429 {string} string "too many failed login attempts"
 // @Router /user/login [post]
 // Login implements domain.Controller.
 func (ctr *userController) Login(ctx echo.Context) error {
@@ -56,22 +64,126 @@ func (ctr *userController) Login(ctx echo.Context) error {
 		return response.ErrorResponse(ctx, validationErr, consts.ValidationError)
 	}
 
-	userID, loginErr := ctr.svc.Login(reqUser.Email, reqUser.Password)
+	userID, loginErr := ctr.svc.Login(reqUser.Email, reqUser.Password, ctx.RealIP())
 	if loginErr != nil {
 		return response.ErrorResponse(ctx, loginErr, userconsts.InvalidEmailOrPassword)
 	}
 
-	tokenString, tokenErr := generateToken(userID, reqUser.Email)
+	challenge, mfaRequired, mfaErr := ctr.mfaSvc.BeginLoginChallenge(userID)
+	if mfaErr != nil {
+		return response.ErrorResponse(ctx, mfaErr, userconsts.ErrorGeneratingToken)
+	}
+
+	if mfaRequired {
+		return response.SuccessResponse(ctx, mfaconsts.MFARequired, challenge)
+	}
+
+	tokens, tokenErr := ctr.tokenSvc.IssueTokenPair(userID, deviceInfo(ctx, reqUser.DeviceID))
 	if tokenErr != nil {
 		return response.ErrorResponse(ctx, tokenErr, userconsts.ErrorGeneratingToken)
 	}
 
-	return response.SuccessResponse(ctx, userconsts.LoginSuccessful, tokenString)
+	return response.SuccessResponse(ctx, userconsts.LoginSuccessful, tokens)
+}
+
+// RefreshToken godoc
+// @Summary Refresh access token
+// @Description Exchanges a refresh token for a new access and refresh token pair, the presented refresh token can not be used again
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param refresh body types.RefreshTokenRequest true "Refresh Token Request"
+// @Success 200 {object} types.TokenResp "token pair"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 401 {string} string "invalid refresh token"
+// @Router /user/token/refresh [post]
+// RefreshToken implements domain.Controller.
+func (ctr *userController) RefreshToken(ctx echo.Context) error {
+
+	req := types.RefreshTokenRequest{}
+
+	if err := ctx.Bind(&req); err != nil {
+		return response.ErrorResponse(ctx, err, consts.InvalidDataRequest)
+	}
+
+	if validationErr := req.Validate(); validationErr != nil {
+		return response.ErrorResponse(ctx, validationErr, consts.ValidationError)
+	}
+
+	tokens, err := ctr.tokenSvc.Refresh(req.RefreshToken, deviceInfo(ctx, req.DeviceID))
+	if err != nil {
+		return response.ErrorResponse(ctx, err, tokenconsts.ErrorRefreshingToken)
+	}
+
+	return response.SuccessResponse(ctx, tokenconsts.TokenRefreshedSuccessfully, tokens)
+}
+
+// GetSessions godoc
+// @Summary List active sessions
+// @Description Lists the active login sessions of the logged in user
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Success 200 {array} types.SessionResp "sessions fetched successfully"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 500 {string} string "error getting sessions"
+// @Router /user/sessions [get]
+// GetSessions implements domain.Controller.
+func (ctr *userController) GetSessions(ctx echo.Context) error {
+
+	userID, parseErr := uuid.Parse(ctx.Get(userconsts.UserID).(string))
+	if parseErr != nil {
+		return response.ErrorResponse(ctx, parseErr, consts.InvalidDataRequest)
+	}
+
+	currentSessionID, _ := ctx.Get(userconsts.SessionID).(string)
+
+	sessions, err := ctr.tokenSvc.GetSessions(userID.String(), currentSessionID)
+	if err != nil {
+		return response.ErrorResponse(ctx, err, tokenconsts.ErrorGettingSessions)
+	}
+
+	return response.SuccessResponse(ctx, tokenconsts.SessionsFetchSuccessfully, sessions)
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Description Revokes a login session of the logged in user, its refresh and access tokens stop working
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param session_id query string true "Session ID"
+// @Success 200 {string} string "session revoked successfully"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 404 {string} string "session not found"
+// @Router /user/sessions [delete]
+// RevokeSession implements domain.Controller.
+func (ctr *userController) RevokeSession(c echo.Context) error {
+
+	userID, parseErr := uuid.Parse(c.Get(userconsts.UserID).(string))
+	if parseErr != nil {
+		return response.ErrorResponse(c, parseErr, consts.InvalidDataRequest)
+	}
+
+	sessionID, parseErr := uuid.Parse(c.QueryParam(tokenconsts.SessionID))
+	if parseErr != nil {
+		return response.ErrorResponse(c, errors.New(tokenconsts.SessionIDRequired), consts.InvalidDataRequest)
+	}
+
+	if err := ctr.tokenSvc.RevokeSession(userID.String(), sessionID.String()); err != nil {
+		return response.ErrorResponse(c, err, tokenconsts.ErrorRevokingSession)
+	}
+
+	return response.SuccessResponse(c, tokenconsts.SessionRevokedSuccessfully, sessionID.String())
 }
 
 // Logout godoc
 // @Summary User logout
-// @Description Logs out a user
+// @Description Logs out a user and revokes the access token used for the request
 // @Tags User
 // @Accept json
 // @Produce json
@@ -98,6 +210,20 @@ func (ctr *userController) Logout(ctx echo.Context) error {
 		return response.ErrorResponse(ctx, errors.New(userconsts.LogoutFailed), userconsts.UserNotFound)
 	}
 
+	tokenID, _ := ctx.Get(userconsts.TokenID).(string)
+	expiresAt, _ := ctx.Get(userconsts.TokenExpiresAt).(time.Time)
+
+	if err := ctr.svc.Logout(user.ID, tokenID, expiresAt); err != nil {
+		return response.ErrorResponse(ctx, err, userconsts.ErrorRevokingToken)
+	}
+
+	// Tokens issued before refresh tokens existed carry no session
+	if sessionID, _ := ctx.Get(userconsts.SessionID).(string); sessionID != "" {
+		if err := ctr.tokenSvc.RevokeSession(user.ID, sessionID); err != nil {
+			return response.ErrorResponse(ctx, err, tokenconsts.ErrorRevokingSession)
+		}
+	}
+
 	return response.SuccessResponse(ctx, userconsts.LogoutSuccessful, user.Email)
 }
 
@@ -155,6 +281,8 @@ func (ctr *userController) GetUser(c echo.Context) error {
 		return response.ErrorResponse(c, err, userconsts.ErrorGettingUser)
 	}
 
+	utils.SetVersionETag(c, user.Version)
+
 	return response.SuccessResponse(c, userconsts.UserFetchSuccessfully, user)
 }
 
@@ -195,10 +323,13 @@ func (ctr *userController) GetUsers(c echo.Context) error {
 // @Produce json
 // @Security BearerAuth
 // @Param Authorization header string true "Bearer <token>"
+// @Param If-Match header string true "ETag of the version being changed, or *"
 // @Param user body types.UserUpdateRequest true "User Request"
 // @Success 200 {object} types.UserResp "user updated successfully"
 // @Failure 400 {string} string "invalid data request"
 // @Failure 500 {string} string "error updating user"
+// @Failure 412 {string} string "the resource was changed by someone else"
+// @Failure 428 {string} string "If-Match header is required"
 // @Router /user/update [put]
 func (ctr *userController) UpdateUser(c echo.Context) error {
 
@@ -212,30 +343,161 @@ func (ctr *userController) UpdateUser(c echo.Context) error {
 		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
 	}
 
-	profilePicURL, err := uploadProfilePicture(profilePic)
-	if err != nil {
-		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
-	}
-
 	reqUser := types.UserUpdateRequest{}
 	if err := c.Bind(&reqUser); err != nil {
 		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
 	}
 
-	reqUser.ProfilePicture = profilePicURL
+	// The picture URL comes from the upload, not from the client
+	reqUser.ProfilePicture = ""
 
 	if validationErr := reqUser.Validate(); validationErr != nil {
 		return response.ErrorResponse(c, validationErr, consts.ValidationError)
 	}
 
-	user, err := ctr.svc.UpdateUser(userID.String(), reqUser)
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.PreconditionFailed)
+	}
+
+	// The upload is paid for and can not be taken back, a request already known to fail is not worth one.
+	// The service checks the version again when it writes.
+	current, err := ctr.svc.GetUser(userID.String())
 	if err != nil {
 		return response.ErrorResponse(c, err, userconsts.ErrorUpdatingUser)
 	}
+	if err := utils.CheckVersion(version, current.Version); err != nil {
+		return response.ErrorResponse(c, err, consts.PreconditionFailed)
+	}
+
+	profilePicURL, err := uploadProfilePicture(profilePic)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+	reqUser.ProfilePicture = profilePicURL
+
+	user, err := ctr.svc.UpdateUser(userID.String(), version, reqUser)
+	if err != nil {
+		return response.ErrorResponse(c, err, userconsts.ErrorUpdatingUser)
+	}
+
+	utils.SetVersionETag(c, user.Version)
+
+	return response.SuccessResponse(c, userconsts.UserUpdatedSuccessfully, user)
+}
+
+// PatchUser implements domain.Controller.
+// @Summary Partially update the profile of the user
+// @Description Apply a JSON merge patch (RFC 7396) to the profile, only the fields in the patch are changed and null clears a field. The patched profile is validated as a whole. Email, password and role are not part of the profile.
+// @Tags User
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param If-Match header string true "ETag of the version being changed, or *"
+// @Param patch body types.UserProfilePatch true "merge patch"
+// @Success 200 {object} types.UserResp "user updated successfully"
+// @Failure 400 {string} string "invalid merge patch"
+// @Failure 415 {string} string "merge patch must be sent as application/merge-patch+json"
+// @Failure 422 {string} string "the patched profile is not valid"
+// @Failure 412 {string} string "the resource was changed by someone else"
+// @Failure 428 {string} string "If-Match header is required"
+// @Router /user/update [patch]
+func (ctr *userController) PatchUser(c echo.Context) error {
+
+	userID, parseErr := uuid.Parse(c.Get(userconsts.UserID).(string))
+	if parseErr != nil {
+		return response.ErrorResponse(c, parseErr, consts.InvalidDataRequest)
+	}
+
+	patch, err := readMergePatch(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.PreconditionFailed)
+	}
+
+	user, err := ctr.svc.PatchUser(userID.String(), version, patch)
+	if err != nil {
+		return response.ErrorResponse(c, err, userconsts.ErrorUpdatingUser)
+	}
+
+	utils.SetVersionETag(c, user.Version)
 
 	return response.SuccessResponse(c, userconsts.UserUpdatedSuccessfully, user)
 }
 
+// UpdateUserRole implements domain.Controller.
+// @Summary Change the role of a user
+// @Description Change the role of a user, requires the user:manage permission
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param user_id query string true "User ID"
+// @Param role body types.UserRoleRequest true "Role Request"
+// @Success 200 {object} types.UserResp "user role updated successfully"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 403 {string} string "permission denied"
+// @Failure 500 {string} string "error updating user role"
+// @Router /user/role [put]
+func (ctr *userController) UpdateUserRole(c echo.Context) error {
+
+	reqUserID, parseErr := uuid.Parse(c.QueryParam(userconsts.UserID))
+	if parseErr != nil {
+		return response.ErrorResponse(c, parseErr, consts.InvalidDataRequest)
+	}
+
+	reqRole := types.UserRoleRequest{}
+	if err := c.Bind(&reqRole); err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	if validationErr := reqRole.Validate(); validationErr != nil {
+		return response.ErrorResponse(c, validationErr, consts.ValidationError)
+	}
+
+	user, err := ctr.svc.UpdateUserRole(reqUserID.String(), reqRole.Role)
+	if err != nil {
+		return response.ErrorResponse(c, err, userconsts.ErrorUpdatingUserRole)
+	}
+
+	return response.SuccessResponse(c, userconsts.UserRoleUpdated, user)
+}
+
+// UnlockUser implements domain.Controller.
+// @Summary Unlock a user
+// @Description Lifts the lockout of an account locked after failed logins and clears its failure count, requires the user:manage permission
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param user_id query string true "User ID"
+// @Success 200 {object} types.UserResp "user unlocked successfully"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 403 {string} string "permission denied"
+// @Failure 500 {string} string "error unlocking user"
+// @Router /user/unlock [post]
+func (ctr *userController) UnlockUser(c echo.Context) error {
+
+	reqUserID, parseErr := uuid.Parse(c.QueryParam(userconsts.UserID))
+	if parseErr != nil {
+		return response.ErrorResponse(c, parseErr, consts.InvalidDataRequest)
+	}
+
+	user, err := ctr.svc.UnlockUser(reqUserID.String())
+	if err != nil {
+		return response.ErrorResponse(c, err, userconsts.ErrorUnlockingUser)
+	}
+
+	return response.SuccessResponse(c, userconsts.UserUnlockedSuccessfully, user)
+}
+
 // DeleteUser implements domain.Controller.
 // @Summary Delete a user
 // @Description Delete a user
@@ -263,31 +525,13 @@ func (ctr *userController) DeleteUser(c echo.Context) error {
 	return response.SuccessResponse(c, userconsts.UserDeletedSuccessfully, user)
 }
 
-func generateToken(userID string, userEmail string) (string, error) {
-
-	conf := config.LocalConfig
-
-	now := time.Now().UTC()
-	ttl := time.Minute * consts.ExpiredTokenLimit
-
-	jwtClaims := types.JWTClaims{
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: now.Add(ttl).Unix(),
-			IssuedAt:  now.Unix(),
-			NotBefore: now.Unix(),
-		},
-		UserID:    userID,
-		UserEmail: userEmail,
+// deviceInfo collects the client details a token pair is bound to
+func deviceInfo(ctx echo.Context, deviceID string) types.DeviceInfo {
+	return types.DeviceInfo{
+		DeviceID:  deviceID,
+		UserAgent: ctx.Request().UserAgent(),
+		IPAddress: ctx.RealIP(),
 	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwtClaims)
-
-	tokenString, tokenErr := token.SignedString([]byte(conf.JWTSecret))
-	if tokenErr != nil {
-		return "", tokenErr
-	}
-
-	return tokenString, nil
 }
 
 func uploadProfilePicture(profilePic *multipart.FileHeader) (string, error) {
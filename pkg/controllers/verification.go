@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/types"
+	"Blog_API/pkg/utils/consts"
+	userconsts "Blog_API/pkg/utils/consts/user"
+	verificationconsts "Blog_API/pkg/utils/consts/verification"
+	"Blog_API/pkg/utils/response"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Parent struct to implement interface binding
+type verificationController struct {
+	svc domain.VerificationService
+}
+
+// Interface binding
+func NewVerificationController(svc domain.VerificationService) domain.VerificationController {
+	return &verificationController{
+		svc: svc,
+	}
+}
+
+// VerifyEmail implements domain.VerificationController.
+// @Summary Verify an email address
+// @Description Marks the email of a user as verified using the token mailed on sign-up
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body types.VerifyEmailRequest true "Verify Email Request"
+// @Success 200 {string} string "email verified successfully"
+// @Failure 400 {string} string "invalid or expired verification token"
+// @Failure 500 {string} string "error verifying email"
+// @Router /user/verify [post]
+func (ctr *verificationController) VerifyEmail(c echo.Context) error {
+
+	req := types.VerifyEmailRequest{}
+	if err := c.Bind(&req); err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	if validationErr := req.Validate(); validationErr != nil {
+		return response.ErrorResponse(c, validationErr, consts.ValidationError)
+	}
+
+	if err := ctr.svc.VerifyEmail(req.Token); err != nil {
+		return response.ErrorResponse(c, err, verificationconsts.ErrorVerifyingEmail)
+	}
+
+	return response.SuccessResponse(c, verificationconsts.EmailVerifiedSuccessfully, nil)
+}
+
+// ResendVerification implements domain.VerificationController.
+// @Summary Resend the verification email
+// @Description Mails a new verification token to the logged in user, limited to one per minute and five per hour
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Success 200 {string} string "verification email sent"
+// @Failure 409 {string} string "email is already verified"
+// @Failure 429 {string} string "too many verification emails requested"
+// @Router /user/verify/resend [post]
+func (ctr *verificationController) ResendVerification(c echo.Context) error {
+
+	userID, parseErr := uuid.Parse(c.Get(userconsts.UserID).(string))
+	if parseErr != nil {
+		return response.ErrorResponse(c, parseErr, consts.InvalidDataRequest)
+	}
+
+	if err := ctr.svc.ResendVerification(userID.String()); err != nil {
+		return response.ErrorResponse(c, err, verificationconsts.ErrorSendingVerification)
+	}
+
+	return response.SuccessResponse(c, verificationconsts.VerificationSent, nil)
+}
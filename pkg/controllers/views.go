@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"Blog_API/pkg/types"
+	"Blog_API/pkg/utils"
+	"Blog_API/pkg/utils/consts"
+	blogconsts "Blog_API/pkg/utils/consts/blog"
+	"Blog_API/pkg/utils/response"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// GetBlogPostViews implements domain.BlogController.
+// @Summary Get the view history of a blog post
+// @Description Get the views of a blog post per day (UTC), days without views included. Views are written in batches, the last few seconds may not show yet.
+// @Tags Blog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer <token>"
+// @Param blog_id query string true "Blog ID"
+// @Param from query string false "First day, 2006-01-02, 29 days before to by default"
+// @Param to query string false "Last day, 2006-01-02, today by default"
+// @Success 200 {object} types.ViewHistoryResp "views fetched successfully"
+// @Failure 400 {string} string "invalid data request"
+// @Failure 403 {string} string "you are not authorized to see the views of this blog"
+// @Failure 404 {string} string "blog not found"
+// @Failure 500 {string} string "error getting views"
+// @Router /blog/views [get]
+func (ctr *blogController) GetBlogPostViews(c echo.Context) error {
+
+	userID, reqBlogID, err := extractUserIDAndReqBlogID(c)
+	if err != nil {
+		return response.ErrorResponse(c, err, consts.InvalidDataRequest)
+	}
+
+	query := types.ViewHistoryQuery{}
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &query); err != nil {
+		return response.ErrorResponse(c, utils.NewStatusError(http.StatusBadRequest, err.Error()), consts.InvalidDataRequest)
+	}
+
+	history, err := ctr.service(c).GetBlogPostViews(userID, reqBlogID, query)
+	if err != nil {
+		return response.ErrorResponse(c, err, blogconsts.ErrorGettingViews)
+	}
+
+	return response.SuccessResponse(c, blogconsts.ViewsFetchSuccessfully, history)
+}
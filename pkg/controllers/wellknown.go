@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/signing"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// Parent struct to implement interface binding
+type wellKnownController struct {
+	keySet *signing.KeySet
+}
+
+// Interface binding
+func NewWellKnownController(keySet *signing.KeySet) domain.WellKnownController {
+	return &wellKnownController{
+		keySet: keySet,
+	}
+}
+
+// GetJWKS implements domain.WellKnownController.
+// @Summary JSON Web Key Set
+// @Description Public keys that verify the access tokens issued by this server, as described in RFC 7517
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} signing.JWKSet "public keys"
+// @Router /.well-known/jwks.json [get]
+func (ctr *wellKnownController) GetJWKS(c echo.Context) error {
+
+	// Verifiers cache the document, a rotated key is published well before it signs
+	c.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=300")
+
+	return c.JSON(http.StatusOK, ctr.keySet.JWKS())
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/types"
+	"github.com/labstack/echo/v4"
+	"time"
+)
+
+// For database operation (call from service)
+type APIKeyRepository interface {
+	CreateAPIKey(key models.APIKey) error
+	GetAPIKeysOfUser(userID string) ([]models.APIKey, error)
+	GetAPIKeyByHash(keyHash string) (models.APIKey, error)
+	RevokeAPIKey(userID string, keyID string) error
+	TouchAPIKey(keyID string, usedAt time.Time) error
+}
+
+// For service operation (call from controller and middleware)
+type APIKeyService interface {
+	CreateAPIKey(userID string, req types.APIKeyRequest) (types.APIKeyCreatedResp, error)
+	GetAPIKeys(userID string) ([]types.APIKeyResp, error)
+	RevokeAPIKey(userID string, keyID string) error
+	Authenticate(rawKey string) (types.APIKeyPrincipal, error)
+}
+
+// For controller operation (call from main)
+type APIKeyController interface {
+	CreateAPIKey(c echo.Context) error
+	GetAPIKeys(c echo.Context) error
+	RevokeAPIKey(c echo.Context) error
+}
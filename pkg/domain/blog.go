@@ -1,53 +1,141 @@
 package domain
 
 import (
+	"Blog_API/pkg/listing"
 	"Blog_API/pkg/models"
 	"Blog_API/pkg/types"
 	"github.com/labstack/echo/v4"
+	"time"
 )
 
 // For database UserRepository opearation (call from service)
 type BlogRepository interface {
 	CreateBlogPost(blogPost models.BlogPost) error
 	GetBlogPost(blogID string) (models.BlogPost, error)
-	GetBlogPosts() ([]models.BlogPost, error)
-	GetBlogPostsBasedOnCategory(category string) ([]models.BlogPost, error)
-	GetBlogPostsOfUser(userID string, blogIDs []string) ([]models.BlogPost, error)
-	UpdateBlogPost(blogPost models.BlogPost) error
-	DeleteBlogPost(blogID string) error
+	GetBlogPostBySlug(slug string) (models.BlogPost, error)
+	GetSlugHistory(slug string) (models.BlogSlugHistory, error)
+	IsSlugTaken(slug string, exceptBlogID string) (bool, error)
+	RecordSlugChange(blogID string, oldSlug string, newSlug string) error
+	GetBlogPostsWithoutSlug() ([]models.BlogPost, error)
+	SetBlogPostSlug(blogID string, slug string) error
+	ListBlogPosts(query listing.Query) ([]models.BlogPost, int64, error)
+	GetBlogPostLastModified(blogID string) (uint, string, time.Time, error)
+	GetBlogPostsLastModified(categoryIDs []string) (int64, time.Time, error)
+	GetCategories() ([]models.Category, error)
+	ListCategories() ([]models.Category, error)
+	GetCategory(idOrSlug string) (models.Category, error)
+	CreateCategory(category models.Category) error
+	UpdateCategory(category models.Category) ([]string, error)
+	DeleteCategory(categoryID string, moveTo *models.Category) ([]string, error)
+	GetUnfiledCategoryNames() ([]string, error)
+	FileBlogPosts(category string, into models.Category) error
+	ListTags(prefix string, offset int, limit int) ([]models.Tag, int64, error)
+	GetTag(name string) (models.Tag, error)
+	MergeTags(names []string, into string) ([]string, []string, error)
+	AddBlogPostViews(views []models.BlogPostDailyView) error
+	GetBlogPostDailyViews(blogID string, from string, to string) ([]models.BlogPostDailyView, error)
+	ReconcileCounters(fix bool) ([]models.CounterDiscrepancy, error)
+	GetReactionTypes(includeRetired bool) ([]models.ReactionType, error)
+	GetReactionType(id uint64) (models.ReactionType, error)
+	GetReactionTypeByCode(code string) (models.ReactionType, error)
+	CreateReactionType(reactionType models.ReactionType) (models.ReactionType, error)
+	UpdateReactionType(reactionType models.ReactionType) error
+	SeedReactionTypes(reactionTypes []models.ReactionType) (int64, error)
+	ListReactors(blogID string, reactionType uint64, offset int, limit int) ([]models.Reactor, int64, error)
+	BackfillReactionTypeCounts() (int64, error)
+	UpdateBlogPost(blogPost models.BlogPost, withStatus bool, revision models.BlogRevision) error
+	PatchBlogPost(blogPost models.BlogPost, fields []string, withStatus bool, revision models.BlogRevision) error
+	RestoreBlogRevision(blogPost models.BlogPost, revision models.BlogRevision) error
+	GetBlogRevisions(blogID string) ([]models.BlogRevision, error)
+	GetBlogRevision(blogID string, number uint) (models.BlogRevision, error)
+	UpdateBlogPostStatus(blogID string, version uint, status string, publishAt *time.Time, publishedAt *time.Time) error
+	PublishDuePosts(now time.Time) ([]string, error)
+	GetPublishedBlogPosts(afterID string, limit int) ([]models.BlogPost, error)
+	BackfillPostStatuses() error
+	DeleteBlogPost(blogID string, version uint) error
 	AddAndRemoveReaction(userID string, reactionID uint64, blogPost models.BlogPost) (models.BlogPost, error)
 	AddComment(blogPost models.BlogPost, comment models.Comment) (models.BlogPost, error)
 	GetComments(blogID string, commentIDs []string) ([]models.Comment, error)
-	DeleteComment(blogPost models.BlogPost, commentID string) error
+	DeleteComment(blogPost models.BlogPost, commentID string, version uint) error
 	UpdateComment(blogPost models.BlogPost, comment models.Comment) (models.BlogPost, error)
 }
 
 // For service operation (call from controller)
 type BlogService interface {
+	WithScopes(scopes []string) BlogService
 	CreateBlogPost(reqBlogPost types.BlogPostRequest, userID string) (types.BlogResp, error)
-	GetBlogPost(blogID string) (types.BlogResp, error)
-	GetBlogPosts() ([]types.BlogResp, error)
-	GetBlogPostsBasedOnCategory(category string) ([]types.BlogResp, error)
-	GetBlogPostsOfUser(userID string, blogIDs []string) ([]types.BlogResp, error)
-	UpdateBlogPost(userID string, blogID string, blogPost types.UpdateBlogPostRequest) (types.BlogResp, error)
-	DeleteBlogPost(userID string, blogID string) error
-	AddAndRemoveReaction(userID string, blogID string, reactionID uint64) (types.BlogResp, error)
+	GetBlogPost(viewerID string, blogID string) (types.BlogResp, error)
+	GetBlogPostBySlug(viewerID string, slug string) (types.BlogResp, bool, error)
+	GetBlogPosts(query types.BlogListQuery) (types.BlogPage, error)
+	GetBlogPostValidators(blogID string) (types.CacheValidators, error)
+	GetBlogPostsValidators(category string) (types.CacheValidators, error)
+	GetBlogPostsBasedOnCategory(category string, query types.BlogListQuery) (types.BlogPage, error)
+	GetBlogPostsOfUser(userID string, blogIDs []string, query types.BlogListQuery) (types.BlogPage, error)
+	GetBlogPostsByTags(query types.BlogListQuery) (types.BlogPage, error)
+	GetTags(query types.TagListQuery) (types.TagPage, error)
+	SuggestTags(prefix string, limit int) ([]types.TagResp, error)
+	MergeTags(userID string, req types.MergeTagsRequest) (types.MergeTagsResp, error)
+	GetCategories() ([]types.CategoryResp, error)
+	CreateCategory(userID string, req types.CategoryRequest) (types.CategoryResp, error)
+	UpdateCategory(userID string, categoryID string, req types.CategoryRequest) (types.CategoryChangeResp, error)
+	DeleteCategory(userID string, categoryID string, moveTo string) (types.CategoryChangeResp, error)
+	SearchBlogPosts(query types.SearchQuery) (types.SearchPage, error)
+	RecordView(blogPost types.BlogResp, viewer types.Viewer)
+	FlushViews() error
+	GetBlogPostViews(userID string, blogID string, query types.ViewHistoryQuery) (types.ViewHistoryResp, error)
+	UpdateBlogPost(userID string, blogID string, version uint, blogPost types.UpdateBlogPostRequest) (types.BlogResp, error)
+	PatchBlogPost(userID string, blogID string, version uint, patch []byte) (types.BlogResp, error)
+	ChangeBlogPostStatus(userID string, blogID string, version uint, req types.BlogStatusRequest) (types.BlogResp, error)
+	GetBlogRevisions(userID string, blogID string) ([]types.BlogRevisionResp, error)
+	GetBlogRevision(userID string, blogID string, number uint) (types.BlogRevisionResp, error)
+	DiffBlogRevisions(userID string, blogID string, from uint, to uint) (types.BlogRevisionDiffResp, error)
+	RestoreBlogRevision(userID string, blogID string, version uint, number uint) (types.BlogResp, error)
+	PublishScheduledPosts() (int64, error)
+	DeleteBlogPost(userID string, blogID string, version uint) error
+	AddAndRemoveReaction(userID string, blogID string, reaction string) (types.BlogResp, error)
+	GetReactors(viewerID string, blogID string, reaction string, query types.ReactorListQuery) (types.ReactorPage, error)
+	GetReactionTypes(includeRetired bool) ([]types.ReactionTypeResp, error)
+	CreateReactionType(userID string, req types.ReactionTypeRequest) (types.ReactionTypeResp, error)
+	UpdateReactionType(userID string, reaction string, req types.UpdateReactionTypeRequest) (types.ReactionTypeResp, error)
+	RetireReactionType(userID string, reaction string) (types.ReactionTypeResp, error)
 	AddComment(userID string, blogID string, comment types.Comment) (types.BlogResp, error)
 	GetComments(userID string, blogID string, commentIDs []string) ([]types.CommentResp, error)
-	DeleteComment(userID string, blogID string, commentID string) error
-	UpdateComment(userID string, blogID string, commentID string, reqComment types.Comment) (types.BlogResp, error)
+	DeleteComment(userID string, blogID string, commentID string, version uint) error
+	UpdateComment(userID string, blogID string, commentID string, version uint, reqComment types.Comment) (types.BlogResp, error)
 }
 
 // For controller operation (call from main)
 type BlogController interface {
 	CreateBlogPost(c echo.Context) error
 	GetBlogPost(c echo.Context) error
+	GetBlogPostBySlug(c echo.Context) error
 	GetBlogPosts(c echo.Context) error
 	GetBlogPostsBasedOnCategory(c echo.Context) error
 	GetBlogPostsOfUser(c echo.Context) error
+	GetBlogPostsByTags(c echo.Context) error
+	GetTags(c echo.Context) error
+	SuggestTags(c echo.Context) error
+	MergeTags(c echo.Context) error
+	GetCategories(c echo.Context) error
+	CreateCategory(c echo.Context) error
+	UpdateCategory(c echo.Context) error
+	DeleteCategory(c echo.Context) error
+	SearchBlogPosts(c echo.Context) error
+	GetBlogPostViews(c echo.Context) error
 	UpdateBlogPost(c echo.Context) error
+	PatchBlogPost(c echo.Context) error
+	ChangeBlogPostStatus(c echo.Context) error
+	GetBlogRevisions(c echo.Context) error
+	GetBlogRevision(c echo.Context) error
+	DiffBlogRevisions(c echo.Context) error
+	RestoreBlogRevision(c echo.Context) error
 	DeleteBlogPost(c echo.Context) error
 	AddAndRemoveReaction(c echo.Context) error
+	GetReactors(c echo.Context) error
+	GetReactionTypes(c echo.Context) error
+	CreateReactionType(c echo.Context) error
+	UpdateReactionType(c echo.Context) error
+	RetireReactionType(c echo.Context) error
 	AddComment(c echo.Context) error
 	GetComments(c echo.Context) error
 	DeleteComment(c echo.Context) error
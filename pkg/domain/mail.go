@@ -0,0 +1,6 @@
+package domain
+
+// For outgoing email (call from service)
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
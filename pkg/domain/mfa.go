@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/types"
+	"github.com/labstack/echo/v4"
+)
+
+// For database operation (call from service)
+type MFARepository interface {
+	SetTOTPSecret(userID string, secret string) error
+	EnableTOTP(userID string, step int64, codes []models.RecoveryCode) error
+	DisableTOTP(userID string) error
+	UseTOTPStep(userID string, step int64) error
+	ReplaceRecoveryCodes(userID string, codes []models.RecoveryCode) error
+	UseRecoveryCode(userID string, codeHash string) error
+}
+
+// For service operation (call from controller)
+type MFAService interface {
+	Enroll(userID string) (types.TOTPEnrollmentResp, error)
+	ConfirmEnrollment(userID string, code string) (types.RecoveryCodesResp, error)
+	BeginLoginChallenge(userID string) (types.MFAChallengeResp, bool, error)
+	CompleteLoginChallenge(req types.MFALoginRequest, device types.DeviceInfo) (types.TokenResp, error)
+	Disable(userID string, req types.MFACodeRequest) error
+	RegenerateRecoveryCodes(userID string, req types.MFACodeRequest) (types.RecoveryCodesResp, error)
+}
+
+// For controller operation (call from main)
+type MFAController interface {
+	Enroll(c echo.Context) error
+	ConfirmEnrollment(c echo.Context) error
+	LoginWithMFA(c echo.Context) error
+	Disable(c echo.Context) error
+	RegenerateRecoveryCodes(c echo.Context) error
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"Blog_API/pkg/models"
+	"github.com/labstack/echo/v4"
+)
+
+// For database operation (call from service)
+type OIDCRepository interface {
+	CreateAuthState(state models.OIDCAuthState) error
+	ConsumeAuthState(stateHash string) (models.OIDCAuthState, error)
+	PurgeExpiredAuthStates() error
+	GetIdentity(provider string, subject string) (models.UserIdentity, error)
+	CreateIdentity(identity models.UserIdentity) error
+}
+
+// For service operation (call from controller)
+type OIDCService interface {
+	Providers() []string
+	BeginLogin(provider string) (string, error)
+	CompleteLogin(provider string, code string, state string) (string, error)
+}
+
+// For controller operation (call from main)
+type OIDCController interface {
+	GetProviders(c echo.Context) error
+	BeginLogin(c echo.Context) error
+	Callback(c echo.Context) error
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "github.com/labstack/echo/v4"
+
+// For service operation (call from controller)
+type PasswordService interface {
+	ForgotPassword(email string) error
+	ResetPassword(token string, newPassword string) error
+}
+
+// For controller operation (call from main)
+type PasswordController interface {
+	ForgotPassword(c echo.Context) error
+	ResetPassword(c echo.Context) error
+}
@@ -0,0 +1,10 @@
+package domain
+
+import "Blog_API/pkg/search"
+
+// For full-text search of the published posts (call from service)
+type SearchIndex interface {
+	Index(doc search.Document) error
+	Remove(blogID string) error
+	Search(query search.Query, offset int, limit int) ([]search.Hit, int, error)
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/types"
+	"time"
+)
+
+// For revoked access token storage (call from middleware and service)
+type RevocationStore interface {
+	Revoke(jti string, userID string, expiresAt time.Time) error
+	IsRevoked(jti string) (bool, error)
+	PurgeExpired() error
+}
+
+// For database refresh token operation (call from service)
+type TokenRepository interface {
+	CreateRefreshToken(token models.RefreshToken) error
+	GetRefreshTokenByHash(tokenHash string) (models.RefreshToken, error)
+	RotateRefreshToken(oldTokenID string, newToken models.RefreshToken) error
+	GetRefreshTokensOfFamily(familyID string) ([]models.RefreshToken, error)
+	GetActiveRefreshTokensOfUser(userID string) ([]models.RefreshToken, error)
+	RevokeFamily(familyID string) error
+}
+
+// For database single-use user token operation (call from service)
+type UserTokenRepository interface {
+	CreateUserToken(token models.UserToken) error
+	GetUserTokenByHash(purpose string, tokenHash string) (models.UserToken, error)
+	ConsumeUserToken(tokenID string) error
+	InvalidateUserTokens(userID string, purpose string) error
+	GetLatestUserToken(userID string, purpose string) (models.UserToken, error)
+	CountUserTokensSince(userID string, purpose string, since time.Time) (int64, error)
+}
+
+// For token operation (call from controller and other services)
+type TokenService interface {
+	IssueTokenPair(userID string, device types.DeviceInfo) (types.TokenResp, error)
+	Refresh(refreshToken string, device types.DeviceInfo) (types.TokenResp, error)
+	GetSessions(userID string, currentSessionID string) ([]types.SessionResp, error)
+	RevokeSession(userID string, sessionID string) error
+	RevokeAllSessions(userID string) error
+	IssueChallengeToken(userID string, purpose string, ttl time.Duration) (string, time.Time, error)
+	ParseChallengeToken(token string, purpose string) (types.JWTClaims, error)
+	ConsumeChallengeToken(claims types.JWTClaims) error
+}
@@ -5,25 +5,38 @@ import (
 	"Blog_API/pkg/types"
 	"Blog_API/pkg/utils"
 	"github.com/labstack/echo/v4"
+	"time"
 )
 
 // For database UserRepository opearation (call from service)
 type Repository interface {
-	Login(email string, password string) (string, error)
 	CreateUser(user models.User) error
 	GetUser(userID string) (models.User, error)
+	GetUserByEmail(email string) (models.User, error)
 	GetUsers(pagination utils.Page) ([]models.User, error)
 	UpdateUser(user models.User) error
+	PatchUser(user models.User, fields []string) error
+	UpdatePassword(userID string, hashedPassword string) error
+	MarkEmailVerified(userID string) error
+	RecordFailedLogin(userID string) (int, error)
+	LockUser(userID string, until time.Time) error
+	ResetFailedLogins(userID string) error
+	CreateLoginAttempt(attempt models.LoginAttempt) error
+	UpdateUserRole(userID string, role string) error
 	DeleteUser(userID string) error
 }
 
 // For service operation (call from controller)
 type Service interface {
-	Login(email string, password string) (string, error)
+	Login(email string, password string, ipAddress string) (string, error)
+	Logout(userID string, tokenID string, expiresAt time.Time) error
 	CreateUser(user types.SignUpRequest) (types.UserResp, error)
 	GetUser(userID string) (types.UserResp, error)
 	GetUsers(pagination utils.Page) ([]types.UserResp, error)
-	UpdateUser(userID string, user types.UserUpdateRequest) (types.UserResp, error)
+	UpdateUser(userID string, version uint, user types.UserUpdateRequest) (types.UserResp, error)
+	PatchUser(userID string, version uint, patch []byte) (types.UserResp, error)
+	UpdateUserRole(userID string, role string) (types.UserResp, error)
+	UnlockUser(userID string) (types.UserResp, error)
 	DeleteUser(userID string) (string, error)
 }
 
@@ -31,9 +44,15 @@ type Service interface {
 type Controller interface {
 	Login(c echo.Context) error
 	Logout(c echo.Context) error
+	RefreshToken(c echo.Context) error
+	GetSessions(c echo.Context) error
+	RevokeSession(c echo.Context) error
 	CreateUser(c echo.Context) error
 	GetUser(c echo.Context) error
 	GetUsers(c echo.Context) error
 	UpdateUser(c echo.Context) error
+	PatchUser(c echo.Context) error
+	UpdateUserRole(c echo.Context) error
+	UnlockUser(c echo.Context) error
 	DeleteUser(c echo.Context) error
 }
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"Blog_API/pkg/models"
+	"github.com/labstack/echo/v4"
+)
+
+// For service operation (call from controller and other services)
+type VerificationService interface {
+	SendVerification(user models.User) error
+	ResendVerification(userID string) error
+	VerifyEmail(token string) error
+}
+
+// For controller operation (call from main)
+type VerificationController interface {
+	VerifyEmail(c echo.Context) error
+	ResendVerification(c echo.Context) error
+}
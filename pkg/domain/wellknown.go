@@ -0,0 +1,8 @@
+package domain
+
+import "github.com/labstack/echo/v4"
+
+// For controller operation (call from main)
+type WellKnownController interface {
+	GetJWKS(c echo.Context) error
+}
@@ -0,0 +1,36 @@
+package jobs
+
+import (
+	"log"
+	"time"
+)
+
+// Every runs fn in the background once per interval until the process exits
+func Every(name string, interval time.Duration, fn func() error) {
+	if interval <= 0 {
+		log.Printf("job %s disabled: non-positive interval %s", name, interval)
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			run(name, fn)
+		}
+	}()
+}
+
+// run executes a single job iteration and keeps a panicking job from killing the server
+func run(name string, fn func() error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("job %s panicked: %v", name, r)
+		}
+	}()
+
+	if err := fn(); err != nil {
+		log.Printf("job %s failed: %v", name, err)
+	}
+}
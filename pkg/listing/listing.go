@@ -0,0 +1,115 @@
+package listing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Sort keys of the post listings
+const (
+	SortPublishedAt    = "published_at"
+	SortViews          = "views"
+	SortReactionsCount = "reactions_count"
+	SortCommentsCount  = "comments_count"
+)
+
+const (
+	Ascending  = "asc"
+	Descending = "desc"
+)
+
+// How a tag filter of several tags matches
+const (
+	MatchAny = "any" // posts with at least one of the tags
+	MatchAll = "all" // posts with every tag
+)
+
+// ErrInvalidCursor is returned for a cursor that was not issued for the same sort
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// sortExpressions maps a sort key to the SQL it orders by. Drafts have no publication date yet
+// and are placed by their creation date instead.
+var sortExpressions = map[string]string{
+	SortPublishedAt:    "COALESCE(published_at, created_at)",
+	SortViews:          "views",
+	SortReactionsCount: "reactions_count",
+	SortCommentsCount:  "comments_count",
+}
+
+// IsValidSort reports whether posts can be sorted by key
+func IsValidSort(key string) bool {
+	_, ok := sortExpressions[key]
+	return ok
+}
+
+// Expression returns the SQL expression of a sort key
+func Expression(key string) string {
+	return sortExpressions[key]
+}
+
+// Query is a page of posts as the repository reads it. After takes precedence over Offset.
+type Query struct {
+	Offset int
+	Limit  int
+	After  *Cursor
+	Sort   string
+	Order  string
+
+	CategoryIDs []string // a category and its subcategories
+	AuthorID    string
+	Statuses    []string
+	BlogIDs     []string
+	From        *time.Time // inclusive, on the sort date of published_at
+	Until       *time.Time // exclusive
+	Tags        []string   // normalized names
+	TagMatch    string     // MatchAny or MatchAll
+}
+
+// Cursor marks the last post of a page, the next page starts right after it. The sort and order
+// it was issued for are kept, so it can not be replayed against another ordering.
+type Cursor struct {
+	Sort  string     `json:"s"`
+	Order string     `json:"o"`
+	Time  *time.Time `json:"t,omitempty"` // value of the date sort
+	Count uint       `json:"n,omitempty"` // value of the counter sorts
+	ID    string     `json:"id"`
+}
+
+// Value is the sort value the cursor resumes from
+func (c Cursor) Value() interface{} {
+	if c.Sort == SortPublishedAt && c.Time != nil {
+		return *c.Time
+	}
+	return c.Count
+}
+
+// Encode turns the cursor into the opaque token handed to clients
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a token made by Encode and checks it belongs to the given sort and order
+func DecodeCursor(token string, sort string, order string) (*Cursor, error) {
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if cursor.Sort != sort || cursor.Order != order || cursor.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	if sort == SortPublishedAt && cursor.Time == nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,36 @@
+package mailer
+
+import (
+	"Blog_API/pkg/domain"
+	"fmt"
+	"github.com/google/uuid"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileMailer writes every message as an .eml file, for local development
+type fileMailer struct {
+	dir  string
+	from string
+}
+
+// Interface binding
+func NewFileMailer(dir string, from string) domain.Mailer {
+	return &fileMailer{
+		dir:  dir,
+		from: from,
+	}
+}
+
+// Send implements domain.Mailer.
+func (m *fileMailer) Send(to string, subject string, body string) error {
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), uuid.NewString())
+
+	return os.WriteFile(filepath.Join(m.dir, name), buildMessage(m.from, to, subject, body), 0o600)
+}
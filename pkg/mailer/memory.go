@@ -0,0 +1,44 @@
+package mailer
+
+import "sync"
+
+// Message is a mail kept by the memory mailer
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// MemoryMailer keeps sent mail in memory so tests can inspect it
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+// Send implements domain.Mailer.
+func (m *MemoryMailer) Send(to string, subject string, body string) error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, Message{
+		To:      to,
+		Subject: subject,
+		Body:    body,
+	})
+
+	return nil
+}
+
+// Messages returns a copy of every message sent so far
+func (m *MemoryMailer) Messages() []Message {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Message(nil), m.messages...)
+}
@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"Blog_API/pkg/domain"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// smtpMailer delivers mail through an SMTP relay
+type smtpMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// Interface binding
+func NewSMTPMailer(host string, port string, username string, password string, from string) domain.Mailer {
+
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &smtpMailer{
+		addr: net.JoinHostPort(host, port),
+		auth: auth,
+		from: from,
+	}
+}
+
+// Send implements domain.Mailer.
+func (m *smtpMailer) Send(to string, subject string, body string) error {
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{to}, buildMessage(m.from, to, subject, body))
+}
+
+// buildMessage renders a plain text RFC 5322 message
+func buildMessage(from string, to string, subject string, body string) []byte {
+
+	var msg strings.Builder
+
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	return []byte(msg.String())
+}
@@ -2,22 +2,40 @@ package middlewares
 
 import (
 	"Blog_API/pkg/config"
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/permissions"
+	"Blog_API/pkg/signing"
 	"Blog_API/pkg/types"
 	"Blog_API/pkg/utils/consts"
+	apikeyconsts "Blog_API/pkg/utils/consts/apikey"
 	userconsts "Blog_API/pkg/utils/consts/user"
 	"Blog_API/pkg/utils/response"
-	"fmt"
 	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4"
 	"net/http"
 	"strings"
+	"time"
 )
 
+// revocationStore is consulted on every authenticated request
+var revocationStore domain.RevocationStore
+
+// SetRevocationStore sets the store used by Auth to reject revoked tokens
+func SetRevocationStore(store domain.RevocationStore) {
+	revocationStore = store
+}
+
+// apiKeyService authenticates the personal API keys accepted by AuthOrAPIKey
+var apiKeyService domain.APIKeyService
+
+// SetAPIKeyService sets the service used by AuthOrAPIKey to check API keys
+func SetAPIKeyService(svc domain.APIKeyService) {
+	apiKeyService = svc
+}
+
 func Auth(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 
-		conf := config.LocalConfig
-
 		authHeader := c.Request().Header.Get(consts.Authorization)
 		if authHeader == "" {
 			return response.ErrorResponseWithStatus(c, http.StatusUnauthorized, consts.AuthorizationHeaderRequired)
@@ -31,19 +49,34 @@ func Auth(next echo.HandlerFunc) echo.HandlerFunc {
 		var jwtClaims types.JWTClaims
 
 		// Parse the token with claims
-		token, err := jwt.ParseWithClaims(tokenParts[1], &jwtClaims, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(conf.JWTSecret), nil
-		})
+		token, err := jwt.ParseWithClaims(tokenParts[1], &jwtClaims, signing.LocalKeySet.Keyfunc)
 		if err != nil {
 			return response.ErrorResponseWithStatus(c, http.StatusUnauthorized, consts.InvalidToken)
 		}
 
 		if claims, ok := token.Claims.(*types.JWTClaims); ok && token.Valid {
+			// Challenge tokens are only accepted by the endpoint that issued them, never as access tokens
+			if claims.Id == "" || claims.Purpose != "" {
+				return response.ErrorResponseWithStatus(c, http.StatusUnauthorized, consts.InvalidToken)
+			}
+
+			if revocationStore != nil {
+				revoked, err := revocationStore.IsRevoked(claims.Id)
+				if err != nil {
+					return response.ErrorResponseWithStatus(c, http.StatusInternalServerError, consts.ErrorCheckingToken)
+				}
+
+				if revoked {
+					return response.ErrorResponseWithStatus(c, http.StatusUnauthorized, consts.TokenRevoked)
+				}
+			}
+
 			c.Set(userconsts.UserID, claims.UserID)
 			c.Set(userconsts.UserEmail, claims.UserEmail)
+			c.Set(userconsts.TokenID, claims.Id)
+			c.Set(userconsts.TokenExpiresAt, time.Unix(claims.ExpiresAt, 0))
+			c.Set(userconsts.SessionID, claims.SessionID)
+			c.Set(userconsts.UserRole, permissions.Normalize(claims.Role))
 			return next(c)
 		}
 
@@ -51,6 +84,67 @@ func Auth(next echo.HandlerFunc) echo.HandlerFunc {
 	}
 }
 
+// AuthOrAPIKey accepts a bearer JWT like Auth, or a personal API key sent in the X-API-Key header or as the bearer token
+func AuthOrAPIKey(next echo.HandlerFunc) echo.HandlerFunc {
+	jwtAuth := Auth(next)
+	return func(c echo.Context) error {
+
+		rawKey := c.Request().Header.Get(consts.APIKeyHeader)
+		if rawKey == "" {
+			tokenParts := strings.Split(c.Request().Header.Get(consts.Authorization), " ")
+			if len(tokenParts) == 2 && strings.ToLower(tokenParts[0]) == consts.Bearer && strings.HasPrefix(tokenParts[1], apikeyconsts.KeyPrefix) {
+				rawKey = tokenParts[1]
+			}
+		}
+
+		if rawKey == "" || apiKeyService == nil {
+			return jwtAuth(c)
+		}
+
+		principal, err := apiKeyService.Authenticate(rawKey)
+		if err != nil {
+			return response.ErrorResponse(c, err, apikeyconsts.InvalidAPIKey)
+		}
+
+		c.Set(userconsts.UserID, principal.UserID)
+		c.Set(userconsts.UserEmail, principal.UserEmail)
+		c.Set(userconsts.UserRole, principal.Role)
+		c.Set(userconsts.APIKeyID, principal.KeyID)
+		c.Set(userconsts.APIKeyScopes, principal.Scopes)
+		return next(c)
+	}
+}
+
+// OptionalAuth lets anonymous requests through and authenticates the rest like AuthOrAPIKey, so a bad token is still rejected.
+func OptionalAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	authed := AuthOrAPIKey(next)
+	return func(c echo.Context) error {
+
+		if c.Request().Header.Get(consts.Authorization) == "" && c.Request().Header.Get(consts.APIKeyHeader) == "" {
+			return next(c)
+		}
+
+		return authed(c)
+	}
+}
+
+// RequirePermission allows the request only if the role set by Auth grants the permission, it must run after Auth.
+// Requests made with a scoped API key also need the permission among the key scopes.
+func RequirePermission(permission permissions.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+
+			role, _ := c.Get(userconsts.UserRole).(string)
+			scopes, _ := c.Get(userconsts.APIKeyScopes).([]string)
+			if !permissions.HasScoped(role, scopes, permission) {
+				return response.ErrorResponseWithStatus(c, http.StatusForbidden, consts.PermissionDenied)
+			}
+
+			return next(c)
+		}
+	}
+}
+
 func AppKeyAuth(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 
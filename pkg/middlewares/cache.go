@@ -0,0 +1,62 @@
+package middlewares
+
+import (
+	"Blog_API/pkg/utils/consts"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// Cache-Control policies of the route groups, see SetCachePolicies
+var (
+	publicCacheControl  = consts.DefaultPublicCacheControl
+	privateCacheControl = consts.DefaultPrivateCacheControl
+)
+
+// SetCachePolicies sets the Cache-Control sent by PublicCache and PrivateCache, empty values keep the defaults
+func SetCachePolicies(public string, private string) {
+	if public != "" {
+		publicCacheControl = public
+	}
+	if private != "" {
+		privateCacheControl = private
+	}
+}
+
+// PublicCache lets shared caches like a CDN keep the successful reads of a route group. A request
+// with credentials may see more than an anonymous one (drafts for their author), so it is private.
+func PublicCache(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		policy := publicCacheControl
+		if c.Request().Header.Get(consts.Authorization) != "" || c.Request().Header.Get(consts.APIKeyHeader) != "" {
+			policy = privateCacheControl
+		}
+
+		setCacheControl(c, policy)
+		return next(c)
+	}
+}
+
+// PrivateCache keeps the reads of a route group out of shared caches
+func PrivateCache(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		setCacheControl(c, privateCacheControl)
+		return next(c)
+	}
+}
+
+// setCacheControl sends the policy on GET and HEAD responses that can be reused, errors and redirects are left alone
+func setCacheControl(c echo.Context, policy string) {
+
+	method := c.Request().Method
+	if method != http.MethodGet && method != http.MethodHead {
+		return
+	}
+
+	response := c.Response()
+	response.Before(func() {
+		if response.Status == http.StatusOK || response.Status == http.StatusNotModified {
+			response.Header().Set(echo.HeaderCacheControl, policy)
+		}
+	})
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// APIKey is a personal key for scripted access, only its hash and a visible prefix are stored
+type APIKey struct {
+	ID         string     `json:"id" gorm:"primaryKey"`
+	UserID     string     `json:"user_id" gorm:"size:255;index"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix" gorm:"size:32"`
+	KeyHash    string     `json:"-" gorm:"size:64;uniqueIndex"`
+	Scopes     []string   `json:"scopes" gorm:"type:varchar(1024);serializer:json"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -6,23 +6,132 @@ import (
 )
 
 type BlogPost struct {
-	ID             string         `json:"id" gorm:"primaryKey"`
-	UserID         string         `json:"user_id"`
-	Title          string         `json:"title" gorm:"unique"`
-	ContentText    string         `json:"content_text"`
-	PhotoURL       string         `json:"photo_url"`
-	Description    string         `json:"description"`
-	Category       string         `json:"category"`
-	Comments       []Comment      `json:"comments"`
-	CommentsCount  uint           `json:"comments_count"`
-	Reactions      []Reaction     `json:"reactions"`
-	ReactionsCount uint           `json:"reactions_count"`
-	Views          uint           `json:"views"`
-	IsPublished    bool           `json:"is_published"`
-	PublishedAt    time.Time      `json:"published_at"`
-	CreatedAt      time.Time      `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt      time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt      gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	ID                 string                  `json:"id" gorm:"primaryKey"`
+	UserID             string                  `json:"user_id"`
+	Title              string                  `json:"title" gorm:"unique"`
+	Slug               string                  `json:"slug" gorm:"size:255;uniqueIndex"` // NULL until backfilled for posts created before slugs
+	ContentText        string                  `json:"content_text"`
+	PhotoURL           string                  `json:"photo_url"`
+	Description        string                  `json:"description"`
+	CategoryID         *string                 `json:"category_id" gorm:"size:255;index"` // NULL until posts from before categories are filed under one
+	Category           string                  `json:"category"`                          // slug of the category, kept in step with it
+	Tags               []Tag                   `json:"tags" gorm:"many2many:blog_post_tags"`
+	Comments           []Comment               `json:"comments"`
+	CommentsCount      uint                    `json:"comments_count"`
+	Reactions          []Reaction              `json:"reactions"`
+	ReactionsCount     uint                    `json:"reactions_count"`
+	ReactionTypeCounts []BlogPostReactionCount `json:"reaction_type_counts" gorm:"foreignKey:BlogPostID"`
+	Views              uint                    `json:"views"`
+	Version            uint                    `json:"version" gorm:"not null;default:1"` // bumped on every edit, sent as the ETag
+	Status             string                  `json:"status" gorm:"size:16;index"`       // NULL until backfilled for posts created before statuses
+	IsPublished        bool                    `json:"is_published"`                      // kept equal to Status == published for older clients
+	PublishAt          *time.Time              `json:"publish_at" gorm:"index"`
+	PublishedAt        *time.Time              `json:"published_at"` // set on first publication and never moved afterwards
+	CreatedAt          time.Time               `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt          time.Time               `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt          gorm.DeletedAt          `json:"deleted_at" gorm:"index"`
+}
+
+// Category groups posts. Categories nest under a parent, and the listing of a category includes
+// the posts of its subcategories.
+type Category struct {
+	ID          string    `json:"id" gorm:"primaryKey"`
+	ParentID    *string   `json:"parent_id" gorm:"size:255;index"`
+	Name        string    `json:"name" gorm:"size:100"`
+	Slug        string    `json:"slug" gorm:"size:255;uniqueIndex"`
+	Description string    `json:"description"`
+	PostsCount  uint      `json:"posts_count" gorm:"->;-:migration"` // published posts filed directly under it, only read by the category listing
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Tag labels posts, Name is its normalized form ("Machine Learning" is stored as machine-learning)
+type Tag struct {
+	ID         string    `json:"id" gorm:"primaryKey"`
+	Name       string    `json:"name" gorm:"size:64;uniqueIndex"`
+	PostsCount uint      `json:"posts_count" gorm:"->;-:migration"` // published posts, only read by the tag listings
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BlogPostTag is the join table of posts and tags, indexed both ways
+type BlogPostTag struct {
+	BlogPostID string    `json:"blog_post_id" gorm:"primaryKey;size:255"`
+	TagID      string    `json:"tag_id" gorm:"primaryKey;size:255;index"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BlogPostDailyView counts the views of a post on one day (UTC, as 2006-01-02). BlogPost.Views is
+// the sum of them all.
+type BlogPostDailyView struct {
+	BlogPostID string `json:"blog_post_id" gorm:"primaryKey;size:255"`
+	Day        string `json:"day" gorm:"primaryKey;size:10"`
+	Views      uint   `json:"views"`
+}
+
+// BlogPostReactionCount is the number of reactions of one type on a post. Like ReactionsCount,
+// which is their sum, it moves in the same transaction as the reaction.
+type BlogPostReactionCount struct {
+	BlogPostID string `json:"blog_post_id" gorm:"primaryKey;size:255"`
+	Type       uint64 `json:"type" gorm:"primaryKey"`
+	Count      uint   `json:"count"`
+	Code       string `json:"code" gorm:"->;-:migration"` // code of the reaction type, only read along with the counts
+}
+
+// ReactionType is an entry of the reaction catalog. A retired type (Enabled false) can no longer be
+// given, the reactions made with it before stay.
+type ReactionType struct {
+	ID        uint64    `json:"id" gorm:"primaryKey;autoIncrement"`
+	Code      string    `json:"code" gorm:"size:32;uniqueIndex"` // never changes, counts are keyed by it in cached posts
+	Label     string    `json:"label" gorm:"size:64"`
+	Emoji     string    `json:"emoji" gorm:"size:32"`
+	Position  int       `json:"position"` // order of the types in a picker, lowest first
+	Enabled   bool      `json:"enabled" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Reactor is a reaction along with the name of the user who reacted, a query result, not a table
+type Reactor struct {
+	UserID    string    `json:"user_id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Type      uint64    `json:"type"`
+	Code      string    `json:"code"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CounterDiscrepancy is a counter of a post that does not match the rows it counts, as found by
+// the reconciliation. It is a query result, not a table.
+type CounterDiscrepancy struct {
+	BlogPostID string `json:"blog_post_id"`
+	Counter    string `json:"counter"`        // comments_count, reactions_count or reaction_type_count
+	Type       uint64 `json:"type,omitempty"` // reaction type of a reaction_type_count
+	Stored     uint   `json:"stored"`
+	Actual     uint   `json:"actual"`
+}
+
+// BlogSlugHistory keeps the previous slugs of a post so old links redirect to the current one
+type BlogSlugHistory struct {
+	Slug       string    `json:"slug" gorm:"primaryKey;size:255"`
+	BlogPostID string    `json:"blog_post_id" gorm:"size:255;index"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BlogRevision is an immutable snapshot of the content of a post, one is written on creation and on every update
+type BlogRevision struct {
+	ID           string    `json:"id" gorm:"primaryKey"`
+	BlogPostID   string    `json:"blog_post_id" gorm:"size:255;uniqueIndex:idx_blog_revision_number"`
+	Number       uint      `json:"number" gorm:"uniqueIndex:idx_blog_revision_number"`
+	AuthorID     string    `json:"author_id" gorm:"size:255"`
+	Action       string    `json:"action" gorm:"size:16"` // create, update, restore or baseline
+	RestoredFrom *uint     `json:"restored_from"`
+	Title        string    `json:"title"`
+	Slug         string    `json:"slug"`
+	ContentText  string    `json:"content_text"`
+	PhotoURL     string    `json:"photo_url"`
+	Description  string    `json:"description"`
+	Category     string    `json:"category"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
 }
 
 type Comment struct {
@@ -30,6 +139,7 @@ type Comment struct {
 	UserID     string         `json:"user_id" gorm:"size:255"`
 	BlogPostID string         `json:"blog_post_id" gorm:"size:255"`
 	Content    string         `json:"content"`
+	Version    uint           `json:"version" gorm:"not null;default:1"`
 	CreatedAt  time.Time      `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt  time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index"`
@@ -39,7 +149,7 @@ type Reaction struct {
 	ID         string         `json:"id" gorm:"primaryKey"`
 	UserID     string         `json:"user_id" gorm:"size:255"`
 	BlogPostID string         `json:"blog_post_id" gorm:"size:255"`
-	Type       uint64         `json:"type"` // 1: like, 2: love, 3: care, 4: haha, 5: wow, 6: sad, 7: angry
+	Type       uint64         `json:"type"` // id of a ReactionType
 	CreatedAt  time.Time      `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt  time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index"`
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// RecoveryCode is a hashed single-use code that replaces a TOTP code when the authenticator is lost
+type RecoveryCode struct {
+	ID        string     `json:"id" gorm:"primaryKey"`
+	UserID    string     `json:"user_id" gorm:"size:255;index"`
+	CodeHash  string     `json:"-" gorm:"size:64;index"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
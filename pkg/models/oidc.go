@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// UserIdentity links an account of an external OpenID Connect provider to a user
+type UserIdentity struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	UserID    string    `json:"user_id" gorm:"size:255;index"`
+	Provider  string    `json:"provider" gorm:"size:64;uniqueIndex:idx_identity_provider_subject"`
+	Subject   string    `json:"subject" gorm:"size:255;uniqueIndex:idx_identity_provider_subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// OIDCAuthState is a pending authorization request, kept until the provider redirects back
+type OIDCAuthState struct {
+	StateHash    string    `json:"-" gorm:"primaryKey;size:64"`
+	Provider     string    `json:"provider" gorm:"size:64"`
+	Nonce        string    `json:"-"`
+	CodeVerifier string    `json:"-"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
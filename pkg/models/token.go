@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// RevokedToken is an access token that was revoked before its expiry
+type RevokedToken struct {
+	JTI       string    `json:"jti" gorm:"primaryKey;size:64"`
+	UserID    string    `json:"user_id" gorm:"size:255;index"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// RefreshToken is a single-use refresh token, rotated tokens share a FamilyID which identifies the session
+type RefreshToken struct {
+	ID                   string     `json:"id" gorm:"primaryKey"`
+	UserID               string     `json:"user_id" gorm:"size:255;index"`
+	FamilyID             string     `json:"family_id" gorm:"size:255;index"`
+	TokenHash            string     `json:"-" gorm:"size:64;uniqueIndex"`
+	DeviceID             string     `json:"device_id" gorm:"size:255"`
+	UserAgent            string     `json:"user_agent"`
+	IPAddress            string     `json:"ip_address" gorm:"size:64"`
+	AccessTokenID        string     `json:"access_token_id" gorm:"size:64"`
+	AccessTokenExpiresAt time.Time  `json:"access_token_expires_at"`
+	ReplacedByID         string     `json:"replaced_by_id" gorm:"size:255"`
+	SessionCreatedAt     time.Time  `json:"session_created_at"`
+	ExpiresAt            time.Time  `json:"expires_at"`
+	RevokedAt            *time.Time `json:"revoked_at"`
+	CreatedAt            time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// UserToken is a hashed single-use token mailed to a user, such as a password reset link
+type UserToken struct {
+	ID        string     `json:"id" gorm:"primaryKey"`
+	UserID    string     `json:"user_id" gorm:"size:255;index"`
+	Purpose   string     `json:"purpose" gorm:"size:32;index"`
+	TokenHash string     `json:"-" gorm:"size:64;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -9,26 +9,47 @@ import (
 // @Description User model
 type User struct {
 	//gorm.Model // Embedding the gorm.Model for ID, CreatedAt, UpdatedAt, and DeletedAt fields
-	ID             string         `json:"id" gorm:"primaryKey"`
-	CreatedAt      time.Time      `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt      time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt      gorm.DeletedAt `json:"deleted_at" gorm:"index"`
-	Gender         string         `json:"gender"`
-	DateOfBirth    time.Time      `json:"date_of_birth"`
-	Job            string         `json:"job"`
-	City           string         `json:"city"`
-	ZipCode        string         `json:"zipcode"`
-	ProfilePicture string         `json:"profile_picture"`
-	FirstName      string         `json:"first_name"`
-	LastName       string         `json:"last_name"`
-	Email          string         `json:"email"`
-	Password       string         `json:"password"`
-	Phone          string         `json:"phone"`
-	Street         string         `json:"street"`
-	State          string         `json:"state"`
-	Country        string         `json:"country"`
-	Latitude       float64        `json:"latitude"`
-	Longitude      float64        `json:"longitude"`
-	Role           string         `json:"role"`
-	TagsLike       []string       `json:"tags_like" gorm:"type:varchar(255);serializer:json"`
+	ID              string         `json:"id" gorm:"primaryKey"`
+	CreatedAt       time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt       gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	Gender          string         `json:"gender"`
+	DateOfBirth     time.Time      `json:"date_of_birth"`
+	Job             string         `json:"job"`
+	City            string         `json:"city"`
+	ZipCode         string         `json:"zipcode"`
+	ProfilePicture  string         `json:"profile_picture"`
+	FirstName       string         `json:"first_name"`
+	LastName        string         `json:"last_name"`
+	Email           string         `json:"email"`
+	EmailVerified   bool           `json:"email_verified" gorm:"default:false"`
+	EmailVerifiedAt *time.Time     `json:"email_verified_at"`
+	Password        string         `json:"password"`
+	Phone           string         `json:"phone"`
+	Street          string         `json:"street"`
+	State           string         `json:"state"`
+	Country         string         `json:"country"`
+	Latitude        float64        `json:"latitude"`
+	Longitude       float64        `json:"longitude"`
+	Role            string         `json:"role"`
+	TagsLike        []string       `json:"tags_like" gorm:"type:varchar(255);serializer:json"`
+	Version         uint           `json:"version" gorm:"not null;default:1"` // bumped on every profile edit, sent as the ETag
+
+	FailedLoginCount  int        `json:"-" gorm:"default:0"`
+	LastFailedLoginAt *time.Time `json:"-"`
+	LockedUntil       *time.Time `json:"locked_until"`
+
+	TOTPSecret       string `json:"-"`
+	TOTPEnabled      bool   `json:"totp_enabled" gorm:"default:false"`
+	TOTPLastUsedStep int64  `json:"-" gorm:"default:0"`
+}
+
+// LoginAttempt is the audit record of a failed login
+type LoginAttempt struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	UserID    string    `json:"user_id" gorm:"size:255;index"`
+	Email     string    `json:"email" gorm:"size:255;index"`
+	IPAddress string    `json:"ip_address" gorm:"size:64;index"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;index"`
 }
@@ -0,0 +1,213 @@
+package oidc
+
+import (
+	"Blog_API/pkg/signing"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/golang-jwt/jwt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	mockKeyID    = "mock"
+	mockCodeTTL  = time.Minute
+	mockTokenTTL = time.Hour
+)
+
+// MockUser is the identity the mock provider signs every user in as
+type MockUser struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	GivenName     string
+	FamilyName    string
+}
+
+// MockProvider is a local OpenID Connect provider for development and tests, it approves every
+// authorization request as User without showing a login page. Never expose it publicly.
+type MockProvider struct {
+	Issuer   string
+	ClientID string
+	User     MockUser
+
+	key   *rsa.PrivateKey
+	mu    sync.Mutex
+	codes map[string]mockGrant
+}
+
+type mockGrant struct {
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	expiresAt     time.Time
+}
+
+// NewMockProvider generates a fresh signing key for the mock provider
+func NewMockProvider(issuer string, clientID string, user MockUser) (*MockProvider, error) {
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MockProvider{
+		Issuer:   strings.TrimSuffix(issuer, "/"),
+		ClientID: clientID,
+		User:     user,
+		key:      key,
+		codes:    make(map[string]mockGrant),
+	}, nil
+}
+
+// ServeHTTP serves discovery, the authorization and token endpoints and the JWKS
+func (m *MockProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case discoveryPath:
+		writeJSON(w, http.StatusOK, Discovery{
+			Issuer:                m.Issuer,
+			AuthorizationEndpoint: m.Issuer + "/authorize",
+			TokenEndpoint:         m.Issuer + "/token",
+			JWKSURI:               m.Issuer + "/jwks",
+		})
+	case "/authorize":
+		m.authorize(w, r)
+	case "/token":
+		m.token(w, r)
+	case "/jwks":
+		writeJSON(w, http.StatusOK, signing.JWKSet{Keys: []signing.JWK{{
+			KeyType:   "RSA",
+			Use:       "sig",
+			KeyID:     mockKeyID,
+			Algorithm: signing.AlgorithmRS256,
+			N:         base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (m *MockProvider) authorize(w http.ResponseWriter, r *http.Request) {
+
+	query := r.URL.Query()
+	if query.Get("client_id") != m.ClientID || query.Get("response_type") != "code" {
+		oauthError(w, "unauthorized_client")
+		return
+	}
+	if query.Get("code_challenge") == "" || query.Get("code_challenge_method") != CodeChallengeMethod {
+		oauthError(w, "invalid_request")
+		return
+	}
+
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || !redirectURI.IsAbs() {
+		oauthError(w, "invalid_request")
+		return
+	}
+
+	code, err := randomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	m.mu.Lock()
+	m.codes[code] = mockGrant{
+		redirectURI:   redirectURI.String(),
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+		expiresAt:     time.Now().Add(mockCodeTTL),
+	}
+	m.mu.Unlock()
+
+	params := redirectURI.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	redirectURI.RawQuery = params.Encode()
+
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (m *MockProvider) token(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		oauthError(w, "invalid_request")
+		return
+	}
+
+	code := r.PostForm.Get("code")
+
+	m.mu.Lock()
+	grant, ok := m.codes[code]
+	delete(m.codes, code)
+	m.mu.Unlock()
+
+	if !ok || time.Now().After(grant.expiresAt) ||
+		r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("client_id") != m.ClientID ||
+		r.PostForm.Get("redirect_uri") != grant.redirectURI ||
+		CodeChallenge(r.PostForm.Get("code_verifier")) != grant.codeChallenge {
+		oauthError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            m.Issuer,
+		"sub":            m.User.Subject,
+		"aud":            m.ClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(mockTokenTTL).Unix(),
+		"nonce":          grant.nonce,
+		"email":          m.User.Email,
+		"email_verified": m.User.EmailVerified,
+		"given_name":     m.User.GivenName,
+		"family_name":    m.User.FamilyName,
+		"name":           strings.TrimSpace(m.User.GivenName + " " + m.User.FamilyName),
+	})
+	idToken.Header["kid"] = mockKeyID
+
+	signed, err := idToken.SignedString(m.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	accessToken, err := randomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		IDToken:     signed,
+		ExpiresIn:   int(mockTokenTTL / time.Second),
+	})
+}
+
+func oauthError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func randomString() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,15 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// CodeChallengeMethod is the only PKCE method we send, plain is never used
+const CodeChallengeMethod = "S256"
+
+// CodeChallenge derives the S256 challenge of a PKCE code verifier (RFC 7636)
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
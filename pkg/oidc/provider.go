@@ -0,0 +1,275 @@
+// Package oidc is a minimal OpenID Connect relying party: discovery, the authorization code
+// flow with PKCE and ID token verification against the provider's JWKS.
+package oidc
+
+import (
+	"Blog_API/pkg/config"
+	"Blog_API/pkg/signing"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	discoveryPath = "/.well-known/openid-configuration"
+	// keyRefreshInterval limits how often an unknown kid makes us refetch the JWKS
+	keyRefreshInterval = time.Minute
+	// clockSkew is tolerated on the exp and iat of ID tokens
+	clockSkew = time.Minute
+)
+
+var defaultScopes = []string{"openid", "email", "profile"}
+
+// Discovery is the subset of the provider metadata the login flow needs
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// TokenResponse is the token endpoint answer to an authorization code exchange
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Provider talks to one configured OpenID Connect provider
+type Provider struct {
+	conf   config.OIDCProvider
+	client *http.Client
+
+	mu            sync.Mutex
+	discovery     *Discovery
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+// NewProvider returns a provider, metadata is discovered lazily on first use
+func NewProvider(conf config.OIDCProvider) *Provider {
+	if len(conf.Scopes) == 0 {
+		conf.Scopes = defaultScopes
+	}
+	return &Provider{
+		conf:   conf,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name returns the configured provider name
+func (p *Provider) Name() string {
+	return p.conf.Name
+}
+
+// AuthCodeURL returns the URL the user agent is sent to for authentication
+func (p *Provider) AuthCodeURL(state string, nonce string, codeChallenge string) (string, error) {
+
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.conf.ClientID)
+	params.Set("redirect_uri", p.conf.RedirectURL)
+	params.Set("scope", strings.Join(p.conf.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", CodeChallengeMethod)
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return discovery.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange redeems an authorization code together with the PKCE verifier it was requested with
+func (p *Provider) Exchange(code string, codeVerifier string) (TokenResponse, error) {
+
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.conf.RedirectURL)
+	form.Set("client_id", p.conf.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	if p.conf.ClientSecret != "" {
+		form.Set("client_secret", p.conf.ClientSecret)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token TokenResponse
+	if err := p.doJSON(req, &token); err != nil {
+		return TokenResponse{}, fmt.Errorf("token exchange: %w", err)
+	}
+
+	if token.IDToken == "" {
+		return TokenResponse{}, errors.New("token response has no id_token")
+	}
+
+	return token, nil
+}
+
+// VerifyIDToken checks the signature, issuer, audience, lifetime and nonce of an ID token
+func (p *Provider) VerifyIDToken(rawIDToken string, nonce string) (IDTokenClaims, error) {
+
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return IDTokenClaims{}, err
+	}
+
+	var claims IDTokenClaims
+	token, err := jwt.ParseWithClaims(rawIDToken, &claims, p.keyfunc)
+	if err != nil || !token.Valid {
+		return IDTokenClaims{}, fmt.Errorf("invalid id token: %v", err)
+	}
+
+	if claims.Issuer != discovery.Issuer {
+		return IDTokenClaims{}, errors.New("id token issuer mismatch")
+	}
+	if !claims.Audience.Contains(p.conf.ClientID) {
+		return IDTokenClaims{}, errors.New("id token audience mismatch")
+	}
+	if claims.Nonce != nonce {
+		return IDTokenClaims{}, errors.New("id token nonce mismatch")
+	}
+	if claims.Subject == "" {
+		return IDTokenClaims{}, errors.New("id token has no subject")
+	}
+
+	return claims, nil
+}
+
+func (p *Provider) keyfunc(token *jwt.Token) (interface{}, error) {
+
+	switch token.Method.Alg() {
+	case signing.AlgorithmRS256, signing.AlgorithmEdDSA:
+	default:
+		return nil, fmt.Errorf("unexpected signing method %q", token.Method.Alg())
+	}
+
+	kid, _ := token.Header["kid"].(string)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key, ok := p.keys[kid]
+	if !ok && time.Since(p.keysFetchedAt) > keyRefreshInterval {
+		// The provider rotated its keys since the last fetch
+		if err := p.fetchKeysLocked(); err != nil {
+			return nil, err
+		}
+		key, ok = p.keys[kid]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	return key, nil
+}
+
+func (p *Provider) getDiscovery() (*Discovery, error) {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(p.conf.Issuer, "/")+discoveryPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var discovery Discovery
+	if err := p.doJSON(req, &discovery); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+
+	if discovery.Issuer != p.conf.Issuer {
+		return nil, fmt.Errorf("discovery issuer %q does not match configured issuer %q", discovery.Issuer, p.conf.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errors.New("discovery document is missing endpoints")
+	}
+
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+// fetchKeysLocked reloads the provider keys, p.mu must be held and discovery done
+func (p *Provider) fetchKeysLocked() error {
+
+	if p.discovery == nil {
+		return errors.New("provider metadata not discovered")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, p.discovery.JWKSURI, nil)
+	if err != nil {
+		return err
+	}
+
+	var jwks signing.JWKSet
+	if err := p.doJSON(req, &jwks); err != nil {
+		return fmt.Errorf("jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		key, err := jwk.PublicKey()
+		if err != nil {
+			// Keys of types we can not verify with are skipped, not fatal
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+	return nil
+}
+
+func (p *Provider) doJSON(req *http.Request, out interface{}) error {
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d: %s", req.URL.Redacted(), resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return json.Unmarshal(body, out)
+}
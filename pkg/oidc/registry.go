@@ -0,0 +1,40 @@
+package oidc
+
+import (
+	"Blog_API/pkg/config"
+	"sort"
+)
+
+// Registry holds the configured providers by name
+type Registry struct {
+	providers map[string]*Provider
+}
+
+// NewRegistry builds a provider for every configured entry
+func NewRegistry(confs []config.OIDCProvider) *Registry {
+
+	providers := make(map[string]*Provider, len(confs))
+	for _, conf := range confs {
+		providers[conf.Name] = NewProvider(conf)
+	}
+
+	return &Registry{providers: providers}
+}
+
+// Get returns the provider with the given name
+func (r *Registry) Get(name string) (*Provider, bool) {
+	provider, ok := r.providers[name]
+	return provider, ok
+}
+
+// Names returns the provider names in a stable order
+func (r *Registry) Names() []string {
+
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,111 @@
+package permissions
+
+// Permission is an action a role may perform, scoped as resource:action[:scope]
+type Permission string
+
+const (
+	BlogCreate      Permission = "blog:create"
+	BlogUpdateOwn   Permission = "blog:update:own"
+	BlogUpdateAny   Permission = "blog:update:any"
+	BlogDeleteOwn   Permission = "blog:delete:own"
+	BlogDeleteAny   Permission = "blog:delete:any"
+	CommentCreate   Permission = "comment:create"
+	CommentModerate Permission = "comment:moderate"
+	ReactionCreate  Permission = "reaction:create"
+	ReactionManage  Permission = "reaction:manage"
+	TagManage       Permission = "tag:manage"
+	CategoryManage  Permission = "category:manage"
+	UserManage      Permission = "user:manage"
+)
+
+const (
+	RoleReader = "reader"
+	RoleAuthor = "author"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+// DefaultRole is given to new users and to users created before roles were enforced
+const DefaultRole = RoleAuthor
+
+var readerPermissions = []Permission{
+	CommentCreate,
+	ReactionCreate,
+}
+
+var authorPermissions = append([]Permission{
+	BlogCreate,
+	BlogUpdateOwn,
+	BlogDeleteOwn,
+}, readerPermissions...)
+
+var editorPermissions = append([]Permission{
+	BlogUpdateAny,
+	BlogDeleteAny,
+	CommentModerate,
+	TagManage,
+}, authorPermissions...)
+
+var adminPermissions = append([]Permission{
+	CategoryManage,
+	ReactionManage,
+	UserManage,
+}, editorPermissions...)
+
+var rolePermissions = map[string]map[Permission]bool{
+	RoleReader: toSet(readerPermissions),
+	RoleAuthor: toSet(authorPermissions),
+	RoleEditor: toSet(editorPermissions),
+	RoleAdmin:  toSet(adminPermissions),
+}
+
+// Has reports whether the role grants the permission
+func Has(role string, permission Permission) bool {
+	return rolePermissions[Normalize(role)][permission]
+}
+
+// HasScoped is Has for a request made with an API key, the permission must also be among the
+// scopes of the key. No scopes, as for a login or an unscoped key, leave the role as it is.
+func HasScoped(role string, scopes []string, permission Permission) bool {
+
+	if !Has(role, permission) {
+		return false
+	}
+	if len(scopes) == 0 {
+		return true
+	}
+
+	for _, scope := range scopes {
+		if Permission(scope) == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValid reports whether the permission is a known one, every permission is granted to admins
+func IsValid(permission Permission) bool {
+	return rolePermissions[RoleAdmin][permission]
+}
+
+// IsValidRole reports whether the role is one of the known roles
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Normalize maps the empty role of legacy users to the default role
+func Normalize(role string) string {
+	if role == "" {
+		return DefaultRole
+	}
+	return role
+}
+
+func toSet(permissions []Permission) map[Permission]bool {
+	set := make(map[Permission]bool, len(permissions))
+	for _, permission := range permissions {
+		set[permission] = true
+	}
+	return set
+}
@@ -0,0 +1,44 @@
+package poststatus
+
+const (
+	Draft     = "draft"
+	Scheduled = "scheduled"
+	Published = "published"
+	Archived  = "archived"
+)
+
+// transitions lists the statuses a post may move to from each status. Rescheduling is a
+// scheduled to scheduled move; a published post is taken down by archiving it.
+var transitions = map[string]map[string]bool{
+	Draft:     {Scheduled: true, Published: true, Archived: true},
+	Scheduled: {Draft: true, Scheduled: true, Published: true, Archived: true},
+	Published: {Archived: true},
+	Archived:  {Draft: true, Published: true},
+}
+
+// IsValid reports whether the status is one of the known statuses
+func IsValid(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+// CanTransition reports whether a post in status from may be moved to status to
+func CanTransition(from string, to string) bool {
+	return transitions[Normalize(from)][to]
+}
+
+// Normalize maps the empty status of posts created before statuses existed to draft
+func Normalize(status string) string {
+	if status == "" {
+		return Draft
+	}
+	return status
+}
+
+// FromIsPublished maps the legacy is_published flag to a status
+func FromIsPublished(isPublished bool) string {
+	if isPublished {
+		return Published
+	}
+	return Draft
+}
@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Policy describes how failed attempts are slowed down
+type Policy struct {
+	FreeAttempts int           // failures allowed before any delay applies
+	BaseDelay    time.Duration // delay after the first failure past FreeAttempts, doubled for every further failure
+	MaxDelay     time.Duration
+	Forget       time.Duration // failures older than this are forgotten
+}
+
+// Delay returns how long to wait after the given number of consecutive failures
+func (p Policy) Delay(failures int) time.Duration {
+
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := p.FreeAttempts + 1; i < failures; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+
+	return delay
+}
+
+type entry struct {
+	failures    int
+	lastFailure time.Time
+}
+
+// Backoff tracks consecutive failures per key in memory, such as per client IP
+type Backoff struct {
+	mu      sync.Mutex
+	policy  Policy
+	entries map[string]*entry
+}
+
+func NewBackoff(policy Policy) *Backoff {
+	return &Backoff{
+		policy:  policy,
+		entries: make(map[string]*entry),
+	}
+}
+
+// RetryAfter returns how long the key has to wait before its next attempt, zero when it may try now
+func (b *Backoff) RetryAfter(key string, now time.Time) time.Duration {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e, ok := b.entries[key]
+	if !ok {
+		return 0
+	}
+
+	wait := e.lastFailure.Add(b.policy.Delay(e.failures)).Sub(now)
+	if wait < 0 {
+		return 0
+	}
+
+	return wait
+}
+
+// Fail records a failed attempt of the key
+func (b *Backoff) Fail(key string, now time.Time) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e, ok := b.entries[key]
+	if !ok || now.Sub(e.lastFailure) > b.policy.Forget {
+		e = &entry{}
+		b.entries[key] = e
+	}
+
+	e.failures++
+	e.lastFailure = now
+}
+
+// Reset forgets the failures of the key after a successful attempt
+func (b *Backoff) Reset(key string) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.entries, key)
+}
+
+// Prune drops keys whose failures are old enough to be forgotten, it is meant to run periodically
+func (b *Backoff) Prune() error {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for key, e := range b.entries {
+		if now.Sub(e.lastFailure) > b.policy.Forget {
+			delete(b.entries, key)
+		}
+	}
+
+	return nil
+}
+
+// LoginPolicy configures failed login throttling per account and per client IP, and account lockout
+type LoginPolicy struct {
+	Account          Policy
+	IP               Policy
+	LockoutThreshold int // consecutive failures that lock the account
+	LockoutDuration  time.Duration
+}
@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/models"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Parent struct to implement interface binding
+type apiKeyRepo struct {
+	d *gorm.DB
+}
+
+// Interface binding
+func NewAPIKeyRepo(db *gorm.DB) domain.APIKeyRepository {
+	return &apiKeyRepo{
+		d: db,
+	}
+}
+
+// CreateAPIKey implements domain.APIKeyRepository.
+func (repo *apiKeyRepo) CreateAPIKey(key models.APIKey) error {
+	return repo.d.Create(&key).Error
+}
+
+// GetAPIKeysOfUser implements domain.APIKeyRepository.
+func (repo *apiKeyRepo) GetAPIKeysOfUser(userID string) ([]models.APIKey, error) {
+
+	var keys []models.APIKey
+
+	err := repo.d.Where("user_id = ?", userID).Order("created_at desc").Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// GetAPIKeyByHash implements domain.APIKeyRepository.
+func (repo *apiKeyRepo) GetAPIKeyByHash(keyHash string) (models.APIKey, error) {
+
+	var key models.APIKey
+
+	err := repo.d.Where("key_hash = ?", keyHash).First(&key).Error
+	if err != nil {
+		return key, err
+	}
+
+	return key, nil
+}
+
+// RevokeAPIKey implements domain.APIKeyRepository.
+func (repo *apiKeyRepo) RevokeAPIKey(userID string, keyID string) error {
+
+	result := repo.d.Model(&models.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", keyID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// TouchAPIKey implements domain.APIKeyRepository.
+func (repo *apiKeyRepo) TouchAPIKey(keyID string, usedAt time.Time) error {
+	return repo.d.Model(&models.APIKey{}).Where("id = ?", keyID).Update("last_used_at", usedAt).Error
+}
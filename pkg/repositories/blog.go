@@ -3,9 +3,15 @@ package repositories
 import (
 	"Blog_API/pkg/domain"
 	"Blog_API/pkg/models"
+	"Blog_API/pkg/poststatus"
+	"Blog_API/pkg/utils"
 	"Blog_API/pkg/utils/consts"
+	blogconsts "Blog_API/pkg/utils/consts/blog"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"time"
 )
 
 // Parent struct to implement interface binding
@@ -22,18 +28,26 @@ func NewBlogRepo(db *gorm.DB) domain.BlogRepository {
 
 // CreateBlogPost implements domain.BlogRepository.
 func (repo *blogRepo) CreateBlogPost(blogPost models.BlogPost) error {
-	err := repo.d.Preload(consts.REACTIONS).Preload(consts.COMMENTS).Create(&blogPost).Error
-	if err != nil {
-		return err
-	}
-	return nil
+
+	return repo.d.Transaction(func(tx *gorm.DB) error {
+
+		if err := tx.Omit(consts.TAGS).Create(&blogPost).Error; err != nil {
+			return err
+		}
+
+		if err := writeTags(tx, blogPost.ID, blogPost.Tags); err != nil {
+			return err
+		}
+
+		return saveRevision(tx, blogPost.ID, models.BlogRevision{AuthorID: blogPost.UserID, Action: blogconsts.RevisionCreate})
+	})
 }
 
 // GetBlogPost implements domain.BlogRepository.
 func (repo *blogRepo) GetBlogPost(blogID string) (models.BlogPost, error) {
 
 	var blogPost models.BlogPost
-	err := repo.d.Preload(consts.REACTIONS).Preload(consts.COMMENTS).Where("id = ?", blogID).First(&blogPost).Error
+	err := repo.d.Preload(consts.REACTIONS).Preload(consts.REACTIONTYPECOUNTS, reactionTypeCounts).Preload(consts.COMMENTS).Preload(consts.TAGS).Where("id = ?", blogID).First(&blogPost).Error
 	if err != nil {
 		return blogPost, err
 	}
@@ -41,41 +55,75 @@ func (repo *blogRepo) GetBlogPost(blogID string) (models.BlogPost, error) {
 	return blogPost, nil
 }
 
-// GetBlogPosts implements domain.BlogRepository.
-func (repo *blogRepo) GetBlogPosts() ([]models.BlogPost, error) {
+// GetBlogPostBySlug implements domain.BlogRepository.
+func (repo *blogRepo) GetBlogPostBySlug(slug string) (models.BlogPost, error) {
 
-	var blogPosts []models.BlogPost
-	err := repo.d.Preload(consts.REACTIONS).Preload(consts.COMMENTS).Find(&blogPosts).Error
+	var blogPost models.BlogPost
+	err := repo.d.Preload(consts.REACTIONS).Preload(consts.REACTIONTYPECOUNTS, reactionTypeCounts).Preload(consts.COMMENTS).Preload(consts.TAGS).Where("slug = ?", slug).First(&blogPost).Error
 	if err != nil {
-		return blogPosts, err
+		return blogPost, err
 	}
 
-	return blogPosts, nil
+	return blogPost, nil
 }
 
-// GetBlogPostsBasedOnCategory implements domain.BlogRepository.
-func (repo *blogRepo) GetBlogPostsBasedOnCategory(category string) ([]models.BlogPost, error) {
+// GetSlugHistory implements domain.BlogRepository.
+func (repo *blogRepo) GetSlugHistory(slug string) (models.BlogSlugHistory, error) {
 
-	var blogPosts []models.BlogPost
-	err := repo.d.Preload(consts.REACTIONS).Preload(consts.COMMENTS).Where("category = ?", category).Find(&blogPosts).Error
+	var history models.BlogSlugHistory
+	err := repo.d.Where("slug = ?", slug).First(&history).Error
 	if err != nil {
-		return blogPosts, err
+		return history, err
 	}
 
-	return blogPosts, nil
+	return history, nil
 }
 
-// GetBlogPosts implements domain.BlogRepository.
-func (repo *blogRepo) GetBlogPostsOfUser(userID string, blogIDs []string) ([]models.BlogPost, error) {
+// IsSlugTaken implements domain.BlogRepository.
+func (repo *blogRepo) IsSlugTaken(slug string, exceptBlogID string) (bool, error) {
 
-	var blogPosts []models.BlogPost
-	query := repo.d.Preload(consts.REACTIONS).Preload(consts.COMMENTS).Where("user_id = ?", userID)
+	var count int64
+
+	// Deleted posts keep their slug in the unique index, so they are counted too
+	err := repo.d.Unscoped().Model(&models.BlogPost{}).Where("slug = ? AND id <> ?", slug, exceptBlogID).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
 
-	if len(blogIDs) > 0 {
-		query = query.Where("id IN ?", blogIDs)
+	// A previous slug stays reserved for the post it redirects to
+	err = repo.d.Model(&models.BlogSlugHistory{}).Where("slug = ? AND blog_post_id <> ?", slug, exceptBlogID).Count(&count).Error
+	if err != nil {
+		return false, err
 	}
 
-	err := query.Find(&blogPosts).Error
+	return count > 0, nil
+}
+
+// RecordSlugChange implements domain.BlogRepository.
+func (repo *blogRepo) RecordSlugChange(blogID string, oldSlug string, newSlug string) error {
+
+	return repo.d.Transaction(func(tx *gorm.DB) error {
+
+		// Renaming back to an earlier title makes its slug current again
+		if err := tx.Where("slug = ? AND blog_post_id = ?", newSlug, blogID).Delete(&models.BlogSlugHistory{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.BlogSlugHistory{
+			Slug:       oldSlug,
+			BlogPostID: blogID,
+		}).Error
+	})
+}
+
+// GetBlogPostsWithoutSlug implements domain.BlogRepository.
+func (repo *blogRepo) GetBlogPostsWithoutSlug() ([]models.BlogPost, error) {
+
+	var blogPosts []models.BlogPost
+	err := repo.d.Unscoped().Select("id", "title").Where("slug IS NULL OR slug = ''").Order("created_at").Find(&blogPosts).Error
 	if err != nil {
 		return blogPosts, err
 	}
@@ -83,23 +131,187 @@ func (repo *blogRepo) GetBlogPostsOfUser(userID string, blogIDs []string) ([]mod
 	return blogPosts, nil
 }
 
+// SetBlogPostSlug implements domain.BlogRepository.
+func (repo *blogRepo) SetBlogPostSlug(blogID string, slug string) error {
+	return repo.d.Unscoped().Model(&models.BlogPost{}).Where("id = ?", blogID).UpdateColumn("slug", slug).Error
+}
+
 // UpdateBlogPost implements domain.BlogRepository.
-func (repo *blogRepo) UpdateBlogPost(blogPost models.BlogPost) error {
+// With withStatus the lifecycle fields of the post are written in the same transaction.
+func (repo *blogRepo) UpdateBlogPost(blogPost models.BlogPost, withStatus bool, revision models.BlogRevision) error {
+
+	return repo.d.Transaction(func(tx *gorm.DB) error {
+
+		if err := bumpVersion(tx, &models.BlogPost{}, blogPost.ID, blogPost.Version); err != nil {
+			return err
+		}
+
+		if err := saveBaselineRevision(tx, blogPost.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Omit("Version", consts.TAGS).Updates(&blogPost).Error; err != nil {
+			return err
+		}
 
-	err := repo.d.Preload(consts.REACTIONS).Preload(consts.COMMENTS).Updates(&blogPost).Error
+		if err := writeTags(tx, blogPost.ID, blogPost.Tags); err != nil {
+			return err
+		}
+
+		if withStatus {
+			if err := writeStatus(tx, blogPost); err != nil {
+				return err
+			}
+		}
+
+		return saveRevision(tx, blogPost.ID, revision)
+	})
+}
+
+// PatchBlogPost implements domain.BlogRepository.
+// With withStatus the lifecycle fields of the post are written in the same transaction.
+func (repo *blogRepo) PatchBlogPost(blogPost models.BlogPost, fields []string, withStatus bool, revision models.BlogRevision) error {
+
+	return repo.d.Transaction(func(tx *gorm.DB) error {
+
+		if err := bumpVersion(tx, &models.BlogPost{}, blogPost.ID, blogPost.Version); err != nil {
+			return err
+		}
+
+		if err := writeTags(tx, blogPost.ID, blogPost.Tags); err != nil {
+			return err
+		}
+
+		if withStatus {
+			if err := writeStatus(tx, blogPost); err != nil {
+				return err
+			}
+		}
+
+		// Revisions are of the content, a patch of only the tags does not make one
+		if len(fields) == 0 {
+			return nil
+		}
+
+		if err := saveBaselineRevision(tx, blogPost.ID); err != nil {
+			return err
+		}
+
+		// Selected fields are written even when empty, the others are not touched
+		if err := tx.Model(&models.BlogPost{ID: blogPost.ID}).Select(fields).Updates(&blogPost).Error; err != nil {
+			return err
+		}
+
+		return saveRevision(tx, blogPost.ID, revision)
+	})
+}
+
+// UpdateBlogPostStatus implements domain.BlogRepository.
+func (repo *blogRepo) UpdateBlogPostStatus(blogID string, version uint, status string, publishAt *time.Time, publishedAt *time.Time) error {
+
+	return repo.d.Transaction(func(tx *gorm.DB) error {
+
+		if err := bumpVersion(tx, &models.BlogPost{}, blogID, version); err != nil {
+			return err
+		}
+
+		return writeStatus(tx, models.BlogPost{ID: blogID, Status: status, PublishAt: publishAt, PublishedAt: publishedAt})
+	})
+}
+
+// writeStatus writes the lifecycle fields of the post, a map so that clearing publish_at and is_published is written too
+func writeStatus(tx *gorm.DB, blogPost models.BlogPost) error {
+	return tx.Model(&models.BlogPost{}).Where("id = ?", blogPost.ID).Updates(map[string]interface{}{
+		"status":       blogPost.Status,
+		"is_published": blogPost.Status == poststatus.Published,
+		"publish_at":   blogPost.PublishAt,
+		"published_at": blogPost.PublishedAt,
+	}).Error
+}
+
+// PublishDuePosts implements domain.BlogRepository.
+func (repo *blogRepo) PublishDuePosts(now time.Time) ([]string, error) {
+
+	var published []string
+	err := repo.d.Transaction(func(tx *gorm.DB) error {
+
+		var due []string
+		err := tx.Model(&models.BlogPost{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("status = ? AND publish_at <= ?", poststatus.Scheduled, now).
+			Pluck("id", &due).Error
+		if err != nil || len(due) == 0 {
+			return err
+		}
+
+		err = tx.Model(&models.BlogPost{}).
+			Where("id IN ?", due).
+			Updates(map[string]interface{}{
+				"status":       poststatus.Published,
+				"is_published": true,
+				"published_at": gorm.Expr("COALESCE(published_at, publish_at)"),
+				"publish_at":   nil,
+				"version":      gorm.Expr("version + 1"),
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		published = due
+		return nil
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return published, nil
 }
 
-// DeleteBlogPost implements domain.BlogRepository.
-func (repo *blogRepo) DeleteBlogPost(blogID string) error {
+// GetPublishedBlogPosts implements domain.BlogRepository.
+func (repo *blogRepo) GetPublishedBlogPosts(afterID string, limit int) ([]models.BlogPost, error) {
 
-	err := repo.d.Preload(consts.REACTIONS).Preload(consts.COMMENTS).Where("id = ?", blogID).Delete(&models.BlogPost{}).Error
+	var blogPosts []models.BlogPost
+	err := repo.d.Preload(consts.COMMENTS).
+		Where("status = ? AND id > ?", poststatus.Published, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&blogPosts).Error
 	if err != nil {
-		return err
+		return blogPosts, err
+	}
+
+	return blogPosts, nil
+}
+
+// BackfillPostStatuses implements domain.BlogRepository.
+func (repo *blogRepo) BackfillPostStatuses() error {
+
+	return repo.d.Transaction(func(tx *gorm.DB) error {
+
+		// Before statuses every post was a draft or published, and drafts got a publication time anyway
+		err := tx.Unscoped().Model(&models.BlogPost{}).
+			Where("(status IS NULL OR status = '') AND is_published = ?", true).
+			Update("status", poststatus.Published).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Model(&models.BlogPost{}).
+			Where("(status IS NULL OR status = '') AND is_published = ?", false).
+			Updates(map[string]interface{}{"status": poststatus.Draft, "published_at": nil}).Error
+	})
+}
+
+// DeleteBlogPost implements domain.BlogRepository.
+func (repo *blogRepo) DeleteBlogPost(blogID string, version uint) error {
+
+	result := repo.d.Preload(consts.REACTIONS).Preload(consts.COMMENTS).Where("id = ? AND version = ?", blogID, version).Delete(&models.BlogPost{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return utils.NewStatusError(http.StatusPreconditionFailed, consts.VersionMismatch)
 	}
 
 	return nil
@@ -108,30 +320,21 @@ func (repo *blogRepo) DeleteBlogPost(blogID string) error {
 // AddAndRemoveReaction implements domain.BlogRepository.
 func (repo *blogRepo) AddAndRemoveReaction(userID string, reactionID uint64, blogPost models.BlogPost) (models.BlogPost, error) {
 
-	tx, err := beginTransaction(repo.d)
-	if err != nil {
-		return models.BlogPost{}, err
-	}
+	err := repo.d.Transaction(func(tx *gorm.DB) error {
 
-	reaction, err := repo.findReaction(tx, userID, blogPost.ID)
-	if err != nil {
-		if createErr := repo.createReaction(tx, userID, reactionID, &blogPost); createErr != nil {
-			return models.BlogPost{}, createErr
+		reaction, err := repo.findReaction(tx, userID, blogPost.ID)
+		if err != nil {
+			return repo.createReaction(tx, userID, reactionID, &blogPost)
 		}
-	} else {
+
 		if reaction.Type == reactionID {
-			if removeErr := repo.removeReaction(tx, &reaction, &blogPost); removeErr != nil {
-				return models.BlogPost{}, removeErr
-			}
-		} else {
-			if updateErr := repo.updateReaction(tx, reaction, reactionID, &blogPost); updateErr != nil {
-				return models.BlogPost{}, updateErr
-			}
+			return repo.removeReaction(tx, &reaction, &blogPost)
 		}
-	}
 
-	if commitErr := tx.Commit().Error; commitErr != nil {
-		return models.BlogPost{}, commitErr
+		return repo.updateReaction(tx, reaction, reactionID, &blogPost)
+	})
+	if err != nil {
+		return models.BlogPost{}, err
 	}
 
 	return blogPost, nil
@@ -140,26 +343,20 @@ func (repo *blogRepo) AddAndRemoveReaction(userID string, reactionID uint64, blo
 // AddComment implements domain.BlogRepository.
 func (repo *blogRepo) AddComment(blogPost models.BlogPost, comment models.Comment) (models.BlogPost, error) {
 
-	tx, err := beginTransaction(repo.d)
-	if err != nil {
-		return models.BlogPost{}, err
-	}
+	err := repo.d.Transaction(func(tx *gorm.DB) error {
 
-	commentErr := tx.Create(&comment).Error
-	if commentErr != nil {
-		return models.BlogPost{}, commentErr
-	}
-
-	if appendErr := tx.Model(&blogPost).Association(consts.COMMENTS).Append(&comment); appendErr != nil {
-		return models.BlogPost{}, appendErr
-	}
+		if err := tx.Create(&comment).Error; err != nil {
+			return err
+		}
 
-	if updateCountErr := tx.Model(&blogPost).Update(consts.CommentCounts, blogPost.CommentsCount+1).Error; updateCountErr != nil {
-		return models.BlogPost{}, updateCountErr
-	}
+		if err := tx.Model(&blogPost).Association(consts.COMMENTS).Append(&comment); err != nil {
+			return err
+		}
 
-	if commitErr := tx.Commit().Error; commitErr != nil {
-		return models.BlogPost{}, commitErr
+		return adjustCounter(tx, &blogPost, consts.CommentCounts, 1)
+	})
+	if err != nil {
+		return models.BlogPost{}, err
 	}
 
 	return blogPost, nil
@@ -184,84 +381,60 @@ func (repo *blogRepo) GetComments(blogID string, commentIDs []string) ([]models.
 }
 
 // DeleteComment implements domain.BlogRepository.
-func (repo *blogRepo) DeleteComment(blogPost models.BlogPost, commentID string) error {
+func (repo *blogRepo) DeleteComment(blogPost models.BlogPost, commentID string, version uint) error {
 
-	tx, err := beginTransaction(repo.d)
-	if err != nil {
-		return err
-	}
+	return repo.d.Transaction(func(tx *gorm.DB) error {
 
-	var comment models.Comment
-	err = tx.Where("id = ? AND blog_post_id = ?", commentID, blogPost.ID).First(&comment).Error
-	if err != nil {
-		return err
-	}
-
-	err = tx.Delete(&comment).Error
-	if err != nil {
-		return err
-	}
+		var comment models.Comment
+		if err := tx.Where("id = ? AND blog_post_id = ?", commentID, blogPost.ID).First(&comment).Error; err != nil {
+			return err
+		}
 
-	err = tx.Model(&blogPost).Association(consts.COMMENTS).Delete(&comment)
-	if err != nil {
-		return err
-	}
+		if err := bumpVersion(tx, &models.Comment{}, comment.ID, version); err != nil {
+			return err
+		}
 
-	err = tx.Model(&blogPost).Update(consts.CommentCounts, blogPost.CommentsCount-1).Error
-	if err != nil {
-		return err
-	}
+		if err := tx.Delete(&comment).Error; err != nil {
+			return err
+		}
 
-	if commitErr := tx.Commit().Error; commitErr != nil {
-		return commitErr
-	}
+		if err := tx.Model(&blogPost).Association(consts.COMMENTS).Delete(&comment); err != nil {
+			return err
+		}
 
-	return nil
+		return adjustCounter(tx, &blogPost, consts.CommentCounts, -1)
+	})
 }
 
 // UpdateComment implements domain.BlogRepository.
 func (repo *blogRepo) UpdateComment(blogPost models.BlogPost, comment models.Comment) (models.BlogPost, error) {
 
-	tx, err := beginTransaction(repo.d)
-	if err != nil {
-		return models.BlogPost{}, err
-	}
+	err := repo.d.Transaction(func(tx *gorm.DB) error {
 
-	err = tx.Updates(&comment).Error
-	if err != nil {
-		return models.BlogPost{}, err
-	}
+		if err := bumpVersion(tx, &models.Comment{}, comment.ID, comment.Version); err != nil {
+			return err
+		}
 
-	updatedComment, err := repo.GetComments(blogPost.ID, []string{comment.ID})
+		if err := tx.Omit("Version").Updates(&comment).Error; err != nil {
+			return err
+		}
 
-	// Update the blog post with the updated comment
-	err = tx.Model(&blogPost).Association(consts.COMMENTS).Replace(&updatedComment)
+		// Read back in the transaction, the update is not visible outside of it yet
+		var updatedComment []models.Comment
+		if err := tx.Where("blog_post_id = ? AND id = ?", blogPost.ID, comment.ID).Find(&updatedComment).Error; err != nil {
+			return err
+		}
+
+		// Update the blog post with the updated comment
+		return tx.Model(&blogPost).Association(consts.COMMENTS).Replace(&updatedComment)
+	})
 	if err != nil {
 		return models.BlogPost{}, err
 	}
 
-	if commitErr := tx.Commit().Error; commitErr != nil {
-		return models.BlogPost{}, commitErr
-	}
-
 	return blogPost, nil
 }
 
-func beginTransaction(db *gorm.DB) (*gorm.DB, error) {
-	tx := db.Begin()
-	if tx.Error != nil {
-		return nil, tx.Error
-	}
-
-	defer func() {
-		if r := recover(); r != nil || tx.Error != nil {
-			tx.Rollback()
-		}
-	}()
-
-	return tx, nil
-}
-
 func (repo *blogRepo) findReaction(tx *gorm.DB, userID, blogPostID string) (models.Reaction, error) {
 
 	var reaction models.Reaction
@@ -290,7 +463,11 @@ func (repo *blogRepo) createReaction(tx *gorm.DB, userID string, reactionID uint
 		return err
 	}
 
-	if err := tx.Model(&blogPost).Update(consts.ReactionCounts, blogPost.ReactionsCount+1).Error; err != nil {
+	if err := adjustCounter(tx, blogPost, consts.ReactionCounts, 1); err != nil {
+		return err
+	}
+
+	if err := adjustReactionTypeCount(tx, blogPost, reactionID, 1); err != nil {
 		return err
 	}
 
@@ -307,7 +484,11 @@ func (repo *blogRepo) removeReaction(tx *gorm.DB, reaction *models.Reaction, blo
 		return err
 	}
 
-	if err := tx.Model(&blogPost).Update(consts.ReactionCounts, blogPost.ReactionsCount-1).Error; err != nil {
+	if err := adjustCounter(tx, blogPost, consts.ReactionCounts, -1); err != nil {
+		return err
+	}
+
+	if err := adjustReactionTypeCount(tx, blogPost, reaction.Type, -1); err != nil {
 		return err
 	}
 
@@ -316,10 +497,19 @@ func (repo *blogRepo) removeReaction(tx *gorm.DB, reaction *models.Reaction, blo
 
 func (repo *blogRepo) updateReaction(tx *gorm.DB, reaction models.Reaction, reactionID uint64, blogPost *models.BlogPost) error {
 
+	previousType := reaction.Type
 	if err := tx.Model(&reaction).Update("type", reactionID).Error; err != nil {
 		return err
 	}
 
+	// The reaction moves from one type to the other, the total stays
+	if err := adjustReactionTypeCount(tx, blogPost, previousType, -1); err != nil {
+		return err
+	}
+	if err := adjustReactionTypeCount(tx, blogPost, reactionID, 1); err != nil {
+		return err
+	}
+
 	if err := tx.Model(&blogPost).Association(consts.REACTIONS).Replace(&reaction); err != nil {
 		return err
 	}
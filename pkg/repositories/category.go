@@ -0,0 +1,178 @@
+package repositories
+
+import (
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/poststatus"
+	"Blog_API/pkg/utils"
+	blogconsts "Blog_API/pkg/utils/consts/blog"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+// GetCategories implements domain.BlogRepository.
+func (repo *blogRepo) GetCategories() ([]models.Category, error) {
+
+	var categories []models.Category
+	if err := repo.d.Order("name").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// ListCategories implements domain.BlogRepository.
+func (repo *blogRepo) ListCategories() ([]models.Category, error) {
+
+	var categories []models.Category
+	err := repo.d.Model(&models.Category{}).
+		Select("categories.*, COUNT(blog_posts.id) AS posts_count").
+		Joins("LEFT JOIN blog_posts ON blog_posts.category_id = categories.id AND blog_posts.status = ? AND blog_posts.deleted_at IS NULL", poststatus.Published).
+		Group("categories.id").
+		Order("categories.name").
+		Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// GetCategory implements domain.BlogRepository.
+func (repo *blogRepo) GetCategory(idOrSlug string) (models.Category, error) {
+
+	var category models.Category
+	err := repo.d.Where("id = ? OR slug = ?", idOrSlug, idOrSlug).First(&category).Error
+	if err != nil {
+		return category, err
+	}
+
+	return category, nil
+}
+
+// CreateCategory implements domain.BlogRepository.
+func (repo *blogRepo) CreateCategory(category models.Category) error {
+	return repo.d.Create(&category).Error
+}
+
+// UpdateCategory implements domain.BlogRepository.
+func (repo *blogRepo) UpdateCategory(category models.Category) ([]string, error) {
+
+	var blogIDs []string
+	err := repo.d.Transaction(func(tx *gorm.DB) error {
+
+		var current models.Category
+		if err := tx.Where("id = ?", category.ID).First(&current).Error; err != nil {
+			return err
+		}
+
+		// Selected so that an empty description and a move to the top level are written too
+		if err := tx.Model(&models.Category{ID: category.ID}).Select("Name", "Slug", "Description", "ParentID").Updates(&category).Error; err != nil {
+			return err
+		}
+
+		if current.Slug == category.Slug {
+			return nil
+		}
+
+		var err error
+		blogIDs, err = refileBlogPosts(tx, category.ID, category)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return blogIDs, nil
+}
+
+// DeleteCategory implements domain.BlogRepository.
+func (repo *blogRepo) DeleteCategory(categoryID string, moveTo *models.Category) ([]string, error) {
+
+	var blogIDs []string
+	err := repo.d.Transaction(func(tx *gorm.DB) error {
+
+		var category models.Category
+		if err := tx.Where("id = ?", categoryID).First(&category).Error; err != nil {
+			return err
+		}
+
+		// Deleted posts are counted too, they would be left pointing at nothing
+		var count int64
+		if err := tx.Unscoped().Model(&models.BlogPost{}).Where("category_id = ?", categoryID).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count > 0 {
+			if moveTo == nil {
+				return utils.NewStatusError(http.StatusConflict, blogconsts.CategoryInUse)
+			}
+			var err error
+			if blogIDs, err = refileBlogPosts(tx, categoryID, *moveTo); err != nil {
+				return err
+			}
+		}
+
+		// The subcategories take the place of the deleted one
+		if err := tx.Model(&models.Category{}).Where("parent_id = ?", categoryID).Update("parent_id", category.ParentID).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&category).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return blogIDs, nil
+}
+
+// GetUnfiledCategoryNames implements domain.BlogRepository.
+// The names come most used first, so the most common spelling names the category they end up in.
+func (repo *blogRepo) GetUnfiledCategoryNames() ([]string, error) {
+
+	var names []string
+	err := repo.d.Unscoped().Model(&models.BlogPost{}).
+		Where("category_id IS NULL").
+		Group("COALESCE(category, '')").
+		Order("COUNT(*) DESC").
+		Pluck("COALESCE(category, '')", &names).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// FileBlogPosts implements domain.BlogRepository.
+func (repo *blogRepo) FileBlogPosts(category string, into models.Category) error {
+	return repo.d.Unscoped().Model(&models.BlogPost{}).
+		Where("category_id IS NULL AND COALESCE(category, '') = ?", category).
+		Updates(map[string]interface{}{"category_id": into.ID, "category": into.Slug}).Error
+}
+
+// refileBlogPosts moves the posts of a category, deleted ones included, under another category or
+// the new slug of the same one. The posts read differently, so they move to their next version.
+func refileBlogPosts(tx *gorm.DB, fromID string, into models.Category) ([]string, error) {
+
+	var blogIDs []string
+	if err := tx.Unscoped().Model(&models.BlogPost{}).Where("category_id = ?", fromID).Pluck("id", &blogIDs).Error; err != nil {
+		return nil, err
+	}
+
+	if len(blogIDs) == 0 {
+		return blogIDs, nil
+	}
+
+	err := tx.Unscoped().Model(&models.BlogPost{}).Where("id IN ?", blogIDs).UpdateColumns(map[string]interface{}{
+		"category_id": into.ID,
+		"category":    into.Slug,
+		"version":     gorm.Expr("version + 1"),
+		"updated_at":  time.Now(),
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return blogIDs, nil
+}
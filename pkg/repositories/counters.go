@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/utils/consts"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// counterSources are the child tables each counter of a post is the number of live rows of
+var counterSources = []struct {
+	column string
+	table  string
+}{
+	{column: consts.CommentCounts, table: "comments"},
+	{column: consts.ReactionCounts, table: "reactions"},
+}
+
+// ReconcileCounters implements domain.BlogRepository.
+func (repo *blogRepo) ReconcileCounters(fix bool) ([]models.CounterDiscrepancy, error) {
+
+	var found []models.CounterDiscrepancy
+	err := repo.d.Transaction(func(tx *gorm.DB) error {
+
+		for _, source := range counterSources {
+			var discrepancies []models.CounterDiscrepancy
+			err := tx.Raw("SELECT blog_posts.id AS blog_post_id, ? AS counter, blog_posts."+source.column+" AS stored, COUNT("+source.table+".id) AS actual"+
+				" FROM blog_posts LEFT JOIN "+source.table+" ON "+source.table+".blog_post_id = blog_posts.id AND "+source.table+".deleted_at IS NULL"+
+				" GROUP BY blog_posts.id, blog_posts."+source.column+
+				" HAVING stored <> actual ORDER BY blog_posts.id", source.column).
+				Scan(&discrepancies).Error
+			if err != nil {
+				return err
+			}
+			if len(discrepancies) == 0 {
+				continue
+			}
+			found = append(found, discrepancies...)
+
+			if !fix {
+				continue
+			}
+
+			blogIDs := make([]string, len(discrepancies))
+			for i, discrepancy := range discrepancies {
+				blogIDs[i] = discrepancy.BlogPostID
+			}
+
+			// Recounted in the statement itself, a write since the SELECT is not undone
+			recount := gorm.Expr("(SELECT COUNT(*) FROM " + source.table + " WHERE " + source.table + ".blog_post_id = blog_posts.id AND " + source.table + ".deleted_at IS NULL)")
+			err = tx.Unscoped().Model(&models.BlogPost{}).Where("id IN ?", blogIDs).
+				Updates(map[string]interface{}{source.column: recount}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		discrepancies, err := reconcileReactionTypeCounts(tx, fix)
+		if err != nil {
+			return err
+		}
+		found = append(found, discrepancies...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
+
+// reconcileReactionTypeCounts recounts the reactions of every post per type. A type can be
+// missing on either side, so both are put together before they are compared.
+func reconcileReactionTypeCounts(tx *gorm.DB, fix bool) ([]models.CounterDiscrepancy, error) {
+
+	var discrepancies []models.CounterDiscrepancy
+	err := tx.Raw("SELECT blog_post_id, ? AS counter, type, SUM(stored) AS stored, SUM(actual) AS actual FROM ("+
+		"SELECT blog_post_id, type, count AS stored, 0 AS actual FROM blog_post_reaction_counts"+
+		" UNION ALL SELECT blog_post_id, type, 0, COUNT(*) FROM reactions WHERE deleted_at IS NULL GROUP BY blog_post_id, type"+
+		") AS counted GROUP BY blog_post_id, type HAVING stored <> actual ORDER BY blog_post_id, type", consts.ReactionTypeCount).
+		Scan(&discrepancies).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if !fix || len(discrepancies) == 0 {
+		return discrepancies, nil
+	}
+
+	counts := make([]models.BlogPostReactionCount, len(discrepancies))
+	for i, discrepancy := range discrepancies {
+		counts[i] = models.BlogPostReactionCount{BlogPostID: discrepancy.BlogPostID, Type: discrepancy.Type, Count: discrepancy.Actual}
+	}
+
+	// A reaction written since the recount is caught up by the next run
+	err = tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "blog_post_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"count"}),
+	}).Create(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return discrepancies, nil
+}
+
+// adjustCounter moves a counter of the post by delta in the database, where concurrent writers
+// can not overwrite each other, and reads the result back into the post. A counter never goes
+// below zero, the column is unsigned.
+func adjustCounter(tx *gorm.DB, blogPost *models.BlogPost, column string, delta int) error {
+
+	expr := gorm.Expr(column+" + ?", delta)
+	if delta < 0 {
+		expr = gorm.Expr("GREATEST("+column+", ?) - ?", -delta, -delta)
+	}
+
+	// updated_at moves with it, a comment or reaction going away changes the post as it is read
+	if err := tx.Model(&models.BlogPost{}).Where("id = ?", blogPost.ID).Updates(map[string]interface{}{column: expr}).Error; err != nil {
+		return err
+	}
+
+	var counters models.BlogPost
+	if err := tx.Model(&models.BlogPost{}).Select("comments_count", "reactions_count", "updated_at").Where("id = ?", blogPost.ID).Take(&counters).Error; err != nil {
+		return err
+	}
+	blogPost.CommentsCount, blogPost.ReactionsCount, blogPost.UpdatedAt = counters.CommentsCount, counters.ReactionsCount, counters.UpdatedAt
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/poststatus"
+	"database/sql"
+	"gorm.io/gorm"
+	"time"
+)
+
+// GetBlogPostLastModified implements domain.BlogRepository.
+func (repo *blogRepo) GetBlogPostLastModified(blogID string) (uint, string, time.Time, error) {
+
+	var blogPost models.BlogPost
+	if err := repo.d.Select("id", "version", "status", "updated_at").Where("id = ?", blogID).First(&blogPost).Error; err != nil {
+		return 0, "", time.Time{}, err
+	}
+
+	lastModified, err := repo.childrenLastModified(repo.d.Model(&models.BlogPost{}).Select("id").Where("id = ?", blogID))
+	if err != nil {
+		return 0, "", time.Time{}, err
+	}
+
+	return blogPost.Version, blogPost.Status, latest(blogPost.UpdatedAt, lastModified), nil
+}
+
+// GetBlogPostsLastModified implements domain.BlogRepository.
+func (repo *blogRepo) GetBlogPostsLastModified(categoryIDs []string) (int64, time.Time, error) {
+
+	published := func() *gorm.DB {
+		query := repo.d.Model(&models.BlogPost{}).Where("status = ?", poststatus.Published)
+		if len(categoryIDs) > 0 {
+			query = query.Where("category_id IN ?", categoryIDs)
+		}
+		return query
+	}
+
+	var count int64
+	var postsLastModified sql.NullTime
+	if err := published().Select("COUNT(*), MAX(updated_at)").Row().Scan(&count, &postsLastModified); err != nil {
+		return 0, time.Time{}, err
+	}
+
+	lastModified, err := repo.childrenLastModified(published().Select("id"))
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	return count, latest(postsLastModified.Time, lastModified), nil
+}
+
+// childrenLastModified is the latest change to the comments and reactions of the posts, removing
+// one also updates the counter of its post so deletions show up in the post itself
+func (repo *blogRepo) childrenLastModified(blogIDs *gorm.DB) (time.Time, error) {
+
+	var comments, reactions sql.NullTime
+	if err := repo.d.Model(&models.Comment{}).Where("blog_post_id IN (?)", blogIDs).Select("MAX(updated_at)").Row().Scan(&comments); err != nil {
+		return time.Time{}, err
+	}
+
+	if err := repo.d.Model(&models.Reaction{}).Where("blog_post_id IN (?)", blogIDs).Select("MAX(updated_at)").Row().Scan(&reactions); err != nil {
+		return time.Time{}, err
+	}
+
+	return latest(comments.Time, reactions.Time), nil
+}
+
+func latest(times ...time.Time) time.Time {
+
+	var last time.Time
+	for _, t := range times {
+		if t.After(last) {
+			last = t
+		}
+	}
+
+	return last
+}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"Blog_API/pkg/listing"
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/utils/consts"
+	"fmt"
+	"gorm.io/gorm"
+)
+
+// ListBlogPosts implements domain.BlogRepository.
+// It returns up to query.Limit+1 posts, the extra one tells the caller another page follows, and
+// the number of posts matching the filters regardless of the page. Of the associations only the
+// tags are loaded.
+func (repo *blogRepo) ListBlogPosts(query listing.Query) ([]models.BlogPost, int64, error) {
+
+	var total int64
+	if err := filterBlogPosts(repo.d.Model(&models.BlogPost{}), query).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	expression := listing.Expression(query.Sort)
+	direction, comparison := "DESC", "<"
+	if query.Order == listing.Ascending {
+		direction, comparison = "ASC", ">"
+	}
+
+	page := filterBlogPosts(repo.d.Model(&models.BlogPost{}), query)
+
+	// Keyset paging: resume after the sort value and id of the last post of the previous page
+	if query.After != nil {
+		value := query.After.Value()
+		page = page.Where(fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", expression, comparison, expression, comparison), value, value, query.After.ID)
+	} else if query.Offset > 0 {
+		page = page.Offset(query.Offset)
+	}
+
+	var blogPosts []models.BlogPost
+	err := page.Preload(consts.TAGS).Preload(consts.REACTIONTYPECOUNTS, reactionTypeCounts).Order(fmt.Sprintf("%s %s, id %s", expression, direction, direction)).Limit(query.Limit + 1).Find(&blogPosts).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return blogPosts, total, nil
+}
+
+func filterBlogPosts(db *gorm.DB, query listing.Query) *gorm.DB {
+
+	if len(query.CategoryIDs) > 0 {
+		db = db.Where("category_id IN ?", query.CategoryIDs)
+	}
+
+	if query.AuthorID != "" {
+		db = db.Where("user_id = ?", query.AuthorID)
+	}
+
+	if len(query.Statuses) > 0 {
+		db = db.Where("status IN ?", query.Statuses)
+	}
+
+	if len(query.BlogIDs) > 0 {
+		db = db.Where("id IN ?", query.BlogIDs)
+	}
+
+	if len(query.Tags) > 0 {
+		tagged := db.Session(&gorm.Session{NewDB: true}).
+			Table("blog_post_tags").
+			Select("blog_post_tags.blog_post_id").
+			Joins("JOIN tags ON tags.id = blog_post_tags.tag_id").
+			Where("tags.name IN ?", query.Tags)
+		// A post is tagged once per tag, so having every tag means one row per tag
+		if query.TagMatch == listing.MatchAll {
+			tagged = tagged.Group("blog_post_tags.blog_post_id").Having("COUNT(*) = ?", len(query.Tags))
+		}
+		db = db.Where("id IN (?)", tagged)
+	}
+
+	dateExpression := listing.Expression(listing.SortPublishedAt)
+	if query.From != nil {
+		db = db.Where(dateExpression+" >= ?", *query.From)
+	}
+
+	if query.Until != nil {
+		db = db.Where(dateExpression+" < ?", *query.Until)
+	}
+
+	return db
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/models"
+	tokenconsts "Blog_API/pkg/utils/consts/token"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Parent struct to implement interface binding
+type mfaRepo struct {
+	d *gorm.DB
+}
+
+// Interface binding
+func NewMFARepo(db *gorm.DB) domain.MFARepository {
+	return &mfaRepo{
+		d: db,
+	}
+}
+
+// SetTOTPSecret implements domain.MFARepository.
+func (repo *mfaRepo) SetTOTPSecret(userID string, secret string) error {
+
+	// A pending secret is never written over an enabled one
+	result := repo.d.Model(&models.User{}).
+		Where("id = ? AND totp_enabled = ?", userID, false).
+		Update("totp_secret", secret)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// EnableTOTP implements domain.MFARepository.
+func (repo *mfaRepo) EnableTOTP(userID string, step int64, codes []models.RecoveryCode) error {
+
+	return repo.d.Transaction(func(tx *gorm.DB) error {
+
+		err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"totp_enabled":        true,
+			"totp_last_used_step": step,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+// DisableTOTP implements domain.MFARepository.
+func (repo *mfaRepo) DisableTOTP(userID string) error {
+
+	return repo.d.Transaction(func(tx *gorm.DB) error {
+
+		err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"totp_enabled":        false,
+			"totp_secret":         "",
+			"totp_last_used_step": 0,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+// UseTOTPStep implements domain.MFARepository.
+func (repo *mfaRepo) UseTOTPStep(userID string, step int64) error {
+
+	// Only a newer time step is accepted, so a code can not be replayed within its validity window
+	result := repo.d.Model(&models.User{}).
+		Where("id = ? AND totp_last_used_step < ?", userID, step).
+		Update("totp_last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New(tokenconsts.TokenAlreadyUsed)
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes implements domain.MFARepository.
+func (repo *mfaRepo) ReplaceRecoveryCodes(userID string, codes []models.RecoveryCode) error {
+
+	return repo.d.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+// UseRecoveryCode implements domain.MFARepository.
+func (repo *mfaRepo) UseRecoveryCode(userID string, codeHash string) error {
+
+	result := repo.d.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New(tokenconsts.TokenAlreadyUsed)
+	}
+
+	return nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID string, codes []models.RecoveryCode) error {
+
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	if len(codes) == 0 {
+		return nil
+	}
+
+	return tx.Create(&codes).Error
+}
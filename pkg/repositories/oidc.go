@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/models"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Parent struct to implement interface binding
+type oidcRepo struct {
+	d *gorm.DB
+}
+
+// Interface binding
+func NewOIDCRepo(db *gorm.DB) domain.OIDCRepository {
+	return &oidcRepo{
+		d: db,
+	}
+}
+
+// CreateAuthState implements domain.OIDCRepository.
+func (repo *oidcRepo) CreateAuthState(state models.OIDCAuthState) error {
+	return repo.d.Create(&state).Error
+}
+
+// ConsumeAuthState implements domain.OIDCRepository.
+func (repo *oidcRepo) ConsumeAuthState(stateHash string) (models.OIDCAuthState, error) {
+
+	var state models.OIDCAuthState
+
+	err := repo.d.Transaction(func(tx *gorm.DB) error {
+
+		if err := tx.Where("state_hash = ?", stateHash).First(&state).Error; err != nil {
+			return err
+		}
+
+		// Whoever deletes the row owns the state, a replayed callback finds nothing
+		result := tx.Where("state_hash = ?", stateHash).Delete(&models.OIDCAuthState{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return models.OIDCAuthState{}, err
+	}
+
+	return state, nil
+}
+
+// PurgeExpiredAuthStates implements domain.OIDCRepository.
+func (repo *oidcRepo) PurgeExpiredAuthStates() error {
+	return repo.d.Where("expires_at < ?", time.Now()).Delete(&models.OIDCAuthState{}).Error
+}
+
+// GetIdentity implements domain.OIDCRepository.
+func (repo *oidcRepo) GetIdentity(provider string, subject string) (models.UserIdentity, error) {
+
+	var identity models.UserIdentity
+
+	err := repo.d.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		return identity, err
+	}
+
+	return identity, nil
+}
+
+// CreateIdentity implements domain.OIDCRepository.
+func (repo *oidcRepo) CreateIdentity(identity models.UserIdentity) error {
+	return repo.d.Create(&identity).Error
+}
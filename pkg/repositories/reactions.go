@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	"Blog_API/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetReactionTypes implements domain.BlogRepository.
+func (repo *blogRepo) GetReactionTypes(includeRetired bool) ([]models.ReactionType, error) {
+
+	query := repo.d.Order("position, id")
+	if !includeRetired {
+		query = query.Where("enabled = ?", true)
+	}
+
+	var reactionTypes []models.ReactionType
+	if err := query.Find(&reactionTypes).Error; err != nil {
+		return nil, err
+	}
+
+	return reactionTypes, nil
+}
+
+// GetReactionType implements domain.BlogRepository.
+func (repo *blogRepo) GetReactionType(id uint64) (models.ReactionType, error) {
+
+	var reactionType models.ReactionType
+	err := repo.d.Where("id = ?", id).First(&reactionType).Error
+	if err != nil {
+		return reactionType, err
+	}
+
+	return reactionType, nil
+}
+
+// GetReactionTypeByCode implements domain.BlogRepository.
+func (repo *blogRepo) GetReactionTypeByCode(code string) (models.ReactionType, error) {
+
+	var reactionType models.ReactionType
+	err := repo.d.Where("code = ?", code).First(&reactionType).Error
+	if err != nil {
+		return reactionType, err
+	}
+
+	return reactionType, nil
+}
+
+// CreateReactionType implements domain.BlogRepository.
+func (repo *blogRepo) CreateReactionType(reactionType models.ReactionType) (models.ReactionType, error) {
+
+	if err := repo.d.Create(&reactionType).Error; err != nil {
+		return reactionType, err
+	}
+
+	return reactionType, nil
+}
+
+// UpdateReactionType implements domain.BlogRepository.
+func (repo *blogRepo) UpdateReactionType(reactionType models.ReactionType) error {
+	return repo.d.Model(&reactionType).Select("Label", "Emoji", "Position", "Enabled").Updates(reactionType).Error
+}
+
+// SeedReactionTypes implements domain.BlogRepository.
+func (repo *blogRepo) SeedReactionTypes(reactionTypes []models.ReactionType) (int64, error) {
+
+	var seeded int64
+	err := repo.d.Transaction(func(tx *gorm.DB) error {
+
+		// Only an empty catalog is seeded, types retired by an admin are not brought back
+		var count int64
+		if err := tx.Model(&models.ReactionType{}).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		result := tx.Create(&reactionTypes)
+		seeded = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return seeded, nil
+}
+
+// ListReactors implements domain.BlogRepository.
+func (repo *blogRepo) ListReactors(blogID string, reactionType uint64, offset int, limit int) ([]models.Reactor, int64, error) {
+
+	reactions := func() *gorm.DB {
+		return repo.d.Model(&models.Reaction{}).Where("reactions.blog_post_id = ? AND reactions.type = ?", blogID, reactionType)
+	}
+
+	var total int64
+	if err := reactions().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// A user that is gone still shows as reacted, only without a name
+	var reactors []models.Reactor
+	err := reactions().
+		Select("reactions.user_id, users.first_name, users.last_name, reactions.type, reaction_types.code, reactions.created_at").
+		Joins("LEFT JOIN users ON users.id = reactions.user_id AND users.deleted_at IS NULL").
+		Joins("LEFT JOIN reaction_types ON reaction_types.id = reactions.type").
+		Order("reactions.created_at DESC, reactions.id").
+		Offset(offset).Limit(limit).
+		Scan(&reactors).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return reactors, total, nil
+}
+
+// BackfillReactionTypeCounts implements domain.BlogRepository.
+func (repo *blogRepo) BackfillReactionTypeCounts() (int64, error) {
+
+	// Posts with a count of their own are kept up to date by the writes, only the others are counted
+	result := repo.d.Exec("INSERT INTO blog_post_reaction_counts (blog_post_id, type, count)" +
+		" SELECT blog_post_id, type, COUNT(*) FROM reactions" +
+		" WHERE deleted_at IS NULL AND blog_post_id NOT IN (SELECT blog_post_id FROM blog_post_reaction_counts)" +
+		" GROUP BY blog_post_id, type")
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// adjustReactionTypeCount moves the count of one reaction type of the post by delta, like
+// adjustCounter does for the total, and reads the counts of the post back into it
+func adjustReactionTypeCount(tx *gorm.DB, blogPost *models.BlogPost, reactionType uint64, delta int) error {
+
+	var err error
+	if delta > 0 {
+		err = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "blog_post_id"}, {Name: "type"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("count + VALUES(count)")}),
+		}).Create(&models.BlogPostReactionCount{BlogPostID: blogPost.ID, Type: reactionType, Count: uint(delta)}).Error
+	} else {
+		err = tx.Model(&models.BlogPostReactionCount{}).Where("blog_post_id = ? AND type = ?", blogPost.ID, reactionType).
+			UpdateColumn("count", gorm.Expr("GREATEST(count, ?) - ?", -delta, -delta)).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	return reactionTypeCounts(tx.Where("blog_post_reaction_counts.blog_post_id = ?", blogPost.ID)).Find(&blogPost.ReactionTypeCounts).Error
+}
+
+// reactionTypeCounts reads the counts of a post that are in use, along with the code of their type
+func reactionTypeCounts(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.BlogPostReactionCount{}).
+		Select("blog_post_reaction_counts.*, reaction_types.code").
+		Joins("LEFT JOIN reaction_types ON reaction_types.id = blog_post_reaction_counts.type").
+		Where("blog_post_reaction_counts.count > 0").
+		Order("blog_post_reaction_counts.type")
+}
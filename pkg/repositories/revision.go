@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"Blog_API/pkg/models"
+	blogconsts "Blog_API/pkg/utils/consts/blog"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RestoreBlogRevision implements domain.BlogRepository.
+func (repo *blogRepo) RestoreBlogRevision(blogPost models.BlogPost, revision models.BlogRevision) error {
+
+	// Every content field is written, a revision with an empty description has to clear it
+	return repo.PatchBlogPost(blogPost, []string{"Title", "Slug", "ContentText", "PhotoURL", "Description", "CategoryID", "Category"}, false, revision)
+}
+
+// GetBlogRevisions implements domain.BlogRepository.
+func (repo *blogRepo) GetBlogRevisions(blogID string) ([]models.BlogRevision, error) {
+
+	var revisions []models.BlogRevision
+	err := repo.d.Where("blog_post_id = ?", blogID).Order("number DESC").Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// GetBlogRevision implements domain.BlogRepository.
+func (repo *blogRepo) GetBlogRevision(blogID string, number uint) (models.BlogRevision, error) {
+
+	var revision models.BlogRevision
+	err := repo.d.Where("blog_post_id = ? AND number = ?", blogID, number).First(&revision).Error
+	if err != nil {
+		return revision, err
+	}
+
+	return revision, nil
+}
+
+// saveRevision snapshots the post as it is in the transaction and stores it as the next revision
+func saveRevision(tx *gorm.DB, blogID string, revision models.BlogRevision) error {
+
+	// Locking the post serializes concurrent writers on the revision number
+	var blogPost models.BlogPost
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", blogID).First(&blogPost).Error; err != nil {
+		return err
+	}
+
+	var last uint
+	if err := tx.Model(&models.BlogRevision{}).Where("blog_post_id = ?", blogID).Select("COALESCE(MAX(number), 0)").Scan(&last).Error; err != nil {
+		return err
+	}
+
+	revision.ID = uuid.New().String()
+	revision.BlogPostID = blogPost.ID
+	revision.Number = last + 1
+	revision.Title = blogPost.Title
+	revision.Slug = blogPost.Slug
+	revision.ContentText = blogPost.ContentText
+	revision.PhotoURL = blogPost.PhotoURL
+	revision.Description = blogPost.Description
+	revision.Category = blogPost.Category
+
+	return tx.Create(&revision).Error
+}
+
+// saveBaselineRevision keeps the text of posts written before revisions existed, so their first edit can be undone
+func saveBaselineRevision(tx *gorm.DB, blogID string) error {
+
+	var count int64
+	if err := tx.Model(&models.BlogRevision{}).Where("blog_post_id = ?", blogID).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	var blogPost models.BlogPost
+	if err := tx.Select("id", "user_id").Where("id = ?", blogID).First(&blogPost).Error; err != nil {
+		return err
+	}
+
+	return saveRevision(tx, blogID, models.BlogRevision{AuthorID: blogPost.UserID, Action: blogconsts.RevisionBaseline})
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// Parent struct to implement interface binding
+type revocationRepo struct {
+	d *gorm.DB
+}
+
+// Interface binding
+func NewRevocationRepo(db *gorm.DB) domain.RevocationStore {
+	return &revocationRepo{
+		d: db,
+	}
+}
+
+// Revoke implements domain.RevocationStore.
+func (repo *revocationRepo) Revoke(jti string, userID string, expiresAt time.Time) error {
+
+	revokedToken := models.RevokedToken{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}
+
+	// Revoking an already revoked token is not an error
+	err := repo.d.Clauses(clause.OnConflict{DoNothing: true}).Create(&revokedToken).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// IsRevoked implements domain.RevocationStore.
+func (repo *revocationRepo) IsRevoked(jti string) (bool, error) {
+
+	var count int64
+
+	err := repo.d.Model(&models.RevokedToken{}).Where("jti = ? AND expires_at > ?", jti, time.Now()).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// PurgeExpired implements domain.RevocationStore.
+func (repo *revocationRepo) PurgeExpired() error {
+
+	err := repo.d.Where("expires_at <= ?", time.Now()).Delete(&models.RevokedToken{}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
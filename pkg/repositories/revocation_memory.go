@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"Blog_API/pkg/domain"
+	"sync"
+	"time"
+)
+
+// memoryRevocationStore keeps revoked tokens in process memory, revocations are lost on restart
+type memoryRevocationStore struct {
+	mu     sync.RWMutex
+	tokens map[string]time.Time
+}
+
+// Interface binding
+func NewMemoryRevocationStore() domain.RevocationStore {
+	return &memoryRevocationStore{
+		tokens: make(map[string]time.Time),
+	}
+}
+
+// Revoke implements domain.RevocationStore.
+func (store *memoryRevocationStore) Revoke(jti string, userID string, expiresAt time.Time) error {
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.tokens[jti] = expiresAt
+
+	return nil
+}
+
+// IsRevoked implements domain.RevocationStore.
+func (store *memoryRevocationStore) IsRevoked(jti string) (bool, error) {
+
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	expiresAt, ok := store.tokens[jti]
+	if !ok {
+		return false, nil
+	}
+
+	return expiresAt.After(time.Now()), nil
+}
+
+// PurgeExpired implements domain.RevocationStore.
+func (store *memoryRevocationStore) PurgeExpired() error {
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := time.Now()
+	for jti, expiresAt := range store.tokens {
+		if !expiresAt.After(now) {
+			delete(store.tokens, jti)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package repositories
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/poststatus"
+	"Blog_API/pkg/search"
+	"fmt"
+	"gorm.io/gorm"
+	"strings"
+)
+
+// fullTextIndexes are the FULLTEXT indexes the MySQL search needs: one over the text of a post to
+// find it, one per field to weigh the match by field, and one over the comments
+var fullTextIndexes = []struct {
+	table   string
+	name    string
+	columns string
+}{
+	{"blog_posts", "ft_blog_posts_search", "title, description, content_text"},
+	{"blog_posts", "ft_blog_posts_title", "title"},
+	{"blog_posts", "ft_blog_posts_description", "description"},
+	{"blog_posts", "ft_blog_posts_content", "content_text"},
+	{"comments", "ft_comments_content", "content"},
+}
+
+// Parent struct to implement interface binding
+type fullTextSearchRepo struct {
+	d *gorm.DB
+}
+
+// Interface binding
+// NewFullTextSearchRepo searches with the FULLTEXT indexes of MySQL. The database keeps them
+// current, so Index and Remove have nothing to do. All the words of a query have to be found
+// in the post itself or in one of its comments, and words shorter than innodb_ft_min_token_size
+// or on the stopword list of the server are not indexed at all.
+func NewFullTextSearchRepo(db *gorm.DB) domain.SearchIndex {
+	return &fullTextSearchRepo{
+		d: db,
+	}
+}
+
+// CreateFullTextIndexes adds the FULLTEXT indexes of the MySQL search that do not exist yet
+func CreateFullTextIndexes(db *gorm.DB) error {
+
+	for _, index := range fullTextIndexes {
+		if db.Migrator().HasIndex(index.table, index.name) {
+			continue
+		}
+		if err := db.Exec(fmt.Sprintf("CREATE FULLTEXT INDEX %s ON %s (%s)", index.name, index.table, index.columns)).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Index implements domain.SearchIndex.
+func (repo *fullTextSearchRepo) Index(doc search.Document) error {
+	return nil
+}
+
+// Remove implements domain.SearchIndex.
+func (repo *fullTextSearchRepo) Remove(blogID string) error {
+	return nil
+}
+
+type fullTextHit struct {
+	ID          string
+	Slug        string
+	Title       string
+	Description string
+	ContentText string
+	Score       float64
+}
+
+// Search implements domain.SearchIndex.
+func (repo *fullTextSearchRepo) Search(query search.Query, offset int, limit int) ([]search.Hit, int, error) {
+
+	required := booleanQuery(query, "+")
+	scoring := booleanQuery(query, "")
+
+	matched := func() *gorm.DB {
+		comments := repo.d.Model(&models.Comment{}).Select("blog_post_id").Where("MATCH(content) AGAINST(? IN BOOLEAN MODE)", required)
+		return repo.d.Model(&models.BlogPost{}).
+			Where("status = ?", poststatus.Published).
+			Where("(MATCH(title, description, content_text) AGAINST(? IN BOOLEAN MODE) OR id IN (?))", required, comments)
+	}
+
+	var total int64
+	if err := matched().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	score := fmt.Sprintf("%g * MATCH(title) AGAINST(@q IN BOOLEAN MODE)"+
+		" + %g * MATCH(description) AGAINST(@q IN BOOLEAN MODE)"+
+		" + %g * MATCH(content_text) AGAINST(@q IN BOOLEAN MODE)"+
+		" + %g * COALESCE((SELECT MAX(MATCH(comments.content) AGAINST(@q IN BOOLEAN MODE)) FROM comments"+
+		" WHERE comments.blog_post_id = blog_posts.id AND comments.deleted_at IS NULL), 0)",
+		search.Boosts[search.FieldTitle], search.Boosts[search.FieldDescription], search.Boosts[search.FieldContent], search.Boosts[search.FieldComments])
+
+	var rows []fullTextHit
+	err := matched().
+		Select("id, slug, title, description, content_text, "+score+" AS score", map[string]interface{}{"q": scoring}).
+		Order("score DESC, id").
+		Offset(offset).
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	comments, err := repo.matchingComments(rows, scoring)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hits := make([]search.Hit, 0, len(rows))
+	for _, row := range rows {
+		doc := search.Document{
+			ID:          row.ID,
+			Slug:        row.Slug,
+			Title:       row.Title,
+			Description: row.Description,
+			Content:     row.ContentText,
+			Comments:    comments[row.ID],
+		}
+		hits = append(hits, search.Hit{
+			ID:         row.ID,
+			Slug:       row.Slug,
+			Title:      row.Title,
+			Score:      row.Score,
+			Highlights: search.Highlight(doc, query),
+		})
+	}
+
+	return hits, int(total), nil
+}
+
+// matchingComments returns the comments of the hits that match the query, for their snippets
+func (repo *fullTextSearchRepo) matchingComments(rows []fullTextHit, scoring string) (map[string][]string, error) {
+
+	comments := make(map[string][]string)
+	if len(rows) == 0 {
+		return comments, nil
+	}
+
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+
+	var matches []models.Comment
+	err := repo.d.Select("blog_post_id", "content").
+		Where("blog_post_id IN ? AND MATCH(content) AGAINST(? IN BOOLEAN MODE)", ids, scoring).
+		Order("created_at").
+		Find(&matches).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, comment := range matches {
+		comments[comment.BlogPostID] = append(comments[comment.BlogPostID], comment.Content)
+	}
+
+	return comments, nil
+}
+
+// booleanQuery writes a query in the syntax of MATCH ... IN BOOLEAN MODE, with operator in front
+// of every term and phrase. The terms come from the tokenizer and hold no operators themselves.
+func booleanQuery(query search.Query, operator string) string {
+
+	parts := make([]string, 0, len(query.Terms)+len(query.Phrases))
+	for _, term := range query.Terms {
+		parts = append(parts, operator+term)
+	}
+	for _, phrase := range query.Phrases {
+		parts = append(parts, operator+`"`+strings.Join(phrase, " ")+`"`)
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,187 @@
+package repositories
+
+import (
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/poststatus"
+	"Blog_API/pkg/utils"
+	blogconsts "Blog_API/pkg/utils/consts/blog"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"time"
+)
+
+// ListTags implements domain.BlogRepository.
+func (repo *blogRepo) ListTags(prefix string, offset int, limit int) ([]models.Tag, int64, error) {
+
+	var total int64
+	if err := repo.d.Table("(?) AS used_tags", usedTags(repo.d, prefix)).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var tags []models.Tag
+	err := usedTags(repo.d, prefix).Order("posts_count DESC, tags.name").Offset(offset).Limit(limit).Find(&tags).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tags, total, nil
+}
+
+// GetTag implements domain.BlogRepository.
+func (repo *blogRepo) GetTag(name string) (models.Tag, error) {
+
+	var tag models.Tag
+	err := tagsWithCounts(repo.d).Where("tags.name = ?", name).First(&tag).Error
+	if err != nil {
+		return tag, err
+	}
+
+	return tag, nil
+}
+
+// MergeTags implements domain.BlogRepository.
+func (repo *blogRepo) MergeTags(names []string, into string) ([]string, []string, error) {
+
+	var mergedNames, blogIDs []string
+	err := repo.d.Transaction(func(tx *gorm.DB) error {
+
+		var merged []models.Tag
+		if err := tx.Where("name IN ? AND name <> ?", names, into).Find(&merged).Error; err != nil {
+			return err
+		}
+		if len(merged) == 0 {
+			return utils.NewStatusError(http.StatusNotFound, blogconsts.TagNotFound)
+		}
+
+		mergedIDs := make([]string, len(merged))
+		for i, tag := range merged {
+			mergedIDs[i] = tag.ID
+			mergedNames = append(mergedNames, tag.Name)
+		}
+
+		targets, err := resolveTags(tx, []string{into})
+		if err != nil {
+			return err
+		}
+		target := targets[0]
+
+		err = tx.Model(&models.BlogPostTag{}).Distinct("blog_post_id").Where("tag_id IN ?", mergedIDs).Pluck("blog_post_id", &blogIDs).Error
+		if err != nil {
+			return err
+		}
+
+		// Posts that already carry the target keep a single row of it
+		err = tx.Exec("INSERT IGNORE INTO blog_post_tags (blog_post_id, tag_id, created_at) SELECT DISTINCT blog_post_id, ?, ? FROM blog_post_tags WHERE tag_id IN ?",
+			target.ID, time.Now(), mergedIDs).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("tag_id IN ?", mergedIDs).Delete(&models.BlogPostTag{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("id IN ?", mergedIDs).Delete(&models.Tag{}).Error; err != nil {
+			return err
+		}
+
+		if len(blogIDs) == 0 {
+			return nil
+		}
+
+		// The posts read differently now, so their versions and Last-Modified move on
+		return tx.Unscoped().Model(&models.BlogPost{}).Where("id IN ?", blogIDs).UpdateColumns(map[string]interface{}{
+			"version":    gorm.Expr("version + 1"),
+			"updated_at": time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return mergedNames, blogIDs, nil
+}
+
+// writeTags makes the tags of a post exactly the given ones, creating the tags that do not exist
+// yet. Nil leaves the tags of the post alone, an empty slice removes them all.
+func writeTags(tx *gorm.DB, blogID string, tags []models.Tag) error {
+
+	if tags == nil {
+		return nil
+	}
+
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+
+	stored, err := resolveTags(tx, names)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Where("blog_post_id = ?", blogID).Delete(&models.BlogPostTag{}).Error; err != nil {
+		return err
+	}
+
+	if len(stored) > 0 {
+		links := make([]models.BlogPostTag, len(stored))
+		for i, tag := range stored {
+			links[i] = models.BlogPostTag{BlogPostID: blogID, TagID: tag.ID}
+		}
+		if err := tx.Create(&links).Error; err != nil {
+			return err
+		}
+	}
+
+	// Tags are part of the post, a change to them alone still moves its Last-Modified
+	return tx.Model(&models.BlogPost{}).Where("id = ?", blogID).UpdateColumn("updated_at", time.Now()).Error
+}
+
+// resolveTags returns the tags of the given normalized names, creating the missing ones. Two
+// writers creating the same tag at once both end up with the row that won.
+func resolveTags(tx *gorm.DB, names []string) ([]models.Tag, error) {
+
+	if len(names) == 0 {
+		return []models.Tag{}, nil
+	}
+
+	created := make([]models.Tag, len(names))
+	for i, name := range names {
+		created[i] = models.Tag{ID: uuid.NewString(), Name: name}
+	}
+
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&created).Error; err != nil {
+		return nil, err
+	}
+
+	var tags []models.Tag
+	if err := tx.Where("name IN ?", names).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// tagsWithCounts selects the tags along with the number of published posts carrying them
+func tagsWithCounts(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.Tag{}).
+		Select("tags.id, tags.name, tags.created_at, COUNT(blog_posts.id) AS posts_count").
+		Joins("LEFT JOIN blog_post_tags ON blog_post_tags.tag_id = tags.id").
+		Joins("LEFT JOIN blog_posts ON blog_posts.id = blog_post_tags.blog_post_id AND blog_posts.status = ? AND blog_posts.deleted_at IS NULL", poststatus.Published).
+		Group("tags.id, tags.name, tags.created_at")
+}
+
+// usedTags are the tags of at least one published post, so tags only drafts carry stay private.
+// The prefix is a normalized name and holds no LIKE wildcards.
+func usedTags(db *gorm.DB, prefix string) *gorm.DB {
+
+	query := tagsWithCounts(db).Having("COUNT(blog_posts.id) > 0")
+	if prefix != "" {
+		query = query.Where("tags.name LIKE ?", prefix+"%")
+	}
+
+	return query
+}
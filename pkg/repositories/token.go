@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/models"
+	tokenconsts "Blog_API/pkg/utils/consts/token"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Parent struct to implement interface binding
+type tokenRepo struct {
+	d *gorm.DB
+}
+
+// Interface binding
+func NewTokenRepo(db *gorm.DB) domain.TokenRepository {
+	return &tokenRepo{
+		d: db,
+	}
+}
+
+// CreateRefreshToken implements domain.TokenRepository.
+func (repo *tokenRepo) CreateRefreshToken(token models.RefreshToken) error {
+
+	err := repo.d.Create(&token).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetRefreshTokenByHash implements domain.TokenRepository.
+func (repo *tokenRepo) GetRefreshTokenByHash(tokenHash string) (models.RefreshToken, error) {
+
+	var token models.RefreshToken
+
+	err := repo.d.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		return token, err
+	}
+
+	return token, nil
+}
+
+// RotateRefreshToken implements domain.TokenRepository.
+func (repo *tokenRepo) RotateRefreshToken(oldTokenID string, newToken models.RefreshToken) error {
+
+	return repo.d.Transaction(func(tx *gorm.DB) error {
+
+		// Only the first rotation of a token may succeed, a concurrent second use counts as reuse
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND replaced_by_id = '' AND revoked_at IS NULL", oldTokenID).
+			Updates(map[string]interface{}{
+				"replaced_by_id": newToken.ID,
+				"revoked_at":     time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return errors.New(tokenconsts.RefreshTokenReused)
+		}
+
+		if err := tx.Create(&newToken).Error; err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// GetRefreshTokensOfFamily implements domain.TokenRepository.
+func (repo *tokenRepo) GetRefreshTokensOfFamily(familyID string) ([]models.RefreshToken, error) {
+
+	var tokens []models.RefreshToken
+
+	err := repo.d.Where("family_id = ?", familyID).Order("created_at").Find(&tokens).Error
+	if err != nil {
+		return tokens, err
+	}
+
+	return tokens, nil
+}
+
+// GetActiveRefreshTokensOfUser implements domain.TokenRepository.
+func (repo *tokenRepo) GetActiveRefreshTokensOfUser(userID string) ([]models.RefreshToken, error) {
+
+	var tokens []models.RefreshToken
+
+	err := repo.d.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	if err != nil {
+		return tokens, err
+	}
+
+	return tokens, nil
+}
+
+// RevokeFamily implements domain.TokenRepository.
+func (repo *tokenRepo) RevokeFamily(familyID string) error {
+
+	err := repo.d.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	userconsts "Blog_API/pkg/utils/consts/user"
 	"errors"
 	"gorm.io/gorm"
+	"time"
 )
 
 // Parent struct to implement interface binding
@@ -21,22 +22,6 @@ func NewUserRepo(db *gorm.DB) domain.Repository {
 	}
 }
 
-// Login implements domain.UserRepository.
-func (repo *userRepo) Login(email string, password string) (string, error) {
-
-	var existingUser models.User
-
-	if err := repo.d.Where("email = ?", email).First(&existingUser).Error; err != nil {
-		return "", err
-	}
-
-	if err := utils.ComparePassword(existingUser.Password, password); err != nil {
-		return "", err
-	}
-
-	return existingUser.ID, nil
-}
-
 // CreateUser implements domain.UserRepository.
 func (repo *userRepo) CreateUser(user models.User) error {
 
@@ -85,6 +70,19 @@ func (repo *userRepo) GetUser(userID string) (models.User, error) {
 	return user, nil
 }
 
+// GetUserByEmail implements domain.UserRepository.
+func (repo *userRepo) GetUserByEmail(email string) (models.User, error) {
+
+	var user models.User
+
+	err := repo.d.Where("email = ?", email).First(&user).Error
+	if err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
 // GetUsers implements domain.UserRepository.
 func (repo *userRepo) GetUsers(pagination utils.Page) ([]models.User, error) {
 
@@ -111,7 +109,121 @@ func (repo *userRepo) GetUsers(pagination utils.Page) ([]models.User, error) {
 // UpdateUser implements domain.UserRepository.
 func (repo *userRepo) UpdateUser(user models.User) error {
 
-	err := repo.d.Updates(&user).Error
+	return repo.d.Transaction(func(tx *gorm.DB) error {
+
+		if err := bumpVersion(tx, &models.User{}, user.ID, user.Version); err != nil {
+			return err
+		}
+
+		return tx.Omit("Version").Updates(&user).Error
+	})
+}
+
+// PatchUser implements domain.UserRepository.
+func (repo *userRepo) PatchUser(user models.User, fields []string) error {
+
+	return repo.d.Transaction(func(tx *gorm.DB) error {
+
+		if err := bumpVersion(tx, &models.User{}, user.ID, user.Version); err != nil {
+			return err
+		}
+
+		// Selected fields are written even when empty, the others are not touched
+		return tx.Model(&models.User{ID: user.ID}).Select(fields).Updates(&user).Error
+	})
+}
+
+// UpdateUserRole implements domain.UserRepository.
+func (repo *userRepo) UpdateUserRole(userID string, role string) error {
+
+	err := repo.d.Model(&models.User{}).Where("id = ?", userID).Update("role", role).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdatePassword implements domain.UserRepository.
+func (repo *userRepo) UpdatePassword(userID string, hashedPassword string) error {
+
+	err := repo.d.Model(&models.User{}).Where("id = ?", userID).Update("password", hashedPassword).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarkEmailVerified implements domain.UserRepository.
+func (repo *userRepo) MarkEmailVerified(userID string) error {
+
+	err := repo.d.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"email_verified":    true,
+		"email_verified_at": time.Now(),
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RecordFailedLogin implements domain.UserRepository.
+func (repo *userRepo) RecordFailedLogin(userID string) (int, error) {
+
+	var user models.User
+
+	err := repo.d.Transaction(func(tx *gorm.DB) error {
+
+		// Incremented in SQL so concurrent failures are all counted
+		err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"failed_login_count":   gorm.Expr("failed_login_count + ?", 1),
+			"last_failed_login_at": time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Select("failed_login_count").Where("id = ?", userID).First(&user).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return user.FailedLoginCount, nil
+}
+
+// LockUser implements domain.UserRepository.
+func (repo *userRepo) LockUser(userID string, until time.Time) error {
+
+	err := repo.d.Model(&models.User{}).Where("id = ?", userID).Update("locked_until", until).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ResetFailedLogins implements domain.UserRepository.
+func (repo *userRepo) ResetFailedLogins(userID string) error {
+
+	err := repo.d.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"failed_login_count":   0,
+		"last_failed_login_at": nil,
+		"locked_until":         nil,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateLoginAttempt implements domain.UserRepository.
+func (repo *userRepo) CreateLoginAttempt(attempt models.LoginAttempt) error {
+
+	err := repo.d.Create(&attempt).Error
 	if err != nil {
 		return err
 	}
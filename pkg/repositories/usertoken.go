@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/models"
+	tokenconsts "Blog_API/pkg/utils/consts/token"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Parent struct to implement interface binding
+type userTokenRepo struct {
+	d *gorm.DB
+}
+
+// Interface binding
+func NewUserTokenRepo(db *gorm.DB) domain.UserTokenRepository {
+	return &userTokenRepo{
+		d: db,
+	}
+}
+
+// CreateUserToken implements domain.UserTokenRepository.
+func (repo *userTokenRepo) CreateUserToken(token models.UserToken) error {
+
+	err := repo.d.Create(&token).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetUserTokenByHash implements domain.UserTokenRepository.
+func (repo *userTokenRepo) GetUserTokenByHash(purpose string, tokenHash string) (models.UserToken, error) {
+
+	var token models.UserToken
+
+	err := repo.d.Where("purpose = ? AND token_hash = ?", purpose, tokenHash).First(&token).Error
+	if err != nil {
+		return token, err
+	}
+
+	return token, nil
+}
+
+// ConsumeUserToken implements domain.UserTokenRepository.
+func (repo *userTokenRepo) ConsumeUserToken(tokenID string) error {
+
+	// The used_at guard makes concurrent redemptions of the same token fail except for one
+	result := repo.d.Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", tokenID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New(tokenconsts.TokenAlreadyUsed)
+	}
+
+	return nil
+}
+
+// InvalidateUserTokens implements domain.UserTokenRepository.
+func (repo *userTokenRepo) InvalidateUserTokens(userID string, purpose string) error {
+
+	err := repo.d.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetLatestUserToken implements domain.UserTokenRepository.
+func (repo *userTokenRepo) GetLatestUserToken(userID string, purpose string) (models.UserToken, error) {
+
+	var token models.UserToken
+
+	err := repo.d.Where("user_id = ? AND purpose = ?", userID, purpose).Order("created_at DESC").First(&token).Error
+	if err != nil {
+		return token, err
+	}
+
+	return token, nil
+}
+
+// CountUserTokensSince implements domain.UserTokenRepository.
+func (repo *userTokenRepo) CountUserTokensSince(userID string, purpose string, since time.Time) (int64, error) {
+
+	var count int64
+
+	err := repo.d.Model(&models.UserToken{}).Where("user_id = ? AND purpose = ? AND created_at > ?", userID, purpose, since).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
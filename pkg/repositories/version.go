@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"Blog_API/pkg/utils"
+	"Blog_API/pkg/utils/consts"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// bumpVersion moves a row to its next version, it fails with 412 when the row is no longer at the
+// version the caller read, and holds the row lock for the rest of the transaction. A new version is
+// an edit, so updated_at moves with it even when the rest of the write only touches other tables,
+// like the tags of a post.
+func bumpVersion(tx *gorm.DB, model interface{}, id string, version uint) error {
+
+	result := tx.Model(model).Where("id = ? AND version = ?", id, version).Updates(map[string]interface{}{"version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return utils.NewStatusError(http.StatusPreconditionFailed, consts.VersionMismatch)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"Blog_API/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
+)
+
+// AddBlogPostViews implements domain.BlogRepository.
+func (repo *blogRepo) AddBlogPostViews(views []models.BlogPostDailyView) error {
+
+	if len(views) == 0 {
+		return nil
+	}
+
+	totals := make(map[string]uint)
+	for _, view := range views {
+		totals[view.BlogPostID] += view.Views
+	}
+
+	// Rows are locked in the same order by every flush, so two instances flushing at once do not deadlock
+	blogIDs := make([]string, 0, len(totals))
+	for blogID := range totals {
+		blogIDs = append(blogIDs, blogID)
+	}
+	sort.Strings(blogIDs)
+
+	return repo.d.Transaction(func(tx *gorm.DB) error {
+
+		// Views are not an edit, updated_at and the version stay so cached copies remain valid
+		for _, blogID := range blogIDs {
+			err := tx.Unscoped().Model(&models.BlogPost{}).Where("id = ?", blogID).
+				UpdateColumn("views", gorm.Expr("views + ?", totals[blogID])).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "blog_post_id"}, {Name: "day"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"views": gorm.Expr("views + VALUES(views)")}),
+		}).Create(&views).Error
+	})
+}
+
+// GetBlogPostDailyViews implements domain.BlogRepository.
+func (repo *blogRepo) GetBlogPostDailyViews(blogID string, from string, to string) ([]models.BlogPostDailyView, error) {
+
+	var views []models.BlogPostDailyView
+	err := repo.d.Where("blog_post_id = ? AND day BETWEEN ? AND ?", blogID, from, to).Order("day").Find(&views).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return views, nil
+}
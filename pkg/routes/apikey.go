@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+type apiKeyRoutes struct {
+	echo             *echo.Echo
+	apiKeyController domain.APIKeyController
+}
+
+func NewAPIKeyRoutes(e *echo.Echo, controller domain.APIKeyController) *apiKeyRoutes {
+	return &apiKeyRoutes{
+		echo:             e,
+		apiKeyController: controller,
+	}
+}
+
+func (a *apiKeyRoutes) InitAPIKeyRoutes() {
+	e := a.echo
+	a.initAPIKeyRoutes(e)
+}
+
+func (a *apiKeyRoutes) initAPIKeyRoutes(e *echo.Echo) {
+
+	// group the routes
+	common := e.Group("blog_api")
+	version := common.Group("/v1")
+
+	// api key management needs a login, a leaked key can not mint more keys
+	apiKeys := version.Group("/user/api-keys", middlewares.PrivateCache, middlewares.Auth)
+	apiKeys.POST("", a.apiKeyController.CreateAPIKey)
+	apiKeys.GET("", a.apiKeyController.GetAPIKeys)
+	apiKeys.DELETE("", a.apiKeyController.RevokeAPIKey)
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"Blog_API/pkg/domain"
 	"Blog_API/pkg/middlewares"
+	"Blog_API/pkg/permissions"
 	"github.com/labstack/echo/v4"
 )
 
@@ -31,20 +32,52 @@ func (b *blogRoutes) initBlogRoutes(e *echo.Echo) {
 
 	blog := version.Group("/blog")
 
+	// reads anyone can see may be kept by shared caches, the others only by the client
+	public := blog.Group("", middlewares.PublicCache)
+	private := blog.Group("", middlewares.PrivateCache)
+
 	// blog routes
-	blog.POST("/create", b.blogController.CreateBlogPost, middlewares.Auth)
-	blog.GET("/get", b.blogController.GetBlogPost)
-	blog.GET("/getAll", b.blogController.GetBlogPosts)
-	blog.GET("/get/category", b.blogController.GetBlogPostsBasedOnCategory)
-	blog.GET("/get/user", b.blogController.GetBlogPostsOfUser, middlewares.Auth)
-	blog.PUT("/update", b.blogController.UpdateBlogPost, middlewares.Auth)
-	blog.DELETE("/delete", b.blogController.DeleteBlogPost, middlewares.Auth)
+	blog.POST("/create", b.blogController.CreateBlogPost, middlewares.AuthOrAPIKey, middlewares.RequirePermission(permissions.BlogCreate))
+	public.GET("/get", b.blogController.GetBlogPost, middlewares.OptionalAuth)
+	public.GET("/post/:slug", b.blogController.GetBlogPostBySlug, middlewares.OptionalAuth)
+	public.GET("/getAll", b.blogController.GetBlogPosts)
+	public.GET("/get/category", b.blogController.GetBlogPostsBasedOnCategory)
+	public.GET("/get/tag", b.blogController.GetBlogPostsByTags)
+	public.GET("/search", b.blogController.SearchBlogPosts)
+	private.GET("/get/user", b.blogController.GetBlogPostsOfUser, middlewares.AuthOrAPIKey)
+	blog.PUT("/update", b.blogController.UpdateBlogPost, middlewares.AuthOrAPIKey, middlewares.RequirePermission(permissions.BlogUpdateOwn))
+	blog.PATCH("/update", b.blogController.PatchBlogPost, middlewares.AuthOrAPIKey, middlewares.RequirePermission(permissions.BlogUpdateOwn))
+	blog.PUT("/status", b.blogController.ChangeBlogPostStatus, middlewares.AuthOrAPIKey, middlewares.RequirePermission(permissions.BlogUpdateOwn))
+	private.GET("/revisions", b.blogController.GetBlogRevisions, middlewares.AuthOrAPIKey)
+	private.GET("/revision", b.blogController.GetBlogRevision, middlewares.AuthOrAPIKey)
+	private.GET("/revisions/diff", b.blogController.DiffBlogRevisions, middlewares.AuthOrAPIKey)
+	private.GET("/views", b.blogController.GetBlogPostViews, middlewares.AuthOrAPIKey)
+	blog.POST("/revisions/restore", b.blogController.RestoreBlogRevision, middlewares.AuthOrAPIKey, middlewares.RequirePermission(permissions.BlogUpdateOwn))
+	blog.DELETE("/delete", b.blogController.DeleteBlogPost, middlewares.AuthOrAPIKey, middlewares.RequirePermission(permissions.BlogDeleteOwn))
+
+	// tag routes
+	public.GET("/tags", b.blogController.GetTags)
+	public.GET("/tags/suggest", b.blogController.SuggestTags)
+	blog.POST("/tags/merge", b.blogController.MergeTags, middlewares.AuthOrAPIKey, middlewares.RequirePermission(permissions.TagManage))
+
+	// category routes
+	public.GET("/categories", b.blogController.GetCategories)
+	blog.POST("/categories", b.blogController.CreateCategory, middlewares.AuthOrAPIKey, middlewares.RequirePermission(permissions.CategoryManage))
+	blog.PUT("/categories", b.blogController.UpdateCategory, middlewares.AuthOrAPIKey, middlewares.RequirePermission(permissions.CategoryManage))
+	blog.DELETE("/categories", b.blogController.DeleteCategory, middlewares.AuthOrAPIKey, middlewares.RequirePermission(permissions.CategoryManage))
 
 	// like and comment routes
-	blog.POST("/reaction", b.blogController.AddAndRemoveReaction, middlewares.Auth)
-	blog.POST("/comment", b.blogController.AddComment, middlewares.Auth)
-	blog.GET("/comment", b.blogController.GetComments, middlewares.Auth)
-	blog.DELETE("/comment", b.blogController.DeleteComment, middlewares.Auth)
-	blog.PUT("/comment", b.blogController.UpdateComment, middlewares.Auth)
+	blog.POST("/reaction", b.blogController.AddAndRemoveReaction, middlewares.AuthOrAPIKey, middlewares.RequirePermission(permissions.ReactionCreate))
+	public.GET("/reactions", b.blogController.GetReactors, middlewares.OptionalAuth)
+	blog.POST("/comment", b.blogController.AddComment, middlewares.AuthOrAPIKey, middlewares.RequirePermission(permissions.CommentCreate))
+	private.GET("/comment", b.blogController.GetComments, middlewares.AuthOrAPIKey)
+	blog.DELETE("/comment", b.blogController.DeleteComment, middlewares.AuthOrAPIKey, middlewares.RequirePermission(permissions.CommentCreate))
+	blog.PUT("/comment", b.blogController.UpdateComment, middlewares.AuthOrAPIKey, middlewares.RequirePermission(permissions.CommentCreate))
+
+	// reaction catalog routes
+	public.GET("/reaction-types", b.blogController.GetReactionTypes)
+	blog.POST("/reaction-types", b.blogController.CreateReactionType, middlewares.AuthOrAPIKey, middlewares.RequirePermission(permissions.ReactionManage))
+	blog.PUT("/reaction-types", b.blogController.UpdateReactionType, middlewares.AuthOrAPIKey, middlewares.RequirePermission(permissions.ReactionManage))
+	blog.DELETE("/reaction-types", b.blogController.RetireReactionType, middlewares.AuthOrAPIKey, middlewares.RequirePermission(permissions.ReactionManage))
 
 }
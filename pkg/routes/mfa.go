@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+type mfaRoutes struct {
+	echo          *echo.Echo
+	mfaController domain.MFAController
+}
+
+func NewMFARoutes(e *echo.Echo, controller domain.MFAController) *mfaRoutes {
+	return &mfaRoutes{
+		echo:          e,
+		mfaController: controller,
+	}
+}
+
+func (m *mfaRoutes) InitMFARoutes() {
+	e := m.echo
+	m.initMFARoutes(e)
+}
+
+func (m *mfaRoutes) initMFARoutes(e *echo.Echo) {
+
+	// group the routes
+	common := e.Group("blog_api")
+	version := common.Group("/v1")
+
+	user := version.Group("/user")
+
+	// second login step, authenticated by the mfa token in the body
+	user.POST("/login/2fa", m.mfaController.LoginWithMFA)
+
+	// two-factor management routes
+	twoFactor := user.Group("/2fa", middlewares.Auth)
+	twoFactor.POST("/enroll", m.mfaController.Enroll)
+	twoFactor.POST("/confirm", m.mfaController.ConfirmEnrollment)
+	twoFactor.POST("/disable", m.mfaController.Disable)
+	twoFactor.POST("/recovery-codes", m.mfaController.RegenerateRecoveryCodes)
+}
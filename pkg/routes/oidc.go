@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"Blog_API/pkg/domain"
+	"github.com/labstack/echo/v4"
+)
+
+type oidcRoutes struct {
+	echo           *echo.Echo
+	oidcController domain.OIDCController
+}
+
+func NewOIDCRoutes(e *echo.Echo, controller domain.OIDCController) *oidcRoutes {
+	return &oidcRoutes{
+		echo:           e,
+		oidcController: controller,
+	}
+}
+
+func (o *oidcRoutes) InitOIDCRoutes() {
+	e := o.echo
+	o.initOIDCRoutes(e)
+}
+
+func (o *oidcRoutes) initOIDCRoutes(e *echo.Echo) {
+
+	// group the routes
+	common := e.Group("blog_api")
+	version := common.Group("/v1")
+
+	social := version.Group("/user/oidc")
+
+	// social login routes
+	social.GET("/providers", o.oidcController.GetProviders)
+	social.GET("/:provider/login", o.oidcController.BeginLogin)
+	social.GET("/:provider/callback", o.oidcController.Callback)
+}
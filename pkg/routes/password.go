@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"Blog_API/pkg/domain"
+	"github.com/labstack/echo/v4"
+)
+
+type passwordRoutes struct {
+	echo               *echo.Echo
+	passwordController domain.PasswordController
+}
+
+func NewPasswordRoutes(e *echo.Echo, controller domain.PasswordController) *passwordRoutes {
+	return &passwordRoutes{
+		echo:               e,
+		passwordController: controller,
+	}
+}
+
+func (p *passwordRoutes) InitPasswordRoutes() {
+	e := p.echo
+	p.initPasswordRoutes(e)
+}
+
+func (p *passwordRoutes) initPasswordRoutes(e *echo.Echo) {
+
+	// group the routes
+	common := e.Group("blog_api")
+	version := common.Group("/v1")
+
+	password := version.Group("/user/password")
+
+	// password recovery routes
+	password.POST("/forgot", p.passwordController.ForgotPassword)
+	password.POST("/reset", p.passwordController.ResetPassword)
+}
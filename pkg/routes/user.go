@@ -3,6 +3,7 @@ package routes
 import (
 	"Blog_API/pkg/domain"
 	"Blog_API/pkg/middlewares"
+	"Blog_API/pkg/permissions"
 	"github.com/labstack/echo/v4"
 )
 
@@ -29,15 +30,25 @@ func (u *UserRoutes) initUserRoutes(e *echo.Echo) {
 	common := e.Group("blog_api")
 	version := common.Group("/v1")
 
-	user := version.Group("/user")
+	user := version.Group("/user", middlewares.PrivateCache)
 
 	// Login route
 	user.POST("/login", u.userController.Login)
 	user.POST("/logout", u.userController.Logout, middlewares.Auth)
+	user.POST("/token/refresh", u.userController.RefreshToken)
+
+	// Session routes
+	user.GET("/sessions", u.userController.GetSessions, middlewares.Auth)
+	user.DELETE("/sessions", u.userController.RevokeSession, middlewares.Auth)
 
 	user.POST("/create", u.userController.CreateUser)
 	user.GET("/get", u.userController.GetUser)
 	user.GET("/getAll", u.userController.GetUsers)
 	user.PUT("/update", u.userController.UpdateUser, middlewares.Auth)
+	user.PATCH("/update", u.userController.PatchUser, middlewares.AuthOrAPIKey)
 	user.DELETE("/delete", u.userController.DeleteUser, middlewares.Auth)
+
+	// Admin routes
+	user.PUT("/role", u.userController.UpdateUserRole, middlewares.Auth, middlewares.RequirePermission(permissions.UserManage))
+	user.POST("/unlock", u.userController.UnlockUser, middlewares.Auth, middlewares.RequirePermission(permissions.UserManage))
 }
@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+type verificationRoutes struct {
+	echo                   *echo.Echo
+	verificationController domain.VerificationController
+}
+
+func NewVerificationRoutes(e *echo.Echo, controller domain.VerificationController) *verificationRoutes {
+	return &verificationRoutes{
+		echo:                   e,
+		verificationController: controller,
+	}
+}
+
+func (v *verificationRoutes) InitVerificationRoutes() {
+	e := v.echo
+	v.initVerificationRoutes(e)
+}
+
+func (v *verificationRoutes) initVerificationRoutes(e *echo.Echo) {
+
+	// group the routes
+	common := e.Group("blog_api")
+	version := common.Group("/v1")
+
+	verify := version.Group("/user/verify")
+
+	// email verification routes
+	verify.POST("", v.verificationController.VerifyEmail)
+	verify.POST("/resend", v.verificationController.ResendVerification, middlewares.Auth)
+}
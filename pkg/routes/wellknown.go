@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"Blog_API/pkg/domain"
+	"github.com/labstack/echo/v4"
+)
+
+type wellKnownRoutes struct {
+	echo                *echo.Echo
+	wellKnownController domain.WellKnownController
+}
+
+func NewWellKnownRoutes(e *echo.Echo, controller domain.WellKnownController) *wellKnownRoutes {
+	return &wellKnownRoutes{
+		echo:                e,
+		wellKnownController: controller,
+	}
+}
+
+func (w *wellKnownRoutes) InitWellKnownRoutes() {
+	e := w.echo
+	w.initWellKnownRoutes(e)
+}
+
+func (w *wellKnownRoutes) initWellKnownRoutes(e *echo.Echo) {
+
+	// well-known routes live at the root, outside the versioned API
+	wellKnown := e.Group("/.well-known")
+
+	wellKnown.GET("/jwks.json", w.wellKnownController.GetJWKS)
+}
@@ -0,0 +1,149 @@
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+// Marks put around the matched words of a snippet, the rest of the snippet is HTML escaped
+const (
+	MarkOpen  = "<mark>"
+	MarkClose = "</mark>"
+	Ellipsis  = "…"
+)
+
+// Snippet cuts the window of at most words words of text holding the most matches of the query
+// and marks the matches in it. It reports false when nothing in text matches.
+func Snippet(text string, query Query, words int) (string, bool) {
+
+	tokens := Tokenize(text)
+	marked := matches(tokens, query)
+
+	first := -1
+	for i, m := range marked {
+		if m {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+
+	start, end := bestWindow(marked, words)
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(Ellipsis)
+	}
+
+	cursor := tokens[start].Start
+	for i := start; i < end; i++ {
+		if !marked[i] {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[cursor:tokens[i].Start]))
+		b.WriteString(MarkOpen)
+		b.WriteString(html.EscapeString(text[tokens[i].Start:tokens[i].End]))
+		b.WriteString(MarkClose)
+		cursor = tokens[i].End
+	}
+	b.WriteString(html.EscapeString(text[cursor:tokens[end-1].End]))
+
+	if end < len(tokens) {
+		b.WriteString(Ellipsis)
+	}
+
+	return b.String(), true
+}
+
+// Highlight makes the snippets of the fields of doc that match the query
+func Highlight(doc Document, query Query) map[string]string {
+
+	highlights := make(map[string]string)
+
+	texts := map[string]string{FieldTitle: doc.Title, FieldDescription: doc.Description, FieldContent: doc.Content}
+	for field, text := range texts {
+		if snippet, ok := Snippet(text, query, SnippetWords); ok {
+			highlights[field] = snippet
+		}
+	}
+
+	// The first comment that matches stands for all of them
+	for _, comment := range doc.Comments {
+		if snippet, ok := Snippet(comment, query, SnippetWords); ok {
+			highlights[FieldComments] = snippet
+			break
+		}
+	}
+
+	return highlights
+}
+
+// matches flags the tokens that are a term of the query or part of one of its phrases
+func matches(tokens []Token, query Query) []bool {
+
+	terms := make(map[string]bool, len(query.Terms))
+	for _, term := range query.Terms {
+		terms[term] = true
+	}
+
+	marked := make([]bool, len(tokens))
+	for i, token := range tokens {
+		if terms[token.Term] {
+			marked[i] = true
+		}
+	}
+
+	for _, phrase := range query.Phrases {
+		for i := 0; i+len(phrase) <= len(tokens); i++ {
+			if phraseAt(tokens, i, phrase) {
+				for j := range phrase {
+					marked[i+j] = true
+				}
+			}
+		}
+	}
+
+	return marked
+}
+
+func phraseAt(tokens []Token, at int, phrase []string) bool {
+	for j, term := range phrase {
+		if tokens[at+j].Term != term {
+			return false
+		}
+	}
+	return true
+}
+
+// bestWindow returns the [start, end) range of at most size tokens with the most marked tokens,
+// the earliest one wins a tie
+func bestWindow(marked []bool, size int) (int, int) {
+
+	if size <= 0 || size >= len(marked) {
+		return 0, len(marked)
+	}
+
+	count := 0
+	for i := 0; i < size; i++ {
+		if marked[i] {
+			count++
+		}
+	}
+
+	best, bestStart := count, 0
+	for start := 1; start+size <= len(marked); start++ {
+		if marked[start-1] {
+			count--
+		}
+		if marked[start+size-1] {
+			count++
+		}
+		if count > best {
+			best, bestStart = count, start
+		}
+	}
+
+	return bestStart, bestStart + size
+}
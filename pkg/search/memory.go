@@ -0,0 +1,249 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// commentGap separates the positions of two comments, so a phrase never spans the end of one
+// comment and the start of the next
+const commentGap = 1 << 16
+
+// saturation of the term frequency: a word found twice counts for more than once, ten times
+// not for much more than five
+const saturation = 1.2
+
+// positions of a term in the fields of one document
+type fieldPositions map[string][]int
+
+type memoryDoc struct {
+	doc   Document
+	terms []string // distinct terms, to take the document out of the postings again
+}
+
+// MemoryIndex is an inverted index held in the process. It is filled from the database at start
+// up and kept current by the blog service, so it only fits deployments of a single instance.
+type MemoryIndex struct {
+	mu       sync.RWMutex
+	docs     map[string]*memoryDoc
+	postings map[string]map[string]fieldPositions // term -> document id -> field -> positions
+}
+
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{
+		docs:     make(map[string]*memoryDoc),
+		postings: make(map[string]map[string]fieldPositions),
+	}
+}
+
+// Index implements domain.SearchIndex.
+func (m *MemoryIndex) Index(doc Document) error {
+
+	fields := map[string]fieldPositions{}
+	add := func(field string, terms []string, offset int) {
+		for i, term := range terms {
+			if fields[term] == nil {
+				fields[term] = fieldPositions{}
+			}
+			fields[term][field] = append(fields[term][field], offset+i)
+		}
+	}
+
+	add(FieldTitle, Terms(doc.Title), 0)
+	add(FieldDescription, Terms(doc.Description), 0)
+	add(FieldContent, Terms(doc.Content), 0)
+	offset := 0
+	for _, comment := range doc.Comments {
+		terms := Terms(comment)
+		add(FieldComments, terms, offset)
+		offset += len(terms) + commentGap
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(doc.ID)
+
+	indexed := &memoryDoc{doc: doc, terms: make([]string, 0, len(fields))}
+	for term, positions := range fields {
+		if m.postings[term] == nil {
+			m.postings[term] = make(map[string]fieldPositions)
+		}
+		m.postings[term][doc.ID] = positions
+		indexed.terms = append(indexed.terms, term)
+	}
+	m.docs[doc.ID] = indexed
+
+	return nil
+}
+
+// Remove implements domain.SearchIndex.
+func (m *MemoryIndex) Remove(blogID string) error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(blogID)
+
+	return nil
+}
+
+// Search implements domain.SearchIndex.
+func (m *MemoryIndex) Search(query Query, offset int, limit int) ([]Hit, int, error) {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	words := query.Words()
+	if len(words) == 0 {
+		return []Hit{}, 0, nil
+	}
+
+	// Every word has to be in the document, start from the rarest to keep the candidates few
+	sort.Slice(words, func(i, j int) bool { return len(m.postings[words[i]]) < len(m.postings[words[j]]) })
+
+	var candidates []string
+	for id := range m.postings[words[0]] {
+		candidates = append(candidates, id)
+	}
+	for _, word := range words[1:] {
+		kept := candidates[:0]
+		for _, id := range candidates {
+			if _, ok := m.postings[word][id]; ok {
+				kept = append(kept, id)
+			}
+		}
+		candidates = kept
+	}
+
+	var hits []Hit
+	for _, id := range candidates {
+		score, ok := m.score(id, query)
+		if !ok {
+			continue
+		}
+		doc := m.docs[id].doc
+		hits = append(hits, Hit{ID: doc.ID, Slug: doc.Slug, Title: doc.Title, Score: score})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+
+	total := len(hits)
+	if offset >= total {
+		return []Hit{}, total, nil
+	}
+	hits = hits[offset:]
+	if limit > 0 && limit < len(hits) {
+		hits = hits[:limit]
+	}
+
+	// Snippets are only made for the page returned
+	for i := range hits {
+		hits[i].Highlights = Highlight(m.docs[hits[i].ID].doc, query)
+	}
+
+	return hits, total, nil
+}
+
+// Len returns the number of documents in the index
+func (m *MemoryIndex) Len() int {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.docs)
+}
+
+// score weighs the terms of the query by their rarity across the index and the boost of the
+// fields they are in. It reports false when a phrase of the query is not in any field.
+func (m *MemoryIndex) score(id string, query Query) (float64, bool) {
+
+	score := 0.0
+
+	for _, term := range query.Terms {
+		idf := m.idf(term)
+		for field, positions := range m.postings[term][id] {
+			score += Boosts[field] * idf * saturate(len(positions))
+		}
+	}
+
+	for _, phrase := range query.Phrases {
+		idf := 0.0
+		for _, term := range phrase {
+			idf += m.idf(term)
+		}
+
+		found := false
+		for _, field := range Fields {
+			if occurrences := m.phraseCount(id, field, phrase); occurrences > 0 {
+				score += Boosts[field] * idf * saturate(occurrences)
+				found = true
+			}
+		}
+		if !found {
+			return 0, false
+		}
+	}
+
+	return score, true
+}
+
+// phraseCount counts where the terms of phrase follow each other in a field of a document
+func (m *MemoryIndex) phraseCount(id string, field string, phrase []string) int {
+
+	count := 0
+	for _, start := range m.postings[phrase[0]][id][field] {
+		found := true
+		for j, term := range phrase[1:] {
+			if !contains(m.postings[term][id][field], start+j+1) {
+				found = false
+				break
+			}
+		}
+		if found {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (m *MemoryIndex) idf(term string) float64 {
+	df := float64(len(m.postings[term]))
+	n := float64(len(m.docs))
+	return math.Log(1 + (n-df+0.5)/(df+0.5))
+}
+
+// remove takes a document out of the postings, the caller holds the write lock
+func (m *MemoryIndex) remove(id string) {
+
+	indexed, ok := m.docs[id]
+	if !ok {
+		return
+	}
+
+	for _, term := range indexed.terms {
+		delete(m.postings[term], id)
+		if len(m.postings[term]) == 0 {
+			delete(m.postings, term)
+		}
+	}
+	delete(m.docs, id)
+}
+
+func saturate(frequency int) float64 {
+	f := float64(frequency)
+	return f * (saturation + 1) / (f + saturation)
+}
+
+// contains reports whether the sorted positions hold position
+func contains(positions []int, position int) bool {
+	i := sort.SearchInts(positions, position)
+	return i < len(positions) && positions[i] == position
+}
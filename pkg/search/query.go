@@ -0,0 +1,83 @@
+package search
+
+import (
+	"errors"
+	"strings"
+)
+
+// MaxQueryTerms bounds the work a single query can ask for
+const MaxQueryTerms = 32
+
+var (
+	ErrEmptyQuery    = errors.New("the query has no words to search for")
+	ErrQueryTooLarge = errors.New("the query has too many words")
+)
+
+// Query is a parsed search: every term and every phrase has to be found in a post. A phrase is
+// a run of words that have to follow each other in one field.
+type Query struct {
+	Terms   []string
+	Phrases [][]string
+}
+
+// ParseQuery reads words and "quoted phrases". A phrase of a single word is a plain term and an
+// unterminated quote runs to the end of the query.
+func ParseQuery(raw string) (Query, error) {
+
+	var query Query
+	seen := make(map[string]bool)
+	count := 0
+
+	parts := strings.Split(raw, `"`)
+	for i, part := range parts {
+		terms := Terms(part)
+		count += len(terms)
+
+		// Odd parts were inside quotes
+		if i%2 == 1 && len(terms) > 1 {
+			query.Phrases = append(query.Phrases, terms)
+			continue
+		}
+
+		for _, term := range terms {
+			if !seen[term] {
+				seen[term] = true
+				query.Terms = append(query.Terms, term)
+			}
+		}
+	}
+
+	if count == 0 {
+		return Query{}, ErrEmptyQuery
+	}
+	if count > MaxQueryTerms {
+		return Query{}, ErrQueryTooLarge
+	}
+
+	return query, nil
+}
+
+// Words returns every distinct term of the query, those of the phrases included
+func (q Query) Words() []string {
+
+	seen := make(map[string]bool)
+	var words []string
+
+	add := func(term string) {
+		if !seen[term] {
+			seen[term] = true
+			words = append(words, term)
+		}
+	}
+
+	for _, term := range q.Terms {
+		add(term)
+	}
+	for _, phrase := range q.Phrases {
+		for _, term := range phrase {
+			add(term)
+		}
+	}
+
+	return words
+}
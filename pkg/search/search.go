@@ -0,0 +1,44 @@
+package search
+
+// Fields of a post that are searched
+const (
+	FieldTitle       = "title"
+	FieldDescription = "description"
+	FieldContent     = "content"
+	FieldComments    = "comments"
+)
+
+// Fields lists the searched fields, the order is the one snippets are made in
+var Fields = []string{FieldTitle, FieldDescription, FieldContent, FieldComments}
+
+// Boosts weigh a match by the field it is found in: a word of the title says more about a post
+// than the same word in its content, or in what readers wrote about it
+var Boosts = map[string]float64{
+	FieldTitle:       3,
+	FieldDescription: 2,
+	FieldContent:     1,
+	FieldComments:    0.5,
+}
+
+// SnippetWords is the length of a highlighted snippet
+const SnippetWords = 30
+
+// Document is what gets indexed of a published post
+type Document struct {
+	ID          string
+	Slug        string
+	Title       string
+	Description string
+	Content     string
+	Comments    []string
+}
+
+// Hit is a post matching a query, Highlights holds a snippet with the matches marked for every
+// field that matched
+type Hit struct {
+	ID         string
+	Slug       string
+	Title      string
+	Score      float64
+	Highlights map[string]string
+}
@@ -0,0 +1,83 @@
+package search
+
+import (
+	"golang.org/x/text/unicode/norm"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token is a word of a text, Start and End are its byte offsets in the text
+type Token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// Tokenize splits text into words of letters, digits and marks of every script. Terms are
+// lowercased and Latin letters lose their accents, so "Crème" is found by "creme".
+func Tokenize(text string) []Token {
+
+	var tokens []Token
+	start := -1
+
+	for offset, r := range text {
+		if isWordRune(r) {
+			if start < 0 {
+				start = offset
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, Token{Term: Normalize(text[start:offset]), Start: start, End: offset})
+			start = -1
+		}
+	}
+
+	if start >= 0 {
+		tokens = append(tokens, Token{Term: Normalize(text[start:]), Start: start, End: len(text)})
+	}
+
+	return tokens
+}
+
+// Normalize turns a word into the term it is indexed under
+func Normalize(word string) string {
+
+	var b strings.Builder
+	var lastBase rune
+
+	for _, r := range norm.NFKD.String(word) {
+		if isMark(r) {
+			if lastBase == 0 || unicode.Is(unicode.Latin, lastBase) {
+				continue
+			}
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+		lastBase = r
+	}
+
+	return norm.NFC.String(b.String())
+}
+
+// Terms returns the terms of a text in order
+func Terms(text string) []string {
+
+	tokens := Tokenize(text)
+	terms := make([]string, len(tokens))
+	for i, token := range tokens {
+		terms[i] = token.Term
+	}
+
+	return terms
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r) || isMark(r))
+}
+
+func isMark(r rune) bool {
+	return unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Mc, r) || unicode.Is(unicode.Me, r)
+}
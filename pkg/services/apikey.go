@@ -0,0 +1,188 @@
+package services
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/permissions"
+	"Blog_API/pkg/types"
+	"Blog_API/pkg/utils"
+	apikeyconsts "Blog_API/pkg/utils/consts/apikey"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Parent struct to implement interface binding
+type apiKeyService struct {
+	repo     domain.APIKeyRepository
+	userRepo domain.Repository
+}
+
+// Interface binding
+func NewAPIKeyService(repo domain.APIKeyRepository, userRepo domain.Repository) domain.APIKeyService {
+	return &apiKeyService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+// CreateAPIKey implements domain.APIKeyService.
+func (svc *apiKeyService) CreateAPIKey(userID string, req types.APIKeyRequest) (types.APIKeyCreatedResp, error) {
+
+	for _, scope := range req.Scopes {
+		if !permissions.IsValid(permissions.Permission(scope)) {
+			return types.APIKeyCreatedResp{}, utils.NewStatusError(http.StatusBadRequest, fmt.Sprintf(apikeyconsts.InvalidScope, scope))
+		}
+	}
+
+	now := time.Now()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return types.APIKeyCreatedResp{}, utils.NewStatusError(http.StatusBadRequest, apikeyconsts.ExpiryInPast)
+	}
+
+	existing, err := svc.repo.GetAPIKeysOfUser(userID)
+	if err != nil {
+		return types.APIKeyCreatedResp{}, err
+	}
+
+	active := 0
+	for _, key := range existing {
+		if isActiveAPIKey(key, now) {
+			active++
+		}
+	}
+	if active >= apikeyconsts.MaxActivePerUser {
+		return types.APIKeyCreatedResp{}, utils.NewStatusError(http.StatusConflict, fmt.Sprintf(apikeyconsts.TooManyAPIKeys, apikeyconsts.MaxActivePerUser))
+	}
+
+	prefix, err := utils.GenerateRandomToken(apikeyconsts.PrefixBytes)
+	if err != nil {
+		return types.APIKeyCreatedResp{}, err
+	}
+	secret, err := utils.GenerateRandomToken(apikeyconsts.SecretBytes)
+	if err != nil {
+		return types.APIKeyCreatedResp{}, err
+	}
+
+	visiblePrefix := apikeyconsts.KeyPrefix + prefix
+	rawKey := visiblePrefix + "." + secret
+
+	key := models.APIKey{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    visiblePrefix,
+		KeyHash:   utils.HashToken(rawKey),
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+		CreatedAt: now,
+	}
+	if key.Scopes == nil {
+		key.Scopes = []string{}
+	}
+
+	if err := svc.repo.CreateAPIKey(key); err != nil {
+		return types.APIKeyCreatedResp{}, err
+	}
+
+	return types.APIKeyCreatedResp{
+		APIKeyResp: convertToAPIKeyResp(key),
+		Key:        rawKey,
+	}, nil
+}
+
+// GetAPIKeys implements domain.APIKeyService.
+func (svc *apiKeyService) GetAPIKeys(userID string) ([]types.APIKeyResp, error) {
+
+	keys, err := svc.repo.GetAPIKeysOfUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]types.APIKeyResp, 0, len(keys))
+	for _, key := range keys {
+		resp = append(resp, convertToAPIKeyResp(key))
+	}
+
+	return resp, nil
+}
+
+// RevokeAPIKey implements domain.APIKeyService.
+func (svc *apiKeyService) RevokeAPIKey(userID string, keyID string) error {
+
+	err := svc.repo.RevokeAPIKey(userID, keyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.NewStatusError(http.StatusNotFound, apikeyconsts.APIKeyNotFound)
+	}
+
+	return err
+}
+
+// Authenticate implements domain.APIKeyService.
+func (svc *apiKeyService) Authenticate(rawKey string) (types.APIKeyPrincipal, error) {
+
+	if !strings.HasPrefix(rawKey, apikeyconsts.KeyPrefix) {
+		return types.APIKeyPrincipal{}, utils.NewStatusError(http.StatusUnauthorized, apikeyconsts.InvalidAPIKey)
+	}
+
+	key, err := svc.repo.GetAPIKeyByHash(utils.HashToken(rawKey))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return types.APIKeyPrincipal{}, utils.NewStatusError(http.StatusUnauthorized, apikeyconsts.InvalidAPIKey)
+		}
+		return types.APIKeyPrincipal{}, err
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil {
+		return types.APIKeyPrincipal{}, utils.NewStatusError(http.StatusUnauthorized, apikeyconsts.APIKeyRevoked)
+	}
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		return types.APIKeyPrincipal{}, utils.NewStatusError(http.StatusUnauthorized, apikeyconsts.APIKeyExpired)
+	}
+
+	// The role is read on every request so a demotion applies to keys right away
+	user, err := svc.userRepo.GetUser(key.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return types.APIKeyPrincipal{}, utils.NewStatusError(http.StatusUnauthorized, apikeyconsts.InvalidAPIKey)
+		}
+		return types.APIKeyPrincipal{}, err
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apikeyconsts.LastUsedPrecision {
+		if err := svc.repo.TouchAPIKey(key.ID, now); err != nil {
+			log.Printf("error updating last use of api key %s: %v", key.ID, err)
+		}
+	}
+
+	return types.APIKeyPrincipal{
+		KeyID:     key.ID,
+		UserID:    user.ID,
+		UserEmail: user.Email,
+		Role:      permissions.Normalize(user.Role),
+		Scopes:    key.Scopes,
+	}, nil
+}
+
+func isActiveAPIKey(key models.APIKey, now time.Time) bool {
+	return key.RevokedAt == nil && (key.ExpiresAt == nil || key.ExpiresAt.After(now))
+}
+
+func convertToAPIKeyResp(key models.APIKey) types.APIKeyResp {
+	return types.APIKeyResp{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
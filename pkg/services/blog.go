@@ -2,29 +2,59 @@ package services
 
 import (
 	"Blog_API/pkg/domain"
+	"Blog_API/pkg/listing"
 	"Blog_API/pkg/models"
+	"Blog_API/pkg/permissions"
+	"Blog_API/pkg/poststatus"
 	"Blog_API/pkg/types"
+	"Blog_API/pkg/utils"
 	blogconsts "Blog_API/pkg/utils/consts/blog"
 	userconsts "Blog_API/pkg/utils/consts/user"
+	verificationconsts "Blog_API/pkg/utils/consts/verification"
+	"Blog_API/pkg/views"
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
 	"time"
 )
 
 // Parent struct to implement interface binding
 type blogService struct {
-	repo domain.BlogRepository
-	uSvc domain.Service
+	repo                 domain.BlogRepository
+	uSvc                 domain.Service
+	index                domain.SearchIndex
+	viewRecorder         *views.Recorder
+	requireVerifiedEmail bool
+	scopes               []string // of the API key the calls are made with, see WithScopes
 }
 
 // Interface binding
-func NewBlogService(repo domain.BlogRepository, usvc domain.Service) domain.BlogService {
+func NewBlogService(repo domain.BlogRepository, usvc domain.Service, index domain.SearchIndex, viewRecorder *views.Recorder, requireVerifiedEmail bool) domain.BlogService {
 	return &blogService{
-		repo: repo,
-		uSvc: usvc,
+		repo:                 repo,
+		uSvc:                 usvc,
+		index:                index,
+		viewRecorder:         viewRecorder,
+		requireVerifiedEmail: requireVerifiedEmail,
 	}
 }
 
+// WithScopes implements domain.BlogService.
+// The permission checks of the returned service also need the permission among the scopes,
+// for the calls made with an API key.
+func (svc *blogService) WithScopes(scopes []string) domain.BlogService {
+	if len(scopes) == 0 {
+		return svc
+	}
+
+	scoped := *svc
+	scoped.scopes = scopes
+	return &scoped
+}
+
 // CreateBlogPost implements domain.BlogService.
 func (svc *blogService) CreateBlogPost(reqBlogPost types.BlogPostRequest, userID string) (types.BlogResp, error) {
 
@@ -37,27 +67,65 @@ func (svc *blogService) CreateBlogPost(reqBlogPost types.BlogPostRequest, userID
 		return types.BlogResp{}, errors.New(userconsts.ErrorGettingUser)
 	}
 
+	if !svc.can(user, permissions.BlogCreate) {
+		return types.BlogResp{}, utils.NewStatusError(http.StatusForbidden, blogconsts.YouAreNotAuthorizedToCreateBlog)
+	}
+
+	if err := svc.checkEmailVerified(user); err != nil {
+		return types.BlogResp{}, err
+	}
+
+	blogID := uuid.NewString()
+
+	slug, err := newBlogSlug(svc.repo, reqBlogPost.Title, blogID)
+	if err != nil {
+		return types.BlogResp{}, err
+	}
+
+	status := reqBlogPost.Status
+	if status == "" {
+		status = poststatus.FromIsPublished(reqBlogPost.IsPublished)
+	}
+
+	tags, err := normalizeTags(reqBlogPost.Tags)
+	if err != nil {
+		return types.BlogResp{}, err
+	}
+
+	category, err := svc.resolveCategory(reqBlogPost.Category)
+	if err != nil {
+		return types.BlogResp{}, err
+	}
+
 	reqBlog := models.BlogPost{
-		ID:          uuid.NewString(),
+		ID:          blogID,
 		UserID:      user.ID,
 		Title:       reqBlogPost.Title,
+		Slug:        slug,
 		ContentText: reqBlogPost.ContentText,
 		PhotoURL:    reqBlogPost.PhotoURL,
 		Description: reqBlogPost.Description,
-		Category:    reqBlogPost.Category,
-		IsPublished: reqBlogPost.IsPublished,
-		PublishedAt: time.Now(),
+		CategoryID:  &category.ID,
+		Category:    category.Slug,
+		Tags:        tagModels(tags),
+		Status:      poststatus.Draft,
+		Version:     1,
+	}
+
+	if err := applyStatus(&reqBlog, status, reqBlogPost.PublishAt, time.Now()); err != nil {
+		return types.BlogResp{}, err
 	}
 
 	if createBlogErr := svc.repo.CreateBlogPost(reqBlog); createBlogErr != nil {
 		return types.BlogResp{}, createBlogErr
 	}
+	svc.reindex(reqBlog.ID)
 
 	return convertBlogPostToBlogResp(reqBlog), nil
 }
 
 // GetBlogPost implements domain.BlogService.
-func (svc *blogService) GetBlogPost(blogID string) (types.BlogResp, error) {
+func (svc *blogService) GetBlogPost(viewerID string, blogID string) (types.BlogResp, error) {
 	blogPost, err := svc.repo.GetBlogPost(blogID)
 	if err != nil {
 		return types.BlogResp{}, err
@@ -67,122 +135,594 @@ func (svc *blogService) GetBlogPost(blogID string) (types.BlogResp, error) {
 		return types.BlogResp{}, errors.New(userconsts.ErrorGettingUser)
 	}
 
-	return convertBlogPostToBlogResp(blogPost), nil
+	if err := svc.checkVisible(viewerID, blogPost); err != nil {
+		return types.BlogResp{}, err
+	}
+
+	blogResp := convertBlogPostToBlogResp(blogPost)
+	setViewerReaction(&blogResp, viewerID)
+
+	return blogResp, nil
 }
 
-// GetBlogPosts implements domain.BlogService.
-func (svc *blogService) GetBlogPosts() ([]types.BlogResp, error) {
+// GetBlogPostValidators implements domain.BlogService.
+func (svc *blogService) GetBlogPostValidators(blogID string) (types.CacheValidators, error) {
 
-	var blogResp []types.BlogResp
-	blogPosts, err := svc.repo.GetBlogPosts()
+	version, status, lastModified, err := svc.repo.GetBlogPostLastModified(blogID)
 	if err != nil {
-		return blogResp, err
+		return types.CacheValidators{}, err
 	}
 
-	for _, blogPost := range blogPosts {
-		blogResp = append(blogResp, convertBlogPostToBlogResp(blogPost))
+	// The validators are answered before the viewer is known, a post not everyone can read has to
+	// go through checkVisible, or a 304 would tell that it exists
+	if poststatus.Normalize(status) != poststatus.Published {
+		return types.CacheValidators{}, utils.NewStatusError(http.StatusNotFound, blogconsts.BlogNotFound)
 	}
 
-	return blogResp, nil
+	return types.CacheValidators{ETag: utils.RepresentationETag(version, lastModified), LastModified: lastModified}, nil
 }
 
-// GetBlogPostsBasedOnCategory implements domain.BlogService.
-func (svc *blogService) GetBlogPostsBasedOnCategory(category string) ([]types.BlogResp, error) {
+// GetBlogPostsValidators implements domain.BlogService.
+func (svc *blogService) GetBlogPostsValidators(category string) (types.CacheValidators, error) {
 
-	var blogResp []types.BlogResp
-	blogPosts, err := svc.repo.GetBlogPostsBasedOnCategory(category)
+	categoryIDs, err := svc.categoryFilter(category)
 	if err != nil {
-		return blogResp, err
+		return types.CacheValidators{}, err
 	}
 
-	for _, blogPost := range blogPosts {
-		blogResp = append(blogResp, convertBlogPostToBlogResp(blogPost))
+	count, lastModified, err := svc.repo.GetBlogPostsLastModified(categoryIDs)
+	if err != nil {
+		return types.CacheValidators{}, err
 	}
 
-	return blogResp, nil
+	return types.CacheValidators{ETag: utils.ListETag(count, lastModified), LastModified: lastModified}, nil
+}
+
+// GetBlogPostBySlug implements domain.BlogService.
+func (svc *blogService) GetBlogPostBySlug(viewerID string, slug string) (types.BlogResp, bool, error) {
+
+	blogPost, err := svc.repo.GetBlogPostBySlug(slug)
+	if err == nil {
+		if err := svc.checkVisible(viewerID, blogPost); err != nil {
+			return types.BlogResp{}, false, err
+		}
+		blogResp := convertBlogPostToBlogResp(blogPost)
+		setViewerReaction(&blogResp, viewerID)
+		return blogResp, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return types.BlogResp{}, false, err
+	}
+
+	// An old slug answers with the post under its current slug, the caller redirects
+	history, err := svc.repo.GetSlugHistory(slug)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return types.BlogResp{}, false, utils.NewStatusError(http.StatusNotFound, blogconsts.BlogNotFound)
+		}
+		return types.BlogResp{}, false, err
+	}
+
+	blogPost, err = svc.repo.GetBlogPost(history.BlogPostID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return types.BlogResp{}, false, utils.NewStatusError(http.StatusNotFound, blogconsts.BlogNotFound)
+		}
+		return types.BlogResp{}, false, err
+	}
+
+	if err := svc.checkVisible(viewerID, blogPost); err != nil {
+		return types.BlogResp{}, false, err
+	}
+
+	blogResp := convertBlogPostToBlogResp(blogPost)
+	setViewerReaction(&blogResp, viewerID)
+
+	return blogResp, true, nil
 }
 
 // GetBlogPosts implements domain.BlogService.
-func (svc *blogService) GetBlogPostsOfUser(userID string, blogIDs []string) ([]types.BlogResp, error) {
+func (svc *blogService) GetBlogPosts(query types.BlogListQuery) (types.BlogPage, error) {
 
-	var blogResp []types.BlogResp
-	blogPosts, err := svc.repo.GetBlogPostsOfUser(userID, blogIDs)
+	listQuery, err := publicListQuery(query)
 	if err != nil {
-		return []types.BlogResp{}, err
+		return types.BlogPage{}, err
+	}
+
+	return svc.listBlogPosts(listQuery, query.Category)
+}
+
+// GetBlogPostsBasedOnCategory implements domain.BlogService.
+func (svc *blogService) GetBlogPostsBasedOnCategory(category string, query types.BlogListQuery) (types.BlogPage, error) {
+
+	listQuery, err := publicListQuery(query)
+	if err != nil {
+		return types.BlogPage{}, err
+	}
+
+	return svc.listBlogPosts(listQuery, category)
+}
+
+// GetBlogPostsOfUser implements domain.BlogService.
+func (svc *blogService) GetBlogPostsOfUser(userID string, blogIDs []string, query types.BlogListQuery) (types.BlogPage, error) {
+
+	if query.Author != "" && query.Author != userID {
+		return types.BlogPage{}, utils.NewStatusError(http.StatusBadRequest, blogconsts.AuthorOfOwnListing)
+	}
+
+	listQuery, err := newListQuery(query)
+	if err != nil {
+		return types.BlogPage{}, err
+	}
+
+	// The owner sees their posts in every status
+	listQuery.AuthorID = userID
+	listQuery.BlogIDs = blogIDs
+	if query.Status != "" {
+		listQuery.Statuses = []string{query.Status}
+	}
+
+	return svc.listBlogPosts(listQuery, query.Category)
+}
+
+// listBlogPosts reads one page of the category, subcategories included, and hands out the cursor of
+// the next one. The posts of a listing come without their comments and reactions, only with the
+// counts of both.
+func (svc *blogService) listBlogPosts(query listing.Query, category string) (types.BlogPage, error) {
+
+	categoryIDs, err := svc.categoryFilter(category)
+	if err != nil {
+		return types.BlogPage{}, err
+	}
+	query.CategoryIDs = categoryIDs
+
+	blogPosts, total, err := svc.repo.ListBlogPosts(query)
+	if err != nil {
+		return types.BlogPage{}, err
+	}
+
+	page := types.BlogPage{
+		Items:  make([]types.BlogResp, 0, len(blogPosts)),
+		Total:  total,
+		Offset: query.Offset,
+		Limit:  query.Limit,
+	}
+	if query.After != nil {
+		page.Offset = 0
+	}
+
+	if len(blogPosts) > query.Limit {
+		blogPosts = blogPosts[:query.Limit]
+		page.NextCursor = nextCursor(query, blogPosts[len(blogPosts)-1]).Encode()
 	}
 
 	for _, blogPost := range blogPosts {
-		blogResp = append(blogResp, convertBlogPostToBlogResp(blogPost))
+		page.Items = append(page.Items, convertBlogPostToBlogResp(blogPost))
 	}
 
-	return blogResp, nil
+	return page, nil
+}
+
+// publicListQuery is newListQuery for the listings anyone can read, they only ever hold published posts
+func publicListQuery(query types.BlogListQuery) (listing.Query, error) {
+
+	if query.Status != "" && query.Status != poststatus.Published {
+		return listing.Query{}, utils.NewStatusError(http.StatusBadRequest, blogconsts.OnlyPublishedListed)
+	}
+
+	listQuery, err := newListQuery(query)
+	if err != nil {
+		return listing.Query{}, err
+	}
+	listQuery.Statuses = []string{poststatus.Published}
+
+	return listQuery, nil
+}
+
+// newListQuery applies the defaults of paging and sorting and parses the cursor and the date range
+func newListQuery(query types.BlogListQuery) (listing.Query, error) {
+
+	tags, tagMatch, err := tagFilter(query)
+	if err != nil {
+		return listing.Query{}, err
+	}
+
+	listQuery := listing.Query{
+		Offset:   query.Offset,
+		Limit:    query.Limit,
+		Sort:     query.Sort,
+		Order:    query.Order,
+		AuthorID: query.Author,
+		Tags:     tags,
+		TagMatch: tagMatch,
+	}
+
+	if listQuery.Limit <= 0 {
+		listQuery.Limit = blogconsts.DefaultPageSize
+	}
+	if listQuery.Limit > blogconsts.MaxPageSize {
+		listQuery.Limit = blogconsts.MaxPageSize
+	}
+	if listQuery.Sort == "" {
+		listQuery.Sort = listing.SortPublishedAt
+	}
+	if listQuery.Order == "" {
+		listQuery.Order = listing.Descending
+	}
+
+	if query.Cursor != "" {
+		if query.Offset > 0 {
+			return listing.Query{}, utils.NewStatusError(http.StatusBadRequest, blogconsts.CursorWithOffset)
+		}
+		cursor, err := listing.DecodeCursor(query.Cursor, listQuery.Sort, listQuery.Order)
+		if err != nil {
+			return listing.Query{}, utils.NewStatusError(http.StatusBadRequest, blogconsts.InvalidCursor)
+		}
+		listQuery.After = cursor
+	}
+
+	if query.From != "" {
+		from, _, err := parseDateFilter(query.From)
+		if err != nil {
+			return listing.Query{}, err
+		}
+		listQuery.From = &from
+	}
+
+	if query.To != "" {
+		to, dateOnly, err := parseDateFilter(query.To)
+		if err != nil {
+			return listing.Query{}, err
+		}
+		// A date includes the whole day, a time is the last instant included
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		} else {
+			to = to.Add(time.Nanosecond)
+		}
+		listQuery.Until = &to
+	}
+
+	return listQuery, nil
+}
+
+// parseDateFilter reads a date in the server time zone or an RFC 3339 time, and tells which it was
+func parseDateFilter(value string) (time.Time, bool, error) {
+
+	if t, err := time.ParseInLocation(blogconsts.DateLayout, value, time.Local); err == nil {
+		return t, true, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, utils.NewStatusError(http.StatusBadRequest, blogconsts.InvalidDateFilter)
+	}
+
+	return t, false, nil
+}
+
+// nextCursor points right after the given post in the order of the query
+func nextCursor(query listing.Query, last models.BlogPost) listing.Cursor {
+
+	cursor := listing.Cursor{Sort: query.Sort, Order: query.Order, ID: last.ID}
+
+	switch query.Sort {
+	case listing.SortPublishedAt:
+		sortTime := last.CreatedAt
+		if last.PublishedAt != nil {
+			sortTime = *last.PublishedAt
+		}
+		cursor.Time = &sortTime
+	case listing.SortViews:
+		cursor.Count = last.Views
+	case listing.SortReactionsCount:
+		cursor.Count = last.ReactionsCount
+	case listing.SortCommentsCount:
+		cursor.Count = last.CommentsCount
+	}
+
+	return cursor
 }
 
 // UpdateBlogPost implements domain.BlogService.
-func (svc *blogService) UpdateBlogPost(userID string, blogID string, blogPostReq types.UpdateBlogPostRequest) (types.BlogResp, error) {
+func (svc *blogService) UpdateBlogPost(userID string, blogID string, version uint, blogPostReq types.UpdateBlogPostRequest) (types.BlogResp, error) {
 
 	user, err := svc.uSvc.GetUser(userID)
 	if err != nil {
 		return types.BlogResp{}, err
 	}
 
-	blogPost, err := svc.repo.GetBlogPostsOfUser(userID, []string{blogID})
+	blogPost, err := svc.repo.GetBlogPost(blogID)
 	if err != nil {
 		return types.BlogResp{}, err
 	}
 
-	if len(blogPost) == 0 {
+	if blogPost.ID == "" {
 		return types.BlogResp{}, errors.New(blogconsts.ErrorGettingBlog)
 	}
 
+	if !svc.isAllowed(user, blogPost.UserID, permissions.BlogUpdateOwn, permissions.BlogUpdateAny) {
+		return types.BlogResp{}, utils.NewStatusError(http.StatusForbidden, blogconsts.YouAreNotAuthorizedToUpdateThisBlog)
+	}
+
+	if err := utils.CheckVersion(version, blogPost.Version); err != nil {
+		return types.BlogResp{}, err
+	}
+
+	// The slug follows the title, the previous one keeps redirecting
+	slug := blogPost.Slug
+	if slug == "" || blogPostReq.Title != blogPost.Title {
+		slug, err = newBlogSlug(svc.repo, blogPostReq.Title, blogPost.ID)
+		if err != nil {
+			return types.BlogResp{}, err
+		}
+	}
+
+	// Leaving the tags out keeps them, an empty list removes them
+	var tags []string
+	if blogPostReq.Tags != nil {
+		if tags, err = normalizeTags(blogPostReq.Tags); err != nil {
+			return types.BlogResp{}, err
+		}
+	}
+
+	category, err := svc.resolveCategory(blogPostReq.Category)
+	if err != nil {
+		return types.BlogResp{}, err
+	}
+
 	blog := models.BlogPost{
-		ID:          blogPost[0].ID,
-		UserID:      user.ID,
+		ID:          blogPost.ID,
+		UserID:      blogPost.UserID,
 		Title:       blogPostReq.Title,
+		Slug:        slug,
 		ContentText: blogPostReq.ContentText,
 		PhotoURL:    blogPostReq.PhotoURL,
 		Description: blogPostReq.Description,
-		Category:    blogPostReq.Category,
-		IsPublished: blogPostReq.IsPublished,
-		PublishedAt: time.Now(),
+		CategoryID:  &category.ID,
+		Category:    category.Slug,
+		Tags:        tagModels(tags),
+		Version:     blogPost.Version,
+	}
+
+	// Older clients only send is_published, false from them means "leave it as it is"
+	status := blogPostReq.Status
+	if status == "" && blogPostReq.IsPublished {
+		status = poststatus.Published
+	}
+
+	// The status is written with the content, a refused transition must not leave the content half written
+	blog.Status, blog.IsPublished, blog.PublishAt, blog.PublishedAt = blogPost.Status, blogPost.IsPublished, blogPost.PublishAt, blogPost.PublishedAt
+	statusChange := status != "" && (status != poststatus.Normalize(blogPost.Status) || status == poststatus.Scheduled)
+	if statusChange {
+		if err := applyStatus(&blog, status, blogPostReq.PublishAt, time.Now()); err != nil {
+			return types.BlogResp{}, err
+		}
 	}
 
-	if updateErr := svc.repo.UpdateBlogPost(blog); updateErr != nil {
+	if updateErr := svc.repo.UpdateBlogPost(blog, statusChange, models.BlogRevision{AuthorID: user.ID, Action: blogconsts.RevisionUpdate}); updateErr != nil {
 		return types.BlogResp{}, updateErr
 	}
+	blog.Version++
+	if blog.Tags == nil {
+		blog.Tags = blogPost.Tags
+	}
+
+	if blogPost.Slug != "" && blogPost.Slug != slug {
+		if err := svc.repo.RecordSlugChange(blogPost.ID, blogPost.Slug, slug); err != nil {
+			return types.BlogResp{}, err
+		}
+	}
+	svc.reindex(blog.ID)
 
 	return convertBlogPostToBlogResp(blog), nil
 }
 
+// PatchBlogPost implements domain.BlogService.
+func (svc *blogService) PatchBlogPost(userID string, blogID string, version uint, patch []byte) (types.BlogResp, error) {
+
+	user, err := svc.uSvc.GetUser(userID)
+	if err != nil {
+		return types.BlogResp{}, err
+	}
+
+	blogPost, err := svc.repo.GetBlogPost(blogID)
+	if err != nil {
+		return types.BlogResp{}, err
+	}
+
+	if !svc.isAllowed(user, blogPost.UserID, permissions.BlogUpdateOwn, permissions.BlogUpdateAny) {
+		return types.BlogResp{}, utils.NewStatusError(http.StatusForbidden, blogconsts.YouAreNotAuthorizedToUpdateThisBlog)
+	}
+
+	if err := utils.CheckVersion(version, blogPost.Version); err != nil {
+		return types.BlogResp{}, err
+	}
+
+	doc := types.BlogPostPatch{
+		Title:       blogPost.Title,
+		ContentText: blogPost.ContentText,
+		PhotoURL:    blogPost.PhotoURL,
+		Description: blogPost.Description,
+		Category:    blogPost.Category,
+		Tags:        tagNames(blogPost.Tags),
+		Status:      poststatus.Normalize(blogPost.Status),
+		PublishAt:   blogPost.PublishAt,
+	}
+
+	fields, err := utils.ApplyMergePatch(&doc, patch)
+	if err != nil {
+		return types.BlogResp{}, err
+	}
+
+	// The merged post is validated as a whole, not only the fields of the patch
+	if err := doc.Validate(); err != nil {
+		return types.BlogResp{}, utils.NewStatusError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	// Status and publish_at go through the lifecycle, tags are written apart, the rest is content
+	var contentFields []string
+	statusPatched, tagsPatched := false, false
+	for _, field := range fields {
+		switch field {
+		case "Status", "PublishAt":
+			statusPatched = true
+		case "Tags":
+			tagsPatched = true
+		case "Category":
+			contentFields = append(contentFields, field, "CategoryID")
+		default:
+			contentFields = append(contentFields, field)
+		}
+	}
+
+	// The status is written with the content, a refused transition must not leave the content half written
+	patched := blogPost
+	statusChange := statusPatched && (doc.Status != poststatus.Normalize(blogPost.Status) || doc.Status == poststatus.Scheduled)
+	if statusChange {
+		if err := applyStatus(&patched, doc.Status, doc.PublishAt, time.Now()); err != nil {
+			return types.BlogResp{}, err
+		}
+	}
+
+	patched.Title = doc.Title
+	patched.ContentText = doc.ContentText
+	patched.PhotoURL = doc.PhotoURL
+	patched.Description = doc.Description
+	patched.Tags = nil
+
+	if doc.Category != blogPost.Category {
+		category, err := svc.resolveCategory(doc.Category)
+		if err != nil {
+			return types.BlogResp{}, err
+		}
+		patched.CategoryID, patched.Category = &category.ID, category.Slug
+	}
+
+	if tagsPatched {
+		tags, err := normalizeTags(doc.Tags)
+		if err != nil {
+			return types.BlogResp{}, err
+		}
+		patched.Tags = tagModels(tags)
+	}
+
+	if patched.Slug == "" || patched.Title != blogPost.Title {
+		patched.Slug, err = newBlogSlug(svc.repo, patched.Title, blogPost.ID)
+		if err != nil {
+			return types.BlogResp{}, err
+		}
+		contentFields = append(contentFields, "Slug")
+	}
+
+	if len(contentFields) > 0 || tagsPatched {
+		if err := svc.repo.PatchBlogPost(patched, contentFields, statusChange, models.BlogRevision{AuthorID: user.ID, Action: blogconsts.RevisionUpdate}); err != nil {
+			return types.BlogResp{}, err
+		}
+		patched.Version++
+
+		if blogPost.Slug != "" && blogPost.Slug != patched.Slug {
+			if err := svc.repo.RecordSlugChange(blogPost.ID, blogPost.Slug, patched.Slug); err != nil {
+				return types.BlogResp{}, err
+			}
+		}
+	} else if statusChange {
+		if err := svc.repo.UpdateBlogPostStatus(patched.ID, patched.Version, patched.Status, patched.PublishAt, patched.PublishedAt); err != nil {
+			return types.BlogResp{}, err
+		}
+		patched.Version++
+	}
+
+	if !tagsPatched {
+		patched.Tags = blogPost.Tags
+	}
+	svc.reindex(patched.ID)
+
+	return convertBlogPostToBlogResp(patched), nil
+}
+
+// ChangeBlogPostStatus implements domain.BlogService.
+func (svc *blogService) ChangeBlogPostStatus(userID string, blogID string, version uint, req types.BlogStatusRequest) (types.BlogResp, error) {
+
+	user, err := svc.uSvc.GetUser(userID)
+	if err != nil {
+		return types.BlogResp{}, err
+	}
+
+	blogPost, err := svc.repo.GetBlogPost(blogID)
+	if err != nil {
+		return types.BlogResp{}, err
+	}
+
+	if !svc.isAllowed(user, blogPost.UserID, permissions.BlogUpdateOwn, permissions.BlogUpdateAny) {
+		return types.BlogResp{}, utils.NewStatusError(http.StatusForbidden, blogconsts.YouAreNotAuthorizedToUpdateThisBlog)
+	}
+
+	if err := utils.CheckVersion(version, blogPost.Version); err != nil {
+		return types.BlogResp{}, err
+	}
+
+	if err := svc.changeStatus(&blogPost, req.Status, req.PublishAt); err != nil {
+		return types.BlogResp{}, err
+	}
+	svc.reindex(blogPost.ID)
+
+	return convertBlogPostToBlogResp(blogPost), nil
+}
+
+// PublishScheduledPosts implements domain.BlogService.
+func (svc *blogService) PublishScheduledPosts() (int64, error) {
+
+	published, err := svc.repo.PublishDuePosts(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	if len(published) > 0 {
+		log.Printf("published %d scheduled blog posts", len(published))
+	}
+	for _, blogID := range published {
+		svc.reindex(blogID)
+	}
+
+	return int64(len(published)), nil
+}
+
 // DeleteBlogPost implements domain.BlogService.
-func (svc *blogService) DeleteBlogPost(userID string, blogID string) error {
+func (svc *blogService) DeleteBlogPost(userID string, blogID string, version uint) error {
 
 	user, err := svc.uSvc.GetUser(userID)
 	if err != nil {
 		return err
 	}
 
-	blogPost, err := svc.repo.GetBlogPostsOfUser(user.ID, []string{blogID})
+	blogPost, err := svc.repo.GetBlogPost(blogID)
 	if err != nil {
 		return err
 	}
 
-	if len(blogPost) == 0 {
-		return errors.New(blogconsts.YouAreNotAuthorizedToDeleteThisBlog)
+	if !svc.isAllowed(user, blogPost.UserID, permissions.BlogDeleteOwn, permissions.BlogDeleteAny) {
+		return utils.NewStatusError(http.StatusForbidden, blogconsts.YouAreNotAuthorizedToDeleteThisBlog)
+	}
+
+	if err := utils.CheckVersion(version, blogPost.Version); err != nil {
+		return err
 	}
 
-	if deleteErr := svc.repo.DeleteBlogPost(blogID); deleteErr != nil {
+	if deleteErr := svc.repo.DeleteBlogPost(blogID, blogPost.Version); deleteErr != nil {
 		return deleteErr
 	}
+	svc.reindex(blogID)
 
 	return nil
 }
 
 // AddAndRemoveReaction implements domain.BlogService.
-func (svc *blogService) AddAndRemoveReaction(userID string, blogID string, reactionID uint64) (types.BlogResp, error) {
+func (svc *blogService) AddAndRemoveReaction(userID string, blogID string, reaction string) (types.BlogResp, error) {
 
-	if blogconsts.ReactionTypes[reactionID] == "" {
-		return types.BlogResp{}, errors.New(blogconsts.InvalidReactionID)
+	reactionType, err := svc.resolveReactionType(reaction)
+	if err != nil {
+		return types.BlogResp{}, err
 	}
 
 	user, err := svc.uSvc.GetUser(userID)
@@ -190,6 +730,10 @@ func (svc *blogService) AddAndRemoveReaction(userID string, blogID string, react
 		return types.BlogResp{}, err
 	}
 
+	if !svc.can(user, permissions.ReactionCreate) {
+		return types.BlogResp{}, utils.NewStatusError(http.StatusForbidden, blogconsts.YouAreNotAuthorizedToReact)
+	}
+
 	blogPost, err := svc.repo.GetBlogPost(blogID)
 	if err != nil {
 		return types.BlogResp{}, err
@@ -199,7 +743,15 @@ func (svc *blogService) AddAndRemoveReaction(userID string, blogID string, react
 		return types.BlogResp{}, errors.New(blogconsts.ErrorGettingBlog)
 	}
 
-	blogPost, err = svc.repo.AddAndRemoveReaction(user.ID, reactionID, blogPost)
+	if err := svc.checkVisible(user.ID, blogPost); err != nil {
+		return types.BlogResp{}, err
+	}
+
+	if err := checkReactable(reactionType, blogPost, user.ID); err != nil {
+		return types.BlogResp{}, err
+	}
+
+	blogPost, err = svc.repo.AddAndRemoveReaction(user.ID, reactionType.ID, blogPost)
 	if err != nil {
 		return types.BlogResp{}, err
 	}
@@ -215,6 +767,14 @@ func (svc *blogService) AddComment(userID, blogID string, commentReq types.Comme
 		return types.BlogResp{}, err
 	}
 
+	if !svc.can(user, permissions.CommentCreate) {
+		return types.BlogResp{}, utils.NewStatusError(http.StatusForbidden, blogconsts.YouAreNotAuthorizedToComment)
+	}
+
+	if err := svc.checkEmailVerified(user); err != nil {
+		return types.BlogResp{}, err
+	}
+
 	blogPost, err := svc.repo.GetBlogPost(blogID)
 	if err != nil {
 		return types.BlogResp{}, err
@@ -224,17 +784,23 @@ func (svc *blogService) AddComment(userID, blogID string, commentReq types.Comme
 		return types.BlogResp{}, errors.New(blogconsts.ErrorGettingBlog)
 	}
 
+	if err := svc.checkVisible(user.ID, blogPost); err != nil {
+		return types.BlogResp{}, err
+	}
+
 	comment := models.Comment{
 		ID:         uuid.NewString(),
 		UserID:     user.ID,
 		BlogPostID: blogPost.ID,
 		Content:    commentReq.Content,
+		Version:    1,
 	}
 
 	blogResp, commentErr := svc.repo.AddComment(blogPost, comment)
 	if commentErr != nil {
 		return types.BlogResp{}, commentErr
 	}
+	svc.reindex(blogPost.ID)
 
 	return convertBlogPostToBlogResp(blogResp), nil
 }
@@ -256,8 +822,8 @@ func (svc *blogService) GetComments(userID string, blogID string, commentIDs []s
 		return []types.CommentResp{}, errors.New(blogconsts.ErrorGettingBlog)
 	}
 
-	if blogPost.UserID != user.ID {
-		return []types.CommentResp{}, errors.New(blogconsts.YouAreNotAuthorizedToGetComments)
+	if blogPost.UserID != user.ID && !svc.can(user, permissions.CommentModerate) {
+		return []types.CommentResp{}, utils.NewStatusError(http.StatusForbidden, blogconsts.YouAreNotAuthorizedToGetComments)
 	}
 
 	comments, err := svc.repo.GetComments(blogPost.ID, commentIDs)
@@ -269,7 +835,7 @@ func (svc *blogService) GetComments(userID string, blogID string, commentIDs []s
 }
 
 // DeleteComment implements domain.BlogService.
-func (svc *blogService) DeleteComment(userID string, blogID string, commentID string) error {
+func (svc *blogService) DeleteComment(userID string, blogID string, commentID string, version uint) error {
 
 	user, err := svc.uSvc.GetUser(userID)
 	if err != nil {
@@ -294,18 +860,22 @@ func (svc *blogService) DeleteComment(userID string, blogID string, commentID st
 		return errors.New(blogconsts.ErrorGettingComments)
 	}
 
-	if (comment[0].UserID == user.ID) || (blogPost.UserID == user.ID) {
-		if deleteErr := svc.repo.DeleteComment(blogPost, commentID); deleteErr != nil {
+	if (comment[0].UserID == user.ID) || (blogPost.UserID == user.ID) || svc.can(user, permissions.CommentModerate) {
+		if err := utils.CheckVersion(version, comment[0].Version); err != nil {
+			return err
+		}
+		if deleteErr := svc.repo.DeleteComment(blogPost, commentID, comment[0].Version); deleteErr != nil {
 			return deleteErr
 		}
+		svc.reindex(blogPost.ID)
 		return nil
 	}
 
-	return errors.New(blogconsts.YouAreNotAuthorizedToDeleteThisComment)
+	return utils.NewStatusError(http.StatusForbidden, blogconsts.YouAreNotAuthorizedToDeleteThisComment)
 }
 
 // UpdateComment implements domain.BlogService.
-func (svc *blogService) UpdateComment(userID string, blogID string, commentID string, reqCommentUpdate types.Comment) (types.BlogResp, error) {
+func (svc *blogService) UpdateComment(userID string, blogID string, commentID string, version uint, reqCommentUpdate types.Comment) (types.BlogResp, error) {
 
 	user, err := svc.uSvc.GetUser(userID)
 	if err != nil {
@@ -330,40 +900,169 @@ func (svc *blogService) UpdateComment(userID string, blogID string, commentID st
 		return types.BlogResp{}, errors.New(blogconsts.ErrorGettingComments)
 	}
 
-	if comment[0].UserID != user.ID {
-		return types.BlogResp{}, errors.New(blogconsts.YouAreNotAuthorizedToUpdateThisComment)
+	if comment[0].UserID != user.ID && !svc.can(user, permissions.CommentModerate) {
+		return types.BlogResp{}, utils.NewStatusError(http.StatusForbidden, blogconsts.YouAreNotAuthorizedToUpdateThisComment)
+	}
+
+	if err := utils.CheckVersion(version, comment[0].Version); err != nil {
+		return types.BlogResp{}, err
 	}
 
 	updateCommentReq := models.Comment{
 		ID:      comment[0].ID,
 		Content: reqCommentUpdate.Content,
+		Version: comment[0].Version,
 	}
 
 	resp, err := svc.repo.UpdateComment(blogPost, updateCommentReq)
 	if err != nil {
 		return types.BlogResp{}, err
 	}
+	svc.reindex(blogPost.ID)
 
 	return convertBlogPostToBlogResp(resp), nil
 }
 
+// changeStatus moves the post to the status and stores it
+func (svc *blogService) changeStatus(blogPost *models.BlogPost, status string, publishAt *time.Time) error {
+
+	if err := applyStatus(blogPost, status, publishAt, time.Now()); err != nil {
+		return err
+	}
+
+	if err := svc.repo.UpdateBlogPostStatus(blogPost.ID, blogPost.Version, blogPost.Status, blogPost.PublishAt, blogPost.PublishedAt); err != nil {
+		return err
+	}
+	blogPost.Version++
+
+	return nil
+}
+
+// checkVisible hides posts that are not published from everyone but their author and editors
+func (svc *blogService) checkVisible(viewerID string, blogPost models.BlogPost) error {
+
+	if poststatus.Normalize(blogPost.Status) == poststatus.Published || (viewerID != "" && viewerID == blogPost.UserID) {
+		return nil
+	}
+
+	if viewerID != "" {
+		viewer, err := svc.uSvc.GetUser(viewerID)
+		if err == nil && svc.can(viewer, permissions.BlogUpdateAny) {
+			return nil
+		}
+	}
+
+	return utils.NewStatusError(http.StatusNotFound, blogconsts.BlogNotFound)
+}
+
+// applyStatus checks the transition and sets the lifecycle fields of the post, PublishedAt is only ever set once
+func applyStatus(blogPost *models.BlogPost, status string, publishAt *time.Time, now time.Time) error {
+
+	current := poststatus.Normalize(blogPost.Status)
+	if !poststatus.CanTransition(current, status) {
+		return utils.NewStatusError(http.StatusConflict, fmt.Sprintf(blogconsts.InvalidStatusTransition, current, status))
+	}
+
+	blogPost.PublishAt = nil
+
+	switch status {
+	case poststatus.Scheduled:
+		if publishAt == nil || !publishAt.After(now) {
+			return utils.NewStatusError(http.StatusBadRequest, blogconsts.PublishAtMustBeInFuture)
+		}
+		at := publishAt.UTC()
+		blogPost.PublishAt = &at
+	case poststatus.Published:
+		if blogPost.PublishedAt == nil {
+			at := now
+			blogPost.PublishedAt = &at
+		}
+	}
+
+	blogPost.Status = status
+	blogPost.IsPublished = status == poststatus.Published
+
+	return nil
+}
+
+// checkEmailVerified enforces the verified email policy for publishing content
+func (svc *blogService) checkEmailVerified(user types.UserResp) error {
+	if svc.requireVerifiedEmail && !user.EmailVerified {
+		return utils.NewStatusError(http.StatusForbidden, verificationconsts.EmailNotVerified)
+	}
+	return nil
+}
+
+// isAllowed reports whether the user may act on a resource of ownerID, either as its owner or through the wider permission
+func (svc *blogService) isAllowed(user types.UserResp, ownerID string, ownPermission permissions.Permission, anyPermission permissions.Permission) bool {
+	if svc.can(user, anyPermission) {
+		return true
+	}
+	return user.ID == ownerID && svc.can(user, ownPermission)
+}
+
+// can reports whether the role of the user, narrowed to the scopes of the API key, grants the permission
+func (svc *blogService) can(user types.UserResp, permission permissions.Permission) bool {
+	return permissions.HasScoped(user.Role, svc.scopes, permission)
+}
+
 func convertBlogPostToBlogResp(blogPost models.BlogPost) types.BlogResp {
 	return types.BlogResp{
 		ID:             blogPost.ID,
 		UserID:         blogPost.UserID,
 		Title:          blogPost.Title,
+		Slug:           blogPost.Slug,
 		ContentText:    blogPost.ContentText,
 		PhotoURL:       blogPost.PhotoURL,
 		Description:    blogPost.Description,
+		CategoryID:     stringValue(blogPost.CategoryID),
 		Category:       blogPost.Category,
+		Tags:           tagNames(blogPost.Tags),
 		CommentsCount:  blogPost.CommentsCount,
 		Comments:       convertCommentsToSummary(blogPost.Comments),
 		ReactionsCount: blogPost.ReactionsCount,
+		ReactionCounts: convertReactionCounts(blogPost.ReactionTypeCounts),
 		Reactions:      convertReactionsToSummary(blogPost.Reactions),
 		Views:          blogPost.Views,
+		Version:        blogPost.Version,
+		Status:         poststatus.Normalize(blogPost.Status),
 		IsPublished:    blogPost.IsPublished,
-		PublishedAt:    blogPost.PublishedAt.Format(time.RFC3339),
+		PublishAt:      formatOptionalTime(blogPost.PublishAt),
+		PublishedAt:    formatOptionalTime(blogPost.PublishedAt),
+		LastModified:   blogPostLastModified(blogPost),
+	}
+}
+
+// blogPostLastModified must agree with blogRepo.GetBlogPostLastModified, or conditional reads never match
+func blogPostLastModified(blogPost models.BlogPost) time.Time {
+
+	lastModified := blogPost.UpdatedAt
+	for _, comment := range blogPost.Comments {
+		if comment.UpdatedAt.After(lastModified) {
+			lastModified = comment.UpdatedAt
+		}
+	}
+	for _, reaction := range blogPost.Reactions {
+		if reaction.UpdatedAt.After(lastModified) {
+			lastModified = reaction.UpdatedAt
+		}
+	}
+
+	return lastModified
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
 	}
+	return t.Format(time.RFC3339)
 }
 
 func convertReactionsToSummary(reactions []models.Reaction) []types.ReactionResp {
@@ -387,6 +1086,7 @@ func convertCommentsToSummary(comments []models.Comment) []types.CommentResp {
 			UserID:     comment.UserID,
 			BlogPostID: comment.BlogPostID,
 			Content:    comment.Content,
+			Version:    comment.Version,
 		})
 	}
 	return summary
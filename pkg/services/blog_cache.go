@@ -0,0 +1,608 @@
+package services
+
+import (
+	"Blog_API/pkg/cache"
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/poststatus"
+	"Blog_API/pkg/types"
+	blogconsts "Blog_API/pkg/utils/consts/blog"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Parent struct to implement interface binding
+type cachedBlogService struct {
+	next  domain.BlogService
+	store cache.Store
+	ttl   time.Duration
+}
+
+// cachedPost keeps the Last-Modified of a post next to it, the response does not serialize it
+type cachedPost struct {
+	Post         types.BlogResp
+	LastModified time.Time
+	Moved        bool
+}
+
+// NewCachedBlogService puts a response cache in front of a blog service. Posts are cached per
+// post and only while published, so every viewer may be answered from the same entry. The
+// listings per category and per user share a generation that every write replaces, which drops
+// them all at once; the entries of the post written are deleted as well. The cache is only an
+// optimization: when the store fails the call goes through to the service.
+func NewCachedBlogService(next domain.BlogService, store cache.Store, ttl time.Duration) domain.BlogService {
+	return &cachedBlogService{
+		next:  next,
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+// WithScopes implements domain.BlogService.
+func (svc *cachedBlogService) WithScopes(scopes []string) domain.BlogService {
+	if len(scopes) == 0 {
+		return svc
+	}
+
+	return &cachedBlogService{
+		next:  svc.next.WithScopes(scopes),
+		store: svc.store,
+		ttl:   svc.ttl,
+	}
+}
+
+// CreateBlogPost implements domain.BlogService.
+func (svc *cachedBlogService) CreateBlogPost(reqBlogPost types.BlogPostRequest, userID string) (types.BlogResp, error) {
+
+	blogResp, err := svc.next.CreateBlogPost(reqBlogPost, userID)
+	if err != nil {
+		return blogResp, err
+	}
+
+	svc.invalidate(blogResp.ID)
+
+	return blogResp, nil
+}
+
+// GetBlogPost implements domain.BlogService.
+func (svc *cachedBlogService) GetBlogPost(viewerID string, blogID string) (types.BlogResp, error) {
+
+	key := fmt.Sprintf(blogconsts.CachePostKey, blogID)
+
+	var entry cachedPost
+	if svc.load(key, &entry) {
+		blogResp := withLastModified(entry)
+		setViewerReaction(&blogResp, viewerID)
+		return blogResp, nil
+	}
+
+	blogResp, err := svc.next.GetBlogPost(viewerID, blogID)
+	if err != nil {
+		return blogResp, err
+	}
+
+	if blogResp.Status == poststatus.Published {
+		svc.save(key, cachedPost{Post: withoutViewer(blogResp), LastModified: blogResp.LastModified})
+	}
+
+	return blogResp, nil
+}
+
+// GetBlogPostBySlug implements domain.BlogService.
+func (svc *cachedBlogService) GetBlogPostBySlug(viewerID string, slug string) (types.BlogResp, bool, error) {
+
+	// Slugs move between posts when titles change, so they are cached with the listings
+	generation, ok := svc.generation()
+	if !ok {
+		return svc.next.GetBlogPostBySlug(viewerID, slug)
+	}
+	key := fmt.Sprintf(blogconsts.CacheSlugKey, generation, slug)
+
+	var entry cachedPost
+	if svc.load(key, &entry) {
+		blogResp := withLastModified(entry)
+		setViewerReaction(&blogResp, viewerID)
+		return blogResp, entry.Moved, nil
+	}
+
+	blogResp, moved, err := svc.next.GetBlogPostBySlug(viewerID, slug)
+	if err != nil {
+		return blogResp, moved, err
+	}
+
+	if blogResp.Status == poststatus.Published {
+		svc.save(key, cachedPost{Post: withoutViewer(blogResp), LastModified: blogResp.LastModified, Moved: moved})
+	}
+
+	return blogResp, moved, nil
+}
+
+// GetBlogPosts implements domain.BlogService.
+func (svc *cachedBlogService) GetBlogPosts(query types.BlogListQuery) (types.BlogPage, error) {
+	return svc.cachedList(blogconsts.CacheAllKey, []interface{}{queryKey(query)}, func() (types.BlogPage, error) {
+		return svc.next.GetBlogPosts(query)
+	})
+}
+
+// GetBlogPostValidators implements domain.BlogService.
+func (svc *cachedBlogService) GetBlogPostValidators(blogID string) (types.CacheValidators, error) {
+
+	key := fmt.Sprintf(blogconsts.CachePostETagKey, blogID)
+
+	var validators types.CacheValidators
+	if svc.load(key, &validators) {
+		return validators, nil
+	}
+
+	validators, err := svc.next.GetBlogPostValidators(blogID)
+	if err != nil {
+		return validators, err
+	}
+	svc.save(key, validators)
+
+	return validators, nil
+}
+
+// GetBlogPostsValidators implements domain.BlogService.
+func (svc *cachedBlogService) GetBlogPostsValidators(category string) (types.CacheValidators, error) {
+
+	generation, ok := svc.generation()
+	if !ok {
+		return svc.next.GetBlogPostsValidators(category)
+	}
+	key := fmt.Sprintf(blogconsts.CacheListETagKey, generation, category)
+
+	var validators types.CacheValidators
+	if svc.load(key, &validators) {
+		return validators, nil
+	}
+
+	validators, err := svc.next.GetBlogPostsValidators(category)
+	if err != nil {
+		return validators, err
+	}
+	svc.save(key, validators)
+
+	return validators, nil
+}
+
+// GetBlogPostsBasedOnCategory implements domain.BlogService.
+func (svc *cachedBlogService) GetBlogPostsBasedOnCategory(category string, query types.BlogListQuery) (types.BlogPage, error) {
+	return svc.cachedList(blogconsts.CacheCategoryKey, []interface{}{category, queryKey(query)}, func() (types.BlogPage, error) {
+		return svc.next.GetBlogPostsBasedOnCategory(category, query)
+	})
+}
+
+// GetBlogPostsOfUser implements domain.BlogService.
+func (svc *cachedBlogService) GetBlogPostsOfUser(userID string, blogIDs []string, query types.BlogListQuery) (types.BlogPage, error) {
+
+	ids := append([]string(nil), blogIDs...)
+	sort.Strings(ids)
+
+	return svc.cachedList(blogconsts.CacheUserKey, []interface{}{userID, strings.Join(ids, ","), queryKey(query)}, func() (types.BlogPage, error) {
+		return svc.next.GetBlogPostsOfUser(userID, blogIDs, query)
+	})
+}
+
+// GetBlogPostsByTags implements domain.BlogService.
+func (svc *cachedBlogService) GetBlogPostsByTags(query types.BlogListQuery) (types.BlogPage, error) {
+	return svc.cachedList(blogconsts.CacheTaggedKey, []interface{}{queryKey(query)}, func() (types.BlogPage, error) {
+		return svc.next.GetBlogPostsByTags(query)
+	})
+}
+
+// GetTags implements domain.BlogService.
+func (svc *cachedBlogService) GetTags(query types.TagListQuery) (types.TagPage, error) {
+
+	raw, _ := json.Marshal(query)
+
+	var page types.TagPage
+	err := svc.cached(blogconsts.CacheTagsKey, []interface{}{string(raw)}, &page, func() (err error) {
+		page, err = svc.next.GetTags(query)
+		return err
+	})
+
+	return page, err
+}
+
+// SuggestTags implements domain.BlogService.
+func (svc *cachedBlogService) SuggestTags(prefix string, limit int) ([]types.TagResp, error) {
+
+	var tags []types.TagResp
+	err := svc.cached(blogconsts.CacheSuggestKey, []interface{}{prefix, limit}, &tags, func() (err error) {
+		tags, err = svc.next.SuggestTags(prefix, limit)
+		return err
+	})
+
+	return tags, err
+}
+
+// MergeTags implements domain.BlogService.
+func (svc *cachedBlogService) MergeTags(userID string, req types.MergeTagsRequest) (types.MergeTagsResp, error) {
+
+	resp, err := svc.next.MergeTags(userID, req)
+	if err != nil {
+		return resp, err
+	}
+
+	svc.invalidate(resp.BlogIDs...)
+
+	return resp, nil
+}
+
+// GetCategories implements domain.BlogService.
+func (svc *cachedBlogService) GetCategories() ([]types.CategoryResp, error) {
+
+	var categories []types.CategoryResp
+	err := svc.cached(blogconsts.CacheCategoriesKey, nil, &categories, func() (err error) {
+		categories, err = svc.next.GetCategories()
+		return err
+	})
+
+	return categories, err
+}
+
+// CreateCategory implements domain.BlogService.
+func (svc *cachedBlogService) CreateCategory(userID string, req types.CategoryRequest) (types.CategoryResp, error) {
+
+	resp, err := svc.next.CreateCategory(userID, req)
+	if err != nil {
+		return resp, err
+	}
+
+	svc.invalidate()
+
+	return resp, nil
+}
+
+// UpdateCategory implements domain.BlogService.
+func (svc *cachedBlogService) UpdateCategory(userID string, categoryID string, req types.CategoryRequest) (types.CategoryChangeResp, error) {
+
+	resp, err := svc.next.UpdateCategory(userID, categoryID, req)
+	if err != nil {
+		return resp, err
+	}
+
+	svc.invalidate(resp.BlogIDs...)
+
+	return resp, nil
+}
+
+// DeleteCategory implements domain.BlogService.
+func (svc *cachedBlogService) DeleteCategory(userID string, categoryID string, moveTo string) (types.CategoryChangeResp, error) {
+
+	resp, err := svc.next.DeleteCategory(userID, categoryID, moveTo)
+	if err != nil {
+		return resp, err
+	}
+
+	svc.invalidate(resp.BlogIDs...)
+
+	return resp, nil
+}
+
+// UpdateBlogPost implements domain.BlogService.
+func (svc *cachedBlogService) UpdateBlogPost(userID string, blogID string, version uint, blogPost types.UpdateBlogPostRequest) (types.BlogResp, error) {
+
+	blogResp, err := svc.next.UpdateBlogPost(userID, blogID, version, blogPost)
+	if err != nil {
+		return blogResp, err
+	}
+
+	svc.invalidate(blogID)
+
+	return blogResp, nil
+}
+
+// PatchBlogPost implements domain.BlogService.
+func (svc *cachedBlogService) PatchBlogPost(userID string, blogID string, version uint, patch []byte) (types.BlogResp, error) {
+
+	blogResp, err := svc.next.PatchBlogPost(userID, blogID, version, patch)
+	if err != nil {
+		return blogResp, err
+	}
+
+	svc.invalidate(blogID)
+
+	return blogResp, nil
+}
+
+// ChangeBlogPostStatus implements domain.BlogService.
+func (svc *cachedBlogService) ChangeBlogPostStatus(userID string, blogID string, version uint, req types.BlogStatusRequest) (types.BlogResp, error) {
+
+	blogResp, err := svc.next.ChangeBlogPostStatus(userID, blogID, version, req)
+	if err != nil {
+		return blogResp, err
+	}
+
+	svc.invalidate(blogID)
+
+	return blogResp, nil
+}
+
+// GetBlogRevisions implements domain.BlogService.
+func (svc *cachedBlogService) GetBlogRevisions(userID string, blogID string) ([]types.BlogRevisionResp, error) {
+	return svc.next.GetBlogRevisions(userID, blogID)
+}
+
+// GetBlogRevision implements domain.BlogService.
+func (svc *cachedBlogService) GetBlogRevision(userID string, blogID string, number uint) (types.BlogRevisionResp, error) {
+	return svc.next.GetBlogRevision(userID, blogID, number)
+}
+
+// DiffBlogRevisions implements domain.BlogService.
+func (svc *cachedBlogService) DiffBlogRevisions(userID string, blogID string, from uint, to uint) (types.BlogRevisionDiffResp, error) {
+	return svc.next.DiffBlogRevisions(userID, blogID, from, to)
+}
+
+// RestoreBlogRevision implements domain.BlogService.
+func (svc *cachedBlogService) RestoreBlogRevision(userID string, blogID string, version uint, number uint) (types.BlogResp, error) {
+
+	blogResp, err := svc.next.RestoreBlogRevision(userID, blogID, version, number)
+	if err != nil {
+		return blogResp, err
+	}
+
+	svc.invalidate(blogID)
+
+	return blogResp, nil
+}
+
+// RecordView implements domain.BlogService.
+func (svc *cachedBlogService) RecordView(blogPost types.BlogResp, viewer types.Viewer) {
+	svc.next.RecordView(blogPost, viewer)
+}
+
+// FlushViews implements domain.BlogService.
+// The cached posts keep the count they were cached with until they expire, views are not worth a new generation.
+func (svc *cachedBlogService) FlushViews() error {
+	return svc.next.FlushViews()
+}
+
+// GetBlogPostViews implements domain.BlogService.
+func (svc *cachedBlogService) GetBlogPostViews(userID string, blogID string, query types.ViewHistoryQuery) (types.ViewHistoryResp, error) {
+	return svc.next.GetBlogPostViews(userID, blogID, query)
+}
+
+// GetReactors implements domain.BlogService. Reactors are not cached, they change with every reaction.
+func (svc *cachedBlogService) GetReactors(viewerID string, blogID string, reaction string, query types.ReactorListQuery) (types.ReactorPage, error) {
+	return svc.next.GetReactors(viewerID, blogID, reaction, query)
+}
+
+// GetReactionTypes implements domain.BlogService.
+func (svc *cachedBlogService) GetReactionTypes(includeRetired bool) ([]types.ReactionTypeResp, error) {
+	return svc.next.GetReactionTypes(includeRetired)
+}
+
+// CreateReactionType implements domain.BlogService.
+func (svc *cachedBlogService) CreateReactionType(userID string, req types.ReactionTypeRequest) (types.ReactionTypeResp, error) {
+	return svc.next.CreateReactionType(userID, req)
+}
+
+// UpdateReactionType implements domain.BlogService. Cached posts key their counts by the code,
+// which never changes, so they stay valid.
+func (svc *cachedBlogService) UpdateReactionType(userID string, reaction string, req types.UpdateReactionTypeRequest) (types.ReactionTypeResp, error) {
+	return svc.next.UpdateReactionType(userID, reaction, req)
+}
+
+// RetireReactionType implements domain.BlogService.
+func (svc *cachedBlogService) RetireReactionType(userID string, reaction string) (types.ReactionTypeResp, error) {
+	return svc.next.RetireReactionType(userID, reaction)
+}
+
+// PublishScheduledPosts implements domain.BlogService.
+func (svc *cachedBlogService) PublishScheduledPosts() (int64, error) {
+
+	published, err := svc.next.PublishScheduledPosts()
+	if err != nil {
+		return published, err
+	}
+
+	// Scheduled posts are never cached, only the listings they now show up in are stale
+	if published > 0 {
+		svc.invalidate()
+	}
+
+	return published, nil
+}
+
+// DeleteBlogPost implements domain.BlogService.
+func (svc *cachedBlogService) DeleteBlogPost(userID string, blogID string, version uint) error {
+
+	if err := svc.next.DeleteBlogPost(userID, blogID, version); err != nil {
+		return err
+	}
+
+	svc.invalidate(blogID)
+
+	return nil
+}
+
+// AddAndRemoveReaction implements domain.BlogService.
+func (svc *cachedBlogService) AddAndRemoveReaction(userID string, blogID string, reaction string) (types.BlogResp, error) {
+
+	blogResp, err := svc.next.AddAndRemoveReaction(userID, blogID, reaction)
+	if err != nil {
+		return blogResp, err
+	}
+
+	svc.invalidate(blogID)
+
+	return blogResp, nil
+}
+
+// AddComment implements domain.BlogService.
+func (svc *cachedBlogService) AddComment(userID string, blogID string, comment types.Comment) (types.BlogResp, error) {
+
+	blogResp, err := svc.next.AddComment(userID, blogID, comment)
+	if err != nil {
+		return blogResp, err
+	}
+
+	svc.invalidate(blogID)
+
+	return blogResp, nil
+}
+
+// SearchBlogPosts implements domain.BlogService. Searches are not cached, the index answers them quickly enough.
+func (svc *cachedBlogService) SearchBlogPosts(query types.SearchQuery) (types.SearchPage, error) {
+	return svc.next.SearchBlogPosts(query)
+}
+
+// GetComments implements domain.BlogService.
+func (svc *cachedBlogService) GetComments(userID string, blogID string, commentIDs []string) ([]types.CommentResp, error) {
+	return svc.next.GetComments(userID, blogID, commentIDs)
+}
+
+// DeleteComment implements domain.BlogService.
+func (svc *cachedBlogService) DeleteComment(userID string, blogID string, commentID string, version uint) error {
+
+	if err := svc.next.DeleteComment(userID, blogID, commentID, version); err != nil {
+		return err
+	}
+
+	svc.invalidate(blogID)
+
+	return nil
+}
+
+// UpdateComment implements domain.BlogService.
+func (svc *cachedBlogService) UpdateComment(userID string, blogID string, commentID string, version uint, reqComment types.Comment) (types.BlogResp, error) {
+
+	blogResp, err := svc.next.UpdateComment(userID, blogID, commentID, version, reqComment)
+	if err != nil {
+		return blogResp, err
+	}
+
+	svc.invalidate(blogID)
+
+	return blogResp, nil
+}
+
+// cachedList answers a listing from the current generation, keyFormat gets the generation followed by args
+func (svc *cachedBlogService) cachedList(keyFormat string, args []interface{}, fetch func() (types.BlogPage, error)) (types.BlogPage, error) {
+
+	var page types.BlogPage
+	err := svc.cached(keyFormat, args, &page, func() (err error) {
+		page, err = fetch()
+		return err
+	})
+
+	return page, err
+}
+
+// cached loads value from the entry of the current generation, or has fetch fill value and saves it
+func (svc *cachedBlogService) cached(keyFormat string, args []interface{}, value interface{}, fetch func() error) error {
+
+	generation, ok := svc.generation()
+	if !ok {
+		return fetch()
+	}
+	key := fmt.Sprintf(keyFormat, append([]interface{}{generation}, args...)...)
+
+	if svc.load(key, value) {
+		return nil
+	}
+
+	if err := fetch(); err != nil {
+		return err
+	}
+	svc.save(key, value)
+
+	return nil
+}
+
+// generation returns the generation the listings are currently cached under. A missing one,
+// because the store lost or evicted it, is replaced by a new one rather than started from zero,
+// so listings cached before can never be served again.
+func (svc *cachedBlogService) generation() (string, bool) {
+
+	value, found, err := svc.store.Get(blogconsts.CacheGenerationKey)
+	if err != nil {
+		log.Printf("error reading blog cache generation: %v", err)
+		return "", false
+	}
+	if found {
+		return string(value), true
+	}
+
+	generation := uuid.NewString()
+	if err := svc.store.Set(blogconsts.CacheGenerationKey, []byte(generation), 0); err != nil {
+		log.Printf("error starting blog cache generation: %v", err)
+		return "", false
+	}
+
+	return generation, true
+}
+
+// invalidate drops the cached entries of the given posts and starts a new generation of listings
+func (svc *cachedBlogService) invalidate(blogIDs ...string) {
+
+	keys := make([]string, 0, 2*len(blogIDs))
+	for _, blogID := range blogIDs {
+		keys = append(keys, fmt.Sprintf(blogconsts.CachePostKey, blogID), fmt.Sprintf(blogconsts.CachePostETagKey, blogID))
+	}
+
+	if err := svc.store.Delete(keys...); err != nil {
+		log.Printf("error dropping cached blog posts %v: %v", blogIDs, err)
+	}
+
+	if err := svc.store.Set(blogconsts.CacheGenerationKey, []byte(uuid.NewString()), 0); err != nil {
+		log.Printf("error starting a new blog cache generation: %v", err)
+	}
+}
+
+// load decodes the entry at key into value, a miss and a failing store both report false
+func (svc *cachedBlogService) load(key string, value interface{}) bool {
+
+	raw, found, err := svc.store.Get(key)
+	if err != nil {
+		log.Printf("error reading blog cache %s: %v", key, err)
+		return false
+	}
+	if !found {
+		return false
+	}
+
+	if err := json.Unmarshal(raw, value); err != nil {
+		log.Printf("error decoding blog cache %s: %v", key, err)
+		return false
+	}
+
+	return true
+}
+
+func (svc *cachedBlogService) save(key string, value interface{}) {
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("error encoding blog cache %s: %v", key, err)
+		return
+	}
+
+	if err := svc.store.Set(key, raw, svc.ttl); err != nil {
+		log.Printf("error writing blog cache %s: %v", key, err)
+	}
+}
+
+// queryKey is the part of a listing key naming the page, sort and filters
+func queryKey(query types.BlogListQuery) string {
+	raw, _ := json.Marshal(query)
+	return string(raw)
+}
+
+func withLastModified(entry cachedPost) types.BlogResp {
+	entry.Post.LastModified = entry.LastModified
+	return entry.Post
+}
+
+// withoutViewer drops what belongs to the viewer that happened to fill the entry
+func withoutViewer(blogResp types.BlogResp) types.BlogResp {
+	blogResp.MyReaction = nil
+	return blogResp
+}
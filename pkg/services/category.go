@@ -0,0 +1,377 @@
+package services
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/permissions"
+	"Blog_API/pkg/types"
+	"Blog_API/pkg/utils"
+	blogconsts "Blog_API/pkg/utils/consts/blog"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// GetCategories implements domain.BlogService.
+func (svc *blogService) GetCategories() ([]types.CategoryResp, error) {
+
+	categories, err := svc.repo.ListCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	return categoryTree(categories), nil
+}
+
+// CreateCategory implements domain.BlogService.
+func (svc *blogService) CreateCategory(userID string, req types.CategoryRequest) (types.CategoryResp, error) {
+
+	if err := svc.checkCategoryManager(userID); err != nil {
+		return types.CategoryResp{}, err
+	}
+
+	category := models.Category{
+		ID:          uuid.NewString(),
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+	}
+
+	if err := svc.applyCategoryRequest(&category, req); err != nil {
+		return types.CategoryResp{}, err
+	}
+
+	if err := svc.repo.CreateCategory(category); err != nil {
+		return types.CategoryResp{}, err
+	}
+
+	return svc.categoryResp(category.ID)
+}
+
+// UpdateCategory implements domain.BlogService.
+func (svc *blogService) UpdateCategory(userID string, categoryID string, req types.CategoryRequest) (types.CategoryChangeResp, error) {
+
+	if err := svc.checkCategoryManager(userID); err != nil {
+		return types.CategoryChangeResp{}, err
+	}
+
+	category, err := svc.getCategory(categoryID)
+	if err != nil {
+		return types.CategoryChangeResp{}, err
+	}
+
+	category.Name = strings.TrimSpace(req.Name)
+	category.Description = req.Description
+	if err := svc.applyCategoryRequest(&category, req); err != nil {
+		return types.CategoryChangeResp{}, err
+	}
+
+	blogIDs, err := svc.repo.UpdateCategory(category)
+	if err != nil {
+		return types.CategoryChangeResp{}, err
+	}
+
+	resp, err := svc.categoryResp(category.ID)
+	if err != nil {
+		return types.CategoryChangeResp{}, err
+	}
+
+	return types.CategoryChangeResp{Category: resp, BlogIDs: nonNil(blogIDs)}, nil
+}
+
+// DeleteCategory implements domain.BlogService.
+func (svc *blogService) DeleteCategory(userID string, categoryID string, moveTo string) (types.CategoryChangeResp, error) {
+
+	if err := svc.checkCategoryManager(userID); err != nil {
+		return types.CategoryChangeResp{}, err
+	}
+
+	category, err := svc.getCategory(categoryID)
+	if err != nil {
+		return types.CategoryChangeResp{}, err
+	}
+
+	var target *models.Category
+	if moveTo != "" {
+		into, err := svc.resolveCategory(moveTo)
+		if err != nil {
+			return types.CategoryChangeResp{}, err
+		}
+		if into.ID == category.ID {
+			return types.CategoryChangeResp{}, utils.NewStatusError(http.StatusBadRequest, blogconsts.MoveToDeletedCategory)
+		}
+		target = &into
+	}
+
+	blogIDs, err := svc.repo.DeleteCategory(category.ID, target)
+	if err != nil {
+		return types.CategoryChangeResp{}, err
+	}
+
+	return types.CategoryChangeResp{Category: convertCategoryToResp(category), BlogIDs: nonNil(blogIDs)}, nil
+}
+
+// MigrateCategories files the posts written before categories existed, whose category is only a
+// string, under a category of that name. Categories are found by the slug of the string, so
+// "Machine Learning" and "machine-learning" end up in the same one, and posts without a usable
+// string go to the default category. It is safe to run on every start, filed posts are left alone.
+func MigrateCategories(repo domain.BlogRepository) error {
+
+	names, err := repo.GetUnfiledCategoryNames()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		category, err := findOrCreateCategory(repo, name)
+		if err != nil {
+			return err
+		}
+		if err := repo.FileBlogPosts(name, category); err != nil {
+			return err
+		}
+	}
+
+	if len(names) > 0 {
+		log.Printf("filed the blog posts of %d category names under categories", len(names))
+	}
+
+	return nil
+}
+
+func findOrCreateCategory(repo domain.BlogRepository, name string) (models.Category, error) {
+
+	name = strings.TrimSpace(name)
+	slug := utils.Slugify(name)
+	if slug == "" {
+		name, slug = blogconsts.DefaultCategoryName, blogconsts.DefaultCategorySlug
+	}
+
+	category, err := repo.GetCategory(slug)
+	if err == nil {
+		return category, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Category{}, err
+	}
+
+	category = models.Category{ID: uuid.NewString(), Name: name, Slug: slug}
+	if err := repo.CreateCategory(category); err != nil {
+		return models.Category{}, err
+	}
+
+	return category, nil
+}
+
+// resolveCategory finds the category a post is filed under from its id or slug. A name works too,
+// as long as its slug is the one of the category.
+func (svc *blogService) resolveCategory(value string) (models.Category, error) {
+
+	category, err := svc.repo.GetCategory(utils.Slugify(value))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Category{}, utils.NewStatusError(http.StatusBadRequest, fmt.Sprintf(blogconsts.UnknownCategory, value))
+	}
+
+	return category, err
+}
+
+// categoryFilter is the category of a listing along with all of its subcategories, nil when the
+// listing is not filtered by category
+func (svc *blogService) categoryFilter(value string) ([]string, error) {
+
+	if value == "" {
+		return nil, nil
+	}
+
+	categories, err := svc.repo.GetCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	slug := utils.Slugify(value)
+	for _, category := range categories {
+		if category.ID == value || category.Slug == slug {
+			return subtreeIDs(categories, category.ID), nil
+		}
+	}
+
+	return nil, utils.NewStatusError(http.StatusNotFound, blogconsts.CategoryNotFound)
+}
+
+// applyCategoryRequest sets the slug and the parent of a category being written, checking that the
+// slug is free and that the category does not end up under itself
+func (svc *blogService) applyCategoryRequest(category *models.Category, req types.CategoryRequest) error {
+
+	slug := utils.Slugify(req.Slug)
+	if req.Slug == "" {
+		slug = utils.Slugify(req.Name)
+	}
+	if slug == "" {
+		return utils.NewStatusError(http.StatusBadRequest, blogconsts.InvalidCategorySlug)
+	}
+
+	taken, err := svc.repo.GetCategory(slug)
+	if err == nil && taken.ID != category.ID {
+		return utils.NewStatusError(http.StatusConflict, fmt.Sprintf(blogconsts.CategorySlugTaken, slug))
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	category.Slug = slug
+
+	category.ParentID = nil
+	if req.ParentID == nil || *req.ParentID == "" {
+		return nil
+	}
+
+	categories, err := svc.repo.GetCategories()
+	if err != nil {
+		return err
+	}
+
+	var parent *models.Category
+	for i := range categories {
+		if categories[i].ID == *req.ParentID {
+			parent = &categories[i]
+		}
+	}
+	if parent == nil {
+		return utils.NewStatusError(http.StatusBadRequest, blogconsts.ParentCategoryNotFound)
+	}
+
+	for _, id := range subtreeIDs(categories, category.ID) {
+		if id == parent.ID {
+			return utils.NewStatusError(http.StatusBadRequest, blogconsts.CategoryCycle)
+		}
+	}
+	category.ParentID = &parent.ID
+
+	return nil
+}
+
+func (svc *blogService) getCategory(categoryID string) (models.Category, error) {
+
+	category, err := svc.repo.GetCategory(categoryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Category{}, utils.NewStatusError(http.StatusNotFound, blogconsts.CategoryNotFound)
+	}
+
+	return category, err
+}
+
+// categoryResp is the category with its counts and subcategories, as the category listing shows it
+func (svc *blogService) categoryResp(categoryID string) (types.CategoryResp, error) {
+
+	categories, err := svc.repo.ListCategories()
+	if err != nil {
+		return types.CategoryResp{}, err
+	}
+
+	if resp, ok := findCategoryResp(categoryTree(categories), categoryID); ok {
+		return resp, nil
+	}
+
+	return types.CategoryResp{}, utils.NewStatusError(http.StatusNotFound, blogconsts.CategoryNotFound)
+}
+
+func (svc *blogService) checkCategoryManager(userID string) error {
+
+	user, err := svc.uSvc.GetUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if !svc.can(user, permissions.CategoryManage) {
+		return utils.NewStatusError(http.StatusForbidden, blogconsts.YouAreNotAuthorizedToManageCategories)
+	}
+
+	return nil
+}
+
+// subtreeIDs are the ids of the category and of every category below it
+func subtreeIDs(categories []models.Category, rootID string) []string {
+
+	children := make(map[string][]string, len(categories))
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	ids := []string{rootID}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+
+	return ids
+}
+
+// categoryTree nests the categories under their parents, a category whose parent is gone shows at the top
+func categoryTree(categories []models.Category) []types.CategoryResp {
+
+	known := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		known[category.ID] = true
+	}
+
+	children := make(map[string][]models.Category, len(categories))
+	var roots []models.Category
+	for _, category := range categories {
+		if category.ParentID != nil && known[*category.ParentID] {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		} else {
+			roots = append(roots, category)
+		}
+	}
+
+	var build func(level []models.Category) []types.CategoryResp
+	build = func(level []models.Category) []types.CategoryResp {
+		nodes := make([]types.CategoryResp, 0, len(level))
+		for _, category := range level {
+			node := convertCategoryToResp(category)
+			node.Children = build(children[category.ID])
+			for _, child := range node.Children {
+				node.TotalPostsCount += child.TotalPostsCount
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+
+	return build(roots)
+}
+
+func findCategoryResp(nodes []types.CategoryResp, categoryID string) (types.CategoryResp, bool) {
+	for _, node := range nodes {
+		if node.ID == categoryID {
+			return node, true
+		}
+		if found, ok := findCategoryResp(node.Children, categoryID); ok {
+			return found, true
+		}
+	}
+	return types.CategoryResp{}, false
+}
+
+func convertCategoryToResp(category models.Category) types.CategoryResp {
+	return types.CategoryResp{
+		ID:              category.ID,
+		ParentID:        category.ParentID,
+		Name:            category.Name,
+		Slug:            category.Slug,
+		Description:     category.Description,
+		PostsCount:      category.PostsCount,
+		TotalPostsCount: category.PostsCount,
+		Children:        []types.CategoryResp{},
+	}
+}
+
+func nonNil(ids []string) []string {
+	if ids == nil {
+		return []string{}
+	}
+	return ids
+}
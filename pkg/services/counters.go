@@ -0,0 +1,41 @@
+package services
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/models"
+	"fmt"
+	"log"
+)
+
+// ReconcileCounters recounts the comments and reactions of every post, the reactions per type too,
+// and logs each counter that drifted from its rows. With fix the drifted counters are set to the
+// recount.
+func ReconcileCounters(repo domain.BlogRepository, fix bool) ([]models.CounterDiscrepancy, error) {
+
+	discrepancies, err := repo.ReconcileCounters(fix)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, discrepancy := range discrepancies {
+		log.Printf("blog post %s has %s %d, counted %d", discrepancy.BlogPostID, CounterName(discrepancy), discrepancy.Stored, discrepancy.Actual)
+	}
+
+	if len(discrepancies) > 0 {
+		if fix {
+			log.Printf("fixed %d blog post counters", len(discrepancies))
+		} else {
+			log.Printf("found %d blog post counters that drifted", len(discrepancies))
+		}
+	}
+
+	return discrepancies, nil
+}
+
+// CounterName names the counter that drifted, with the reaction type for the per type counts
+func CounterName(discrepancy models.CounterDiscrepancy) string {
+	if discrepancy.Type == 0 {
+		return discrepancy.Counter
+	}
+	return fmt.Sprintf("%s %d", discrepancy.Counter, discrepancy.Type)
+}
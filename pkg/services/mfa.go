@@ -0,0 +1,279 @@
+package services
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/ratelimit"
+	"Blog_API/pkg/totp"
+	"Blog_API/pkg/types"
+	"Blog_API/pkg/utils"
+	mfaconsts "Blog_API/pkg/utils/consts/mfa"
+	tokenconsts "Blog_API/pkg/utils/consts/token"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// recoveryCodeEncoding keeps recovery codes free of characters that are easy to mistype
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Parent struct to implement interface binding
+type mfaService struct {
+	repo     domain.MFARepository
+	userRepo domain.Repository
+	tokenSvc domain.TokenService
+	backoff  *ratelimit.Backoff
+}
+
+// Interface binding
+func NewMFAService(repo domain.MFARepository, userRepo domain.Repository, tokenSvc domain.TokenService, backoff *ratelimit.Backoff) domain.MFAService {
+	return &mfaService{
+		repo:     repo,
+		userRepo: userRepo,
+		tokenSvc: tokenSvc,
+		backoff:  backoff,
+	}
+}
+
+// Enroll implements domain.MFAService.
+func (svc *mfaService) Enroll(userID string) (types.TOTPEnrollmentResp, error) {
+
+	user, err := svc.userRepo.GetUser(userID)
+	if err != nil {
+		return types.TOTPEnrollmentResp{}, err
+	}
+
+	if user.TOTPEnabled {
+		return types.TOTPEnrollmentResp{}, utils.NewStatusError(http.StatusConflict, mfaconsts.TOTPAlreadyEnabled)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return types.TOTPEnrollmentResp{}, err
+	}
+
+	if err := svc.repo.SetTOTPSecret(userID, secret); err != nil {
+		return types.TOTPEnrollmentResp{}, err
+	}
+
+	return types.TOTPEnrollmentResp{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(mfaconsts.TOTPIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment implements domain.MFAService.
+func (svc *mfaService) ConfirmEnrollment(userID string, code string) (types.RecoveryCodesResp, error) {
+
+	user, err := svc.userRepo.GetUser(userID)
+	if err != nil {
+		return types.RecoveryCodesResp{}, err
+	}
+
+	if user.TOTPEnabled {
+		return types.RecoveryCodesResp{}, utils.NewStatusError(http.StatusConflict, mfaconsts.TOTPAlreadyEnabled)
+	}
+	if user.TOTPSecret == "" {
+		return types.RecoveryCodesResp{}, utils.NewStatusError(http.StatusBadRequest, mfaconsts.TOTPNotEnrolled)
+	}
+
+	now := time.Now()
+	if err := svc.checkBackoff(userID, now); err != nil {
+		return types.RecoveryCodesResp{}, err
+	}
+
+	step, ok := totp.Validate(user.TOTPSecret, code, now, mfaconsts.TOTPSkew)
+	if !ok {
+		svc.backoff.Fail(userID, now)
+		return types.RecoveryCodesResp{}, utils.NewStatusError(http.StatusUnauthorized, mfaconsts.InvalidMFACode)
+	}
+	svc.backoff.Reset(userID)
+
+	plainCodes, codes, err := newRecoveryCodes(userID)
+	if err != nil {
+		return types.RecoveryCodesResp{}, err
+	}
+
+	if err := svc.repo.EnableTOTP(userID, step, codes); err != nil {
+		return types.RecoveryCodesResp{}, err
+	}
+
+	return types.RecoveryCodesResp{RecoveryCodes: plainCodes}, nil
+}
+
+// BeginLoginChallenge implements domain.MFAService.
+func (svc *mfaService) BeginLoginChallenge(userID string) (types.MFAChallengeResp, bool, error) {
+
+	user, err := svc.userRepo.GetUser(userID)
+	if err != nil {
+		return types.MFAChallengeResp{}, false, err
+	}
+
+	if !user.TOTPEnabled {
+		return types.MFAChallengeResp{}, false, nil
+	}
+
+	token, expiresAt, err := svc.tokenSvc.IssueChallengeToken(userID, mfaconsts.LoginChallengePurpose, mfaconsts.MFAChallengeTTL)
+	if err != nil {
+		return types.MFAChallengeResp{}, false, err
+	}
+
+	return types.MFAChallengeResp{
+		MFARequired: true,
+		MFAToken:    token,
+		ExpiresAt:   expiresAt,
+	}, true, nil
+}
+
+// CompleteLoginChallenge implements domain.MFAService.
+func (svc *mfaService) CompleteLoginChallenge(req types.MFALoginRequest, device types.DeviceInfo) (types.TokenResp, error) {
+
+	claims, err := svc.tokenSvc.ParseChallengeToken(req.MFAToken, mfaconsts.LoginChallengePurpose)
+	if err != nil {
+		return types.TokenResp{}, err
+	}
+
+	user, err := svc.userRepo.GetUser(claims.UserID)
+	if err != nil {
+		return types.TokenResp{}, utils.NewStatusError(http.StatusUnauthorized, tokenconsts.InvalidChallengeToken)
+	}
+
+	if !user.TOTPEnabled {
+		return types.TokenResp{}, utils.NewStatusError(http.StatusUnauthorized, tokenconsts.InvalidChallengeToken)
+	}
+
+	if err := svc.verifySecondFactor(user, req.MFACodeRequest); err != nil {
+		return types.TokenResp{}, err
+	}
+
+	// The challenge is single use, a second exchange needs a fresh password login
+	if err := svc.tokenSvc.ConsumeChallengeToken(claims); err != nil {
+		return types.TokenResp{}, err
+	}
+
+	return svc.tokenSvc.IssueTokenPair(user.ID, device)
+}
+
+// Disable implements domain.MFAService.
+func (svc *mfaService) Disable(userID string, req types.MFACodeRequest) error {
+
+	user, err := svc.userRepo.GetUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.TOTPEnabled {
+		return utils.NewStatusError(http.StatusBadRequest, mfaconsts.TOTPNotEnabled)
+	}
+
+	if err := svc.verifySecondFactor(user, req); err != nil {
+		return err
+	}
+
+	return svc.repo.DisableTOTP(userID)
+}
+
+// RegenerateRecoveryCodes implements domain.MFAService.
+func (svc *mfaService) RegenerateRecoveryCodes(userID string, req types.MFACodeRequest) (types.RecoveryCodesResp, error) {
+
+	user, err := svc.userRepo.GetUser(userID)
+	if err != nil {
+		return types.RecoveryCodesResp{}, err
+	}
+
+	if !user.TOTPEnabled {
+		return types.RecoveryCodesResp{}, utils.NewStatusError(http.StatusBadRequest, mfaconsts.TOTPNotEnabled)
+	}
+
+	if err := svc.verifySecondFactor(user, req); err != nil {
+		return types.RecoveryCodesResp{}, err
+	}
+
+	plainCodes, codes, err := newRecoveryCodes(userID)
+	if err != nil {
+		return types.RecoveryCodesResp{}, err
+	}
+
+	if err := svc.repo.ReplaceRecoveryCodes(userID, codes); err != nil {
+		return types.RecoveryCodesResp{}, err
+	}
+
+	return types.RecoveryCodesResp{RecoveryCodes: plainCodes}, nil
+}
+
+// verifySecondFactor accepts a TOTP code or an unused recovery code, repeated failures are throttled per user
+func (svc *mfaService) verifySecondFactor(user models.User, req types.MFACodeRequest) error {
+
+	now := time.Now()
+	if err := svc.checkBackoff(user.ID, now); err != nil {
+		return err
+	}
+
+	var verifyErr error
+	if req.Code != "" {
+		step, ok := totp.Validate(user.TOTPSecret, req.Code, now, mfaconsts.TOTPSkew)
+		if !ok {
+			verifyErr = errors.New(mfaconsts.InvalidMFACode)
+		} else {
+			verifyErr = svc.repo.UseTOTPStep(user.ID, step)
+		}
+	} else {
+		verifyErr = svc.repo.UseRecoveryCode(user.ID, utils.HashToken(normalizeRecoveryCode(req.RecoveryCode)))
+	}
+
+	if verifyErr != nil {
+		svc.backoff.Fail(user.ID, now)
+		return utils.NewStatusError(http.StatusUnauthorized, mfaconsts.InvalidMFACode)
+	}
+
+	svc.backoff.Reset(user.ID)
+	return nil
+}
+
+func (svc *mfaService) checkBackoff(userID string, now time.Time) error {
+
+	wait := svc.backoff.RetryAfter(userID, now)
+	if wait <= 0 {
+		return nil
+	}
+
+	seconds := int(wait.Round(time.Second).Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	return utils.NewStatusError(http.StatusTooManyRequests, fmt.Sprintf(mfaconsts.TooManyMFAAttempts, seconds))
+}
+
+// newRecoveryCodes returns the codes to show the user once and the hashed rows to store
+func newRecoveryCodes(userID string) ([]string, []models.RecoveryCode, error) {
+
+	plainCodes := make([]string, 0, mfaconsts.RecoveryCodeCount)
+	codes := make([]models.RecoveryCode, 0, mfaconsts.RecoveryCodeCount)
+
+	for i := 0; i < mfaconsts.RecoveryCodeCount; i++ {
+		buf := make([]byte, mfaconsts.RecoveryCodeBytes)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(buf))
+		plainCodes = append(plainCodes, code[:mfaconsts.RecoveryCodeGroupSize]+"-"+code[mfaconsts.RecoveryCodeGroupSize:])
+		codes = append(codes, models.RecoveryCode{
+			ID:       uuid.NewString(),
+			UserID:   userID,
+			CodeHash: utils.HashToken(code),
+		})
+	}
+
+	return plainCodes, codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
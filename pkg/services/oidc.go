@@ -0,0 +1,197 @@
+package services
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/oidc"
+	"Blog_API/pkg/permissions"
+	"Blog_API/pkg/utils"
+	oidcconsts "Blog_API/pkg/utils/consts/oidc"
+	userconsts "Blog_API/pkg/utils/consts/user"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Parent struct to implement interface binding
+type oidcService struct {
+	repo      domain.OIDCRepository
+	userRepo  domain.Repository
+	providers *oidc.Registry
+}
+
+// Interface binding
+func NewOIDCService(repo domain.OIDCRepository, userRepo domain.Repository, providers *oidc.Registry) domain.OIDCService {
+	return &oidcService{
+		repo:      repo,
+		userRepo:  userRepo,
+		providers: providers,
+	}
+}
+
+// Providers implements domain.OIDCService.
+func (svc *oidcService) Providers() []string {
+	return svc.providers.Names()
+}
+
+// BeginLogin implements domain.OIDCService.
+func (svc *oidcService) BeginLogin(providerName string) (string, error) {
+
+	provider, ok := svc.providers.Get(providerName)
+	if !ok {
+		return "", utils.NewStatusError(http.StatusNotFound, oidcconsts.UnknownProvider)
+	}
+
+	state, err := utils.GenerateRandomToken(oidcconsts.StateBytes)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := utils.GenerateRandomToken(oidcconsts.StateBytes)
+	if err != nil {
+		return "", err
+	}
+	codeVerifier, err := utils.GenerateRandomToken(oidcconsts.CodeVerifierBytes)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(state, nonce, oidc.CodeChallenge(codeVerifier))
+	if err != nil {
+		return "", err
+	}
+
+	err = svc.repo.CreateAuthState(models.OIDCAuthState{
+		StateHash:    utils.HashToken(state),
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    time.Now().Add(oidcconsts.AuthStateTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return authURL, nil
+}
+
+// CompleteLogin implements domain.OIDCService.
+func (svc *oidcService) CompleteLogin(providerName string, code string, state string) (string, error) {
+
+	provider, ok := svc.providers.Get(providerName)
+	if !ok {
+		return "", utils.NewStatusError(http.StatusNotFound, oidcconsts.UnknownProvider)
+	}
+
+	authState, err := svc.repo.ConsumeAuthState(utils.HashToken(state))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", utils.NewStatusError(http.StatusBadRequest, oidcconsts.InvalidState)
+		}
+		return "", err
+	}
+
+	if authState.Provider != providerName || time.Now().After(authState.ExpiresAt) {
+		return "", utils.NewStatusError(http.StatusBadRequest, oidcconsts.InvalidState)
+	}
+
+	tokens, err := provider.Exchange(code, authState.CodeVerifier)
+	if err != nil {
+		log.Printf("oidc provider %s: %v", providerName, err)
+		return "", utils.NewStatusError(http.StatusUnauthorized, oidcconsts.ProviderLoginFailed)
+	}
+
+	claims, err := provider.VerifyIDToken(tokens.IDToken, authState.Nonce)
+	if err != nil {
+		log.Printf("oidc provider %s: %v", providerName, err)
+		return "", utils.NewStatusError(http.StatusUnauthorized, oidcconsts.ProviderLoginFailed)
+	}
+
+	user, err := svc.resolveUser(providerName, claims)
+	if err != nil {
+		return "", err
+	}
+
+	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		return "", utils.NewStatusError(http.StatusLocked, fmt.Sprintf(userconsts.AccountLocked, user.LockedUntil.UTC().Format(time.RFC3339)))
+	}
+
+	return user.ID, nil
+}
+
+// resolveUser finds the user of a provider identity, linking it by verified email or creating the account on first login
+func (svc *oidcService) resolveUser(providerName string, claims oidc.IDTokenClaims) (models.User, error) {
+
+	identity, err := svc.repo.GetIdentity(providerName, claims.Subject)
+	if err == nil {
+		return svc.userRepo.GetUser(identity.UserID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.User{}, err
+	}
+
+	// An unverified address could belong to anyone, so it never links or creates an account
+	if claims.Email == "" || !claims.EmailVerified {
+		return models.User{}, utils.NewStatusError(http.StatusForbidden, oidcconsts.ProviderEmailNotVerified)
+	}
+
+	user, err := svc.userRepo.GetUserByEmail(claims.Email)
+	switch {
+	case err == nil:
+		// Linking to an unverified local account would let whoever registered it keep access
+		if !user.EmailVerified {
+			return models.User{}, utils.NewStatusError(http.StatusConflict, oidcconsts.LocalEmailNotVerified)
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		user, err = svc.createUser(claims)
+		if err != nil {
+			return models.User{}, err
+		}
+	default:
+		return models.User{}, err
+	}
+
+	err = svc.repo.CreateIdentity(models.UserIdentity{
+		ID:       uuid.NewString(),
+		UserID:   user.ID,
+		Provider: providerName,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+func (svc *oidcService) createUser(claims oidc.IDTokenClaims) (models.User, error) {
+
+	// The password is never shown, a provider user who wants one goes through forgot password
+	password, err := utils.GenerateRandomToken(oidcconsts.StateBytes)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	now := time.Now()
+	user := models.User{
+		ID:              uuid.NewString(),
+		Email:           claims.Email,
+		EmailVerified:   true,
+		EmailVerifiedAt: &now,
+		Password:        password,
+		FirstName:       claims.GivenName,
+		LastName:        claims.FamilyName,
+		Role:            permissions.DefaultRole,
+		Version:         1,
+	}
+
+	if err := svc.userRepo.CreateUser(user); err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/utils"
+	passwordconsts "Blog_API/pkg/utils/consts/password"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Parent struct to implement interface binding
+type passwordService struct {
+	userRepo      domain.Repository
+	userTokenRepo domain.UserTokenRepository
+	tokenSvc      domain.TokenService
+	mailer        domain.Mailer
+	appBaseURL    string
+}
+
+// Interface binding
+func NewPasswordService(userRepo domain.Repository, userTokenRepo domain.UserTokenRepository, tokenSvc domain.TokenService, mailer domain.Mailer, appBaseURL string) domain.PasswordService {
+	return &passwordService{
+		userRepo:      userRepo,
+		userTokenRepo: userTokenRepo,
+		tokenSvc:      tokenSvc,
+		mailer:        mailer,
+		appBaseURL:    appBaseURL,
+	}
+}
+
+// ForgotPassword implements domain.PasswordService.
+func (svc *passwordService) ForgotPassword(email string) error {
+
+	user, err := svc.userRepo.GetUserByEmail(email)
+	if err != nil {
+		// Unknown emails succeed silently so the endpoint can not be used to find accounts
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// Only the most recently mailed link works
+	if err := svc.userTokenRepo.InvalidateUserTokens(user.ID, passwordconsts.PasswordResetTokenPurpose); err != nil {
+		return err
+	}
+
+	tokenString, err := utils.GenerateRandomToken(passwordconsts.PasswordResetTokenByteSize)
+	if err != nil {
+		return err
+	}
+
+	resetToken := models.UserToken{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		Purpose:   passwordconsts.PasswordResetTokenPurpose,
+		TokenHash: utils.HashToken(tokenString),
+		ExpiresAt: time.Now().Add(passwordconsts.PasswordResetTokenTTL),
+	}
+
+	if err := svc.userTokenRepo.CreateUserToken(resetToken); err != nil {
+		return err
+	}
+
+	link := svc.appBaseURL + passwordconsts.PasswordResetPath + url.QueryEscape(tokenString)
+	body := fmt.Sprintf(passwordconsts.PasswordResetMailBody, link, tokenString, int(passwordconsts.PasswordResetTokenTTL.Minutes()))
+
+	return svc.mailer.Send(user.Email, passwordconsts.PasswordResetMailSubject, body)
+}
+
+// ResetPassword implements domain.PasswordService.
+func (svc *passwordService) ResetPassword(tokenString string, newPassword string) error {
+
+	resetToken, err := svc.userTokenRepo.GetUserTokenByHash(passwordconsts.PasswordResetTokenPurpose, utils.HashToken(tokenString))
+	if err != nil {
+		return utils.NewStatusError(http.StatusBadRequest, passwordconsts.InvalidResetToken)
+	}
+
+	if resetToken.UsedAt != nil || !resetToken.ExpiresAt.After(time.Now()) {
+		return utils.NewStatusError(http.StatusBadRequest, passwordconsts.InvalidResetToken)
+	}
+
+	if err := svc.userTokenRepo.ConsumeUserToken(resetToken.ID); err != nil {
+		return utils.NewStatusError(http.StatusBadRequest, passwordconsts.InvalidResetToken)
+	}
+
+	if err := svc.userRepo.UpdatePassword(resetToken.UserID, utils.HashPassword(newPassword)); err != nil {
+		return err
+	}
+
+	if err := svc.userTokenRepo.InvalidateUserTokens(resetToken.UserID, passwordconsts.PasswordResetTokenPurpose); err != nil {
+		return err
+	}
+
+	// Whoever knew the old password may still hold a session
+	return svc.tokenSvc.RevokeAllSessions(resetToken.UserID)
+}
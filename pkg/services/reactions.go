@@ -0,0 +1,300 @@
+package services
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/permissions"
+	"Blog_API/pkg/types"
+	"Blog_API/pkg/utils"
+	blogconsts "Blog_API/pkg/utils/consts/blog"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultReactionTypes seed an empty catalog, under the ids reactions were made with before the
+// catalog existed
+var defaultReactionTypes = []models.ReactionType{
+	{ID: 1, Code: "like", Label: "Like", Emoji: "👍", Position: 1, Enabled: true},
+	{ID: 2, Code: "love", Label: "Love", Emoji: "❤️", Position: 2, Enabled: true},
+	{ID: 3, Code: "care", Label: "Care", Emoji: "🤗", Position: 3, Enabled: true},
+	{ID: 4, Code: "haha", Label: "Haha", Emoji: "😆", Position: 4, Enabled: true},
+	{ID: 5, Code: "wow", Label: "Wow", Emoji: "😮", Position: 5, Enabled: true},
+	{ID: 6, Code: "sad", Label: "Sad", Emoji: "😢", Position: 6, Enabled: true},
+	{ID: 7, Code: "angry", Label: "Angry", Emoji: "😡", Position: 7, Enabled: true},
+}
+
+// SeedReactionTypes fills an empty reaction catalog with the default types. It is safe to run on
+// every start, a catalog with any type in it is left alone.
+func SeedReactionTypes(repo domain.BlogRepository) error {
+
+	seeded, err := repo.SeedReactionTypes(defaultReactionTypes)
+	if err != nil {
+		return err
+	}
+
+	if seeded > 0 {
+		log.Printf("seeded the reaction catalog with %d types", seeded)
+	}
+
+	return nil
+}
+
+// GetReactionTypes implements domain.BlogService.
+func (svc *blogService) GetReactionTypes(includeRetired bool) ([]types.ReactionTypeResp, error) {
+
+	reactionTypes, err := svc.repo.GetReactionTypes(includeRetired)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]types.ReactionTypeResp, 0, len(reactionTypes))
+	for _, reactionType := range reactionTypes {
+		resp = append(resp, convertReactionTypeToResp(reactionType))
+	}
+
+	return resp, nil
+}
+
+// CreateReactionType implements domain.BlogService.
+func (svc *blogService) CreateReactionType(userID string, req types.ReactionTypeRequest) (types.ReactionTypeResp, error) {
+
+	if err := svc.checkReactionManager(userID); err != nil {
+		return types.ReactionTypeResp{}, err
+	}
+
+	code := strings.ToLower(strings.TrimSpace(req.Code))
+	if _, err := svc.repo.GetReactionTypeByCode(code); err == nil {
+		return types.ReactionTypeResp{}, utils.NewStatusError(http.StatusConflict, blogconsts.ReactionCodeTaken)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return types.ReactionTypeResp{}, err
+	}
+
+	reactionType := models.ReactionType{
+		Code:    code,
+		Label:   strings.TrimSpace(req.Label),
+		Emoji:   strings.TrimSpace(req.Emoji),
+		Enabled: req.Enabled == nil || *req.Enabled,
+	}
+
+	if req.Position != nil {
+		reactionType.Position = *req.Position
+	} else {
+		reactionTypes, err := svc.repo.GetReactionTypes(true)
+		if err != nil {
+			return types.ReactionTypeResp{}, err
+		}
+		for _, other := range reactionTypes {
+			if other.Position >= reactionType.Position {
+				reactionType.Position = other.Position + 1
+			}
+		}
+	}
+
+	reactionType, err := svc.repo.CreateReactionType(reactionType)
+	if err != nil {
+		return types.ReactionTypeResp{}, err
+	}
+
+	return convertReactionTypeToResp(reactionType), nil
+}
+
+// UpdateReactionType implements domain.BlogService.
+func (svc *blogService) UpdateReactionType(userID string, reaction string, req types.UpdateReactionTypeRequest) (types.ReactionTypeResp, error) {
+
+	if err := svc.checkReactionManager(userID); err != nil {
+		return types.ReactionTypeResp{}, err
+	}
+
+	reactionType, err := svc.resolveReactionType(reaction)
+	if err != nil {
+		return types.ReactionTypeResp{}, err
+	}
+
+	if req.Label != nil {
+		reactionType.Label = strings.TrimSpace(*req.Label)
+	}
+	if req.Emoji != nil {
+		reactionType.Emoji = strings.TrimSpace(*req.Emoji)
+	}
+	if req.Position != nil {
+		reactionType.Position = *req.Position
+	}
+	if req.Enabled != nil {
+		reactionType.Enabled = *req.Enabled
+	}
+
+	if err := svc.repo.UpdateReactionType(reactionType); err != nil {
+		return types.ReactionTypeResp{}, err
+	}
+
+	return convertReactionTypeToResp(reactionType), nil
+}
+
+// RetireReactionType implements domain.BlogService.
+// The type is only disabled, the reactions made with it and their counts stay.
+func (svc *blogService) RetireReactionType(userID string, reaction string) (types.ReactionTypeResp, error) {
+	enabled := false
+	return svc.UpdateReactionType(userID, reaction, types.UpdateReactionTypeRequest{Enabled: &enabled})
+}
+
+// GetReactors implements domain.BlogService.
+func (svc *blogService) GetReactors(viewerID string, blogID string, reaction string, query types.ReactorListQuery) (types.ReactorPage, error) {
+
+	// Reactions of a retired type are still listed
+	reactionType, err := svc.resolveReactionType(reaction)
+	if err != nil {
+		return types.ReactorPage{}, err
+	}
+
+	blogPost, err := svc.repo.GetBlogPost(blogID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return types.ReactorPage{}, utils.NewStatusError(http.StatusNotFound, blogconsts.BlogNotFound)
+		}
+		return types.ReactorPage{}, err
+	}
+
+	// Who reacted to a post is seen by whoever can see the post
+	if err := svc.checkVisible(viewerID, blogPost); err != nil {
+		return types.ReactorPage{}, err
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = blogconsts.DefaultPageSize
+	}
+	if limit > blogconsts.MaxPageSize {
+		limit = blogconsts.MaxPageSize
+	}
+
+	reactors, total, err := svc.repo.ListReactors(blogPost.ID, reactionType.ID, query.Offset, limit)
+	if err != nil {
+		return types.ReactorPage{}, err
+	}
+
+	return types.ReactorPage{
+		Items:  convertReactorsToResp(reactors),
+		Total:  total,
+		Offset: query.Offset,
+		Limit:  limit,
+	}, nil
+}
+
+// resolveReactionType finds a type of the catalog by its id or its code, retired types included
+func (svc *blogService) resolveReactionType(value string) (models.ReactionType, error) {
+
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	var reactionType models.ReactionType
+	var err error
+	if id, parseErr := strconv.ParseUint(value, 10, 64); parseErr == nil {
+		reactionType, err = svc.repo.GetReactionType(id)
+	} else {
+		reactionType, err = svc.repo.GetReactionTypeByCode(value)
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ReactionType{}, utils.NewStatusError(http.StatusBadRequest, blogconsts.InvalidReactionID)
+		}
+		return models.ReactionType{}, err
+	}
+
+	return reactionType, nil
+}
+
+// checkReactable lets a user react with an enabled type. A retired type can only be taken back by
+// the users that reacted with it, reacting with the same type again removes the reaction.
+func checkReactable(reactionType models.ReactionType, blogPost models.BlogPost, userID string) error {
+
+	if reactionType.Enabled {
+		return nil
+	}
+
+	for _, reaction := range blogPost.Reactions {
+		if reaction.UserID == userID && reaction.Type == reactionType.ID {
+			return nil
+		}
+	}
+
+	return utils.NewStatusError(http.StatusBadRequest, fmt.Sprintf(blogconsts.ReactionTypeRetired, reactionType.Code))
+}
+
+func (svc *blogService) checkReactionManager(userID string) error {
+
+	user, err := svc.uSvc.GetUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if !svc.can(user, permissions.ReactionManage) {
+		return utils.NewStatusError(http.StatusForbidden, blogconsts.YouAreNotAuthorizedToManageReactions)
+	}
+
+	return nil
+}
+
+// setViewerReaction picks the reaction of the viewer out of the reactions of the post. It is
+// applied after the cache, the cached post is the same for every viewer.
+func setViewerReaction(blogResp *types.BlogResp, viewerID string) {
+
+	blogResp.MyReaction = nil
+	if viewerID == "" {
+		return
+	}
+
+	for i := range blogResp.Reactions {
+		if blogResp.Reactions[i].UserID == viewerID {
+			reaction := blogResp.Reactions[i]
+			blogResp.MyReaction = &reaction
+			return
+		}
+	}
+}
+
+// convertReactionCounts keys the counts of the post by the code of their reaction type, or by the
+// id of a type that is missing from the catalog
+func convertReactionCounts(counts []models.BlogPostReactionCount) map[string]uint {
+	resp := make(map[string]uint, len(counts))
+	for _, count := range counts {
+		if count.Count == 0 {
+			continue
+		}
+		code := count.Code
+		if code == "" {
+			code = strconv.FormatUint(count.Type, 10)
+		}
+		resp[code] = count.Count
+	}
+	return resp
+}
+
+func convertReactionTypeToResp(reactionType models.ReactionType) types.ReactionTypeResp {
+	return types.ReactionTypeResp{
+		ID:       reactionType.ID,
+		Code:     reactionType.Code,
+		Label:    reactionType.Label,
+		Emoji:    reactionType.Emoji,
+		Position: reactionType.Position,
+		Enabled:  reactionType.Enabled,
+	}
+}
+
+func convertReactorsToResp(reactors []models.Reactor) []types.ReactorResp {
+	resp := make([]types.ReactorResp, 0, len(reactors))
+	for _, reactor := range reactors {
+		resp = append(resp, types.ReactorResp{
+			UserID:      reactor.UserID,
+			DisplayName: strings.TrimSpace(reactor.FirstName + " " + reactor.LastName),
+			Type:        reactor.Type,
+			Code:        reactor.Code,
+			ReactedAt:   reactor.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return resp
+}
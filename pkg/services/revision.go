@@ -0,0 +1,234 @@
+package services
+
+import (
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/permissions"
+	"Blog_API/pkg/types"
+	"Blog_API/pkg/utils"
+	blogconsts "Blog_API/pkg/utils/consts/blog"
+	"errors"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+// GetBlogRevisions implements domain.BlogService.
+func (svc *blogService) GetBlogRevisions(userID string, blogID string) ([]types.BlogRevisionResp, error) {
+
+	if _, err := svc.revisionsOf(userID, blogID, blogconsts.YouAreNotAuthorizedToSeeRevisions); err != nil {
+		return nil, err
+	}
+
+	revisions, err := svc.repo.GetBlogRevisions(blogID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The list only tells what happened, the text is fetched one revision at a time
+	revisionsResp := make([]types.BlogRevisionResp, 0, len(revisions))
+	for _, revision := range revisions {
+		revisionsResp = append(revisionsResp, types.BlogRevisionResp{
+			Number:       revision.Number,
+			BlogPostID:   revision.BlogPostID,
+			AuthorID:     revision.AuthorID,
+			Action:       revision.Action,
+			RestoredFrom: revision.RestoredFrom,
+			Title:        revision.Title,
+			CreatedAt:    revision.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return revisionsResp, nil
+}
+
+// GetBlogRevision implements domain.BlogService.
+func (svc *blogService) GetBlogRevision(userID string, blogID string, number uint) (types.BlogRevisionResp, error) {
+
+	if _, err := svc.revisionsOf(userID, blogID, blogconsts.YouAreNotAuthorizedToSeeRevisions); err != nil {
+		return types.BlogRevisionResp{}, err
+	}
+
+	revision, err := svc.getRevision(blogID, number)
+	if err != nil {
+		return types.BlogRevisionResp{}, err
+	}
+
+	return convertBlogRevisionToResp(revision), nil
+}
+
+// DiffBlogRevisions implements domain.BlogService.
+func (svc *blogService) DiffBlogRevisions(userID string, blogID string, from uint, to uint) (types.BlogRevisionDiffResp, error) {
+
+	if _, err := svc.revisionsOf(userID, blogID, blogconsts.YouAreNotAuthorizedToSeeRevisions); err != nil {
+		return types.BlogRevisionDiffResp{}, err
+	}
+
+	fromRevision, err := svc.getRevision(blogID, from)
+	if err != nil {
+		return types.BlogRevisionDiffResp{}, err
+	}
+
+	toRevision, err := svc.getRevision(blogID, to)
+	if err != nil {
+		return types.BlogRevisionDiffResp{}, err
+	}
+
+	diffResp := types.BlogRevisionDiffResp{
+		BlogPostID: blogID,
+		From:       from,
+		To:         to,
+		Fields:     []types.FieldDiffResp{},
+	}
+
+	fields := []struct {
+		name     string
+		from, to string
+	}{
+		{"title", fromRevision.Title, toRevision.Title},
+		{"description", fromRevision.Description, toRevision.Description},
+		{"content_text", fromRevision.ContentText, toRevision.ContentText},
+		{"category", fromRevision.Category, toRevision.Category},
+		{"photo_url", fromRevision.PhotoURL, toRevision.PhotoURL},
+	}
+
+	for _, field := range fields {
+		if field.from == field.to {
+			continue
+		}
+
+		fieldDiff := types.FieldDiffResp{Field: field.name}
+		for _, line := range utils.LineDiff(field.from, field.to) {
+			switch line.Op {
+			case utils.DiffInsert:
+				fieldDiff.Added++
+			case utils.DiffDelete:
+				fieldDiff.Removed++
+			}
+			fieldDiff.Lines = append(fieldDiff.Lines, types.DiffLineResp{
+				Op:       line.Op,
+				Text:     line.Text,
+				FromLine: line.FromLine,
+				ToLine:   line.ToLine,
+			})
+		}
+		diffResp.Fields = append(diffResp.Fields, fieldDiff)
+	}
+
+	return diffResp, nil
+}
+
+// RestoreBlogRevision implements domain.BlogService.
+func (svc *blogService) RestoreBlogRevision(userID string, blogID string, version uint, number uint) (types.BlogResp, error) {
+
+	blogPost, err := svc.revisionsOf(userID, blogID, blogconsts.YouAreNotAuthorizedToUpdateThisBlog)
+	if err != nil {
+		return types.BlogResp{}, err
+	}
+
+	if err := utils.CheckVersion(version, blogPost.Version); err != nil {
+		return types.BlogResp{}, err
+	}
+
+	revision, err := svc.getRevision(blogID, number)
+	if err != nil {
+		return types.BlogResp{}, err
+	}
+
+	slug := blogPost.Slug
+	if slug == "" || revision.Title != blogPost.Title {
+		slug, err = newBlogSlug(svc.repo, revision.Title, blogPost.ID)
+		if err != nil {
+			return types.BlogResp{}, err
+		}
+	}
+
+	restored := models.BlogPost{
+		ID:          blogPost.ID,
+		Title:       revision.Title,
+		Slug:        slug,
+		ContentText: revision.ContentText,
+		PhotoURL:    revision.PhotoURL,
+		Description: revision.Description,
+		CategoryID:  blogPost.CategoryID,
+		Category:    blogPost.Category,
+		Version:     blogPost.Version,
+	}
+
+	// A revision from before a category was renamed or deleted keeps the post where it is now
+	if category, err := svc.resolveCategory(revision.Category); err == nil {
+		restored.CategoryID, restored.Category = &category.ID, category.Slug
+	}
+
+	// Restoring writes a new revision, the history in between stays as it is
+	restoredFrom := revision.Number
+	if err := svc.repo.RestoreBlogRevision(restored, models.BlogRevision{AuthorID: userID, Action: blogconsts.RevisionRestore, RestoredFrom: &restoredFrom}); err != nil {
+		return types.BlogResp{}, err
+	}
+
+	if blogPost.Slug != "" && blogPost.Slug != slug {
+		if err := svc.repo.RecordSlugChange(blogPost.ID, blogPost.Slug, slug); err != nil {
+			return types.BlogResp{}, err
+		}
+	}
+
+	blogPost, err = svc.repo.GetBlogPost(blogID)
+	if err != nil {
+		return types.BlogResp{}, err
+	}
+	svc.indexPost(blogPost)
+
+	return convertBlogPostToBlogResp(blogPost), nil
+}
+
+// revisionsOf loads the post and checks that the user may work on its history, which is as private as editing it
+func (svc *blogService) revisionsOf(userID string, blogID string, forbidden string) (models.BlogPost, error) {
+
+	user, err := svc.uSvc.GetUser(userID)
+	if err != nil {
+		return models.BlogPost{}, err
+	}
+
+	blogPost, err := svc.repo.GetBlogPost(blogID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.BlogPost{}, utils.NewStatusError(http.StatusNotFound, blogconsts.BlogNotFound)
+		}
+		return models.BlogPost{}, err
+	}
+
+	if !svc.isAllowed(user, blogPost.UserID, permissions.BlogUpdateOwn, permissions.BlogUpdateAny) {
+		return models.BlogPost{}, utils.NewStatusError(http.StatusForbidden, forbidden)
+	}
+
+	return blogPost, nil
+}
+
+func (svc *blogService) getRevision(blogID string, number uint) (models.BlogRevision, error) {
+
+	revision, err := svc.repo.GetBlogRevision(blogID, number)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.BlogRevision{}, utils.NewStatusError(http.StatusNotFound, blogconsts.RevisionNotFound)
+		}
+		return models.BlogRevision{}, err
+	}
+
+	return revision, nil
+}
+
+func convertBlogRevisionToResp(revision models.BlogRevision) types.BlogRevisionResp {
+	return types.BlogRevisionResp{
+		Number:       revision.Number,
+		BlogPostID:   revision.BlogPostID,
+		AuthorID:     revision.AuthorID,
+		Action:       revision.Action,
+		RestoredFrom: revision.RestoredFrom,
+		Title:        revision.Title,
+		Slug:         revision.Slug,
+		ContentText:  revision.ContentText,
+		PhotoURL:     revision.PhotoURL,
+		Description:  revision.Description,
+		Category:     revision.Category,
+		CreatedAt:    revision.CreatedAt.Format(time.RFC3339),
+	}
+}
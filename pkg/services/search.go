@@ -0,0 +1,137 @@
+package services
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/poststatus"
+	"Blog_API/pkg/search"
+	"Blog_API/pkg/types"
+	"Blog_API/pkg/utils"
+	blogconsts "Blog_API/pkg/utils/consts/blog"
+	"errors"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+)
+
+// SearchBlogPosts implements domain.BlogService.
+func (svc *blogService) SearchBlogPosts(query types.SearchQuery) (types.SearchPage, error) {
+
+	parsed, err := search.ParseQuery(query.Q)
+	if err != nil {
+		return types.SearchPage{}, utils.NewStatusError(http.StatusBadRequest, err.Error())
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = blogconsts.DefaultPageSize
+	}
+	if limit > blogconsts.MaxPageSize {
+		limit = blogconsts.MaxPageSize
+	}
+
+	hits, total, err := svc.index.Search(parsed, query.Offset, limit)
+	if err != nil {
+		return types.SearchPage{}, err
+	}
+
+	page := types.SearchPage{
+		Items:  make([]types.SearchHitResp, 0, len(hits)),
+		Total:  total,
+		Offset: query.Offset,
+		Limit:  limit,
+	}
+	for _, hit := range hits {
+		page.Items = append(page.Items, types.SearchHitResp{
+			ID:         hit.ID,
+			Slug:       hit.Slug,
+			Title:      hit.Title,
+			Score:      hit.Score,
+			Highlights: hit.Highlights,
+		})
+	}
+
+	return page, nil
+}
+
+// reindex brings the search index up to date with a post after it was written. The write itself
+// already succeeded, so a failing index is logged rather than reported.
+func (svc *blogService) reindex(blogID string) {
+
+	blogPost, err := svc.repo.GetBlogPost(blogID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			svc.unindex(blogID)
+			return
+		}
+		log.Printf("error loading blog post %s for the search index: %v", blogID, err)
+		return
+	}
+
+	svc.indexPost(blogPost)
+}
+
+// indexPost makes a published post searchable and takes any other out of the index
+func (svc *blogService) indexPost(blogPost models.BlogPost) {
+
+	if poststatus.Normalize(blogPost.Status) != poststatus.Published {
+		svc.unindex(blogPost.ID)
+		return
+	}
+
+	if err := svc.index.Index(searchDocument(blogPost)); err != nil {
+		log.Printf("error indexing blog post %s: %v", blogPost.ID, err)
+	}
+}
+
+func (svc *blogService) unindex(blogID string) {
+	if err := svc.index.Remove(blogID); err != nil {
+		log.Printf("error removing blog post %s from the search index: %v", blogID, err)
+	}
+}
+
+// BuildSearchIndex fills an index that starts out empty, such as the in-memory one, with every published post
+func BuildSearchIndex(repo domain.BlogRepository, index domain.SearchIndex) error {
+
+	indexed := 0
+	afterID := ""
+	for {
+		blogPosts, err := repo.GetPublishedBlogPosts(afterID, blogconsts.SearchIndexBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, blogPost := range blogPosts {
+			if err := index.Index(searchDocument(blogPost)); err != nil {
+				return err
+			}
+		}
+		indexed += len(blogPosts)
+
+		if len(blogPosts) < blogconsts.SearchIndexBatchSize {
+			break
+		}
+		afterID = blogPosts[len(blogPosts)-1].ID
+	}
+
+	log.Printf("indexed %d blog posts for search", indexed)
+
+	return nil
+}
+
+func searchDocument(blogPost models.BlogPost) search.Document {
+
+	comments := make([]string, len(blogPost.Comments))
+	for i, comment := range blogPost.Comments {
+		comments[i] = comment.Content
+	}
+
+	return search.Document{
+		ID:          blogPost.ID,
+		Slug:        blogPost.Slug,
+		Title:       blogPost.Title,
+		Description: blogPost.Description,
+		Content:     blogPost.ContentText,
+		Comments:    comments,
+	}
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/utils"
+	blogconsts "Blog_API/pkg/utils/consts/blog"
+	"fmt"
+	"log"
+)
+
+// newBlogSlug returns the slug of the title, suffixed with -2, -3, ... when another post already uses it
+func newBlogSlug(repo domain.BlogRepository, title string, blogID string) (string, error) {
+
+	base := utils.Slugify(title)
+	if base == "" {
+		base = blogconsts.DefaultSlug
+	}
+
+	slug := base
+	for i := 2; i <= blogconsts.MaxSlugCollisions; i++ {
+		taken, err := repo.IsSlugTaken(slug, blogID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	return "", fmt.Errorf("no free slug for %q", base)
+}
+
+// BackfillBlogSlugs gives a slug to posts created before posts had one, it is safe to run on every start
+func BackfillBlogSlugs(repo domain.BlogRepository) error {
+
+	blogPosts, err := repo.GetBlogPostsWithoutSlug()
+	if err != nil {
+		return err
+	}
+
+	for _, blogPost := range blogPosts {
+		slug, err := newBlogSlug(repo, blogPost.Title, blogPost.ID)
+		if err != nil {
+			return err
+		}
+		if err := repo.SetBlogPostSlug(blogPost.ID, slug); err != nil {
+			return err
+		}
+	}
+
+	if len(blogPosts) > 0 {
+		log.Printf("backfilled slugs of %d blog posts", len(blogPosts))
+	}
+
+	return nil
+}
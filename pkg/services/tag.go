@@ -0,0 +1,199 @@
+package services
+
+import (
+	"Blog_API/pkg/listing"
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/permissions"
+	"Blog_API/pkg/types"
+	"Blog_API/pkg/utils"
+	blogconsts "Blog_API/pkg/utils/consts/blog"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// GetBlogPostsByTags implements domain.BlogService.
+func (svc *blogService) GetBlogPostsByTags(query types.BlogListQuery) (types.BlogPage, error) {
+
+	if strings.TrimSpace(query.Tags) == "" {
+		return types.BlogPage{}, utils.NewStatusError(http.StatusBadRequest, blogconsts.TagsRequired)
+	}
+
+	return svc.GetBlogPosts(query)
+}
+
+// GetTags implements domain.BlogService.
+func (svc *blogService) GetTags(query types.TagListQuery) (types.TagPage, error) {
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = blogconsts.DefaultPageSize
+	}
+	if limit > blogconsts.MaxPageSize {
+		limit = blogconsts.MaxPageSize
+	}
+
+	tags, total, err := svc.repo.ListTags(utils.NormalizeTag(query.Q), query.Offset, limit)
+	if err != nil {
+		return types.TagPage{}, err
+	}
+
+	return types.TagPage{
+		Items:  convertTagsToResp(tags),
+		Total:  total,
+		Offset: query.Offset,
+		Limit:  limit,
+	}, nil
+}
+
+// SuggestTags implements domain.BlogService.
+func (svc *blogService) SuggestTags(prefix string, limit int) ([]types.TagResp, error) {
+
+	// Whatever the user typed is matched the way it would be stored
+	prefix = utils.NormalizeTag(prefix)
+	if prefix == "" {
+		return []types.TagResp{}, nil
+	}
+
+	if limit <= 0 {
+		limit = blogconsts.DefaultSuggestionSize
+	}
+	if limit > blogconsts.MaxSuggestionSize {
+		limit = blogconsts.MaxSuggestionSize
+	}
+
+	tags, _, err := svc.repo.ListTags(prefix, 0, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertTagsToResp(tags), nil
+}
+
+// MergeTags implements domain.BlogService.
+func (svc *blogService) MergeTags(userID string, req types.MergeTagsRequest) (types.MergeTagsResp, error) {
+
+	user, err := svc.uSvc.GetUser(userID)
+	if err != nil {
+		return types.MergeTagsResp{}, err
+	}
+
+	if !svc.can(user, permissions.TagManage) {
+		return types.MergeTagsResp{}, utils.NewStatusError(http.StatusForbidden, blogconsts.YouAreNotAuthorizedToManageTags)
+	}
+
+	into, err := normalizeTags([]string{req.Into})
+	if err != nil {
+		return types.MergeTagsResp{}, err
+	}
+
+	from, err := normalizeTags(req.From)
+	if err != nil {
+		return types.MergeTagsResp{}, err
+	}
+
+	// Spellings that already normalize to the target have nothing to merge
+	names := make([]string, 0, len(from))
+	for _, name := range from {
+		if name != into[0] {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return types.MergeTagsResp{}, utils.NewStatusError(http.StatusBadRequest, blogconsts.NothingToMerge)
+	}
+
+	merged, blogIDs, err := svc.repo.MergeTags(names, into[0])
+	if err != nil {
+		return types.MergeTagsResp{}, err
+	}
+
+	tag, err := svc.repo.GetTag(into[0])
+	if err != nil {
+		return types.MergeTagsResp{}, err
+	}
+
+	return types.MergeTagsResp{
+		Tag:     types.TagResp{Name: tag.Name, PostsCount: tag.PostsCount},
+		Merged:  merged,
+		BlogIDs: blogIDs,
+	}, nil
+}
+
+// normalizeTags normalizes the tag names of a request and drops the duplicates this makes
+func normalizeTags(names []string) ([]string, error) {
+
+	normalized := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		tag := utils.NormalizeTag(name)
+		if tag == "" {
+			return nil, utils.NewStatusError(http.StatusBadRequest, fmt.Sprintf(blogconsts.InvalidTag, name))
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+
+	return normalized, nil
+}
+
+// tagFilter reads the comma separated tags of a listing query
+func tagFilter(query types.BlogListQuery) ([]string, string, error) {
+
+	if strings.TrimSpace(query.Tags) == "" {
+		return nil, "", nil
+	}
+
+	tags, err := normalizeTags(strings.Split(query.Tags, ","))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(tags) > blogconsts.MaxTagsInFilter {
+		return nil, "", utils.NewStatusError(http.StatusBadRequest, fmt.Sprintf(blogconsts.TooManyTagsInFilter, blogconsts.MaxTagsInFilter))
+	}
+
+	match := query.Match
+	if match == "" {
+		match = listing.MatchAny
+	}
+
+	return tags, match, nil
+}
+
+// tagModels are the tags of the given names as the repository writes them, nil when names is nil
+func tagModels(names []string) []models.Tag {
+
+	if names == nil {
+		return nil
+	}
+
+	tags := make([]models.Tag, len(names))
+	for i, name := range names {
+		tags[i] = models.Tag{Name: name}
+	}
+
+	return tags
+}
+
+// tagNames are the names of the tags of a post in alphabetical order
+func tagNames(tags []models.Tag) []string {
+
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func convertTagsToResp(tags []models.Tag) []types.TagResp {
+	resp := make([]types.TagResp, 0, len(tags))
+	for _, tag := range tags {
+		resp = append(resp, types.TagResp{Name: tag.Name, PostsCount: tag.PostsCount})
+	}
+	return resp
+}
@@ -0,0 +1,322 @@
+package services
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/permissions"
+	"Blog_API/pkg/signing"
+	"Blog_API/pkg/types"
+	"Blog_API/pkg/utils"
+	tokenconsts "Blog_API/pkg/utils/consts/token"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+)
+
+// Parent struct to implement interface binding
+type tokenService struct {
+	repo            domain.TokenRepository
+	userRepo        domain.Repository
+	revocationStore domain.RevocationStore
+}
+
+// Interface binding
+func NewTokenService(repo domain.TokenRepository, userRepo domain.Repository, revocationStore domain.RevocationStore) domain.TokenService {
+	return &tokenService{
+		repo:            repo,
+		userRepo:        userRepo,
+		revocationStore: revocationStore,
+	}
+}
+
+// IssueTokenPair implements domain.TokenService.
+func (svc *tokenService) IssueTokenPair(userID string, device types.DeviceInfo) (types.TokenResp, error) {
+
+	user, err := svc.userRepo.GetUser(userID)
+	if err != nil {
+		return types.TokenResp{}, err
+	}
+
+	now := time.Now().UTC()
+	sessionID := uuid.NewString()
+
+	refreshToken, refreshTokenString, err := newRefreshToken(userID, sessionID, now, device)
+	if err != nil {
+		return types.TokenResp{}, err
+	}
+	refreshToken.SessionCreatedAt = now
+
+	accessToken, accessTokenID, accessExpiresAt, err := generateAccessToken(user, sessionID, now)
+	if err != nil {
+		return types.TokenResp{}, err
+	}
+	refreshToken.AccessTokenID = accessTokenID
+	refreshToken.AccessTokenExpiresAt = accessExpiresAt
+
+	if err := svc.repo.CreateRefreshToken(refreshToken); err != nil {
+		return types.TokenResp{}, err
+	}
+
+	return convertToTokenResp(accessToken, accessExpiresAt, refreshTokenString, refreshToken), nil
+}
+
+// Refresh implements domain.TokenService.
+func (svc *tokenService) Refresh(refreshTokenString string, device types.DeviceInfo) (types.TokenResp, error) {
+
+	existingToken, err := svc.repo.GetRefreshTokenByHash(utils.HashToken(refreshTokenString))
+	if err != nil {
+		return types.TokenResp{}, utils.NewStatusError(http.StatusUnauthorized, tokenconsts.InvalidRefreshToken)
+	}
+
+	// A rotated or revoked token being presented again means it leaked, so the whole session goes
+	if existingToken.RevokedAt != nil || existingToken.ReplacedByID != "" {
+		if err := svc.revokeFamily(existingToken.FamilyID); err != nil {
+			return types.TokenResp{}, err
+		}
+		return types.TokenResp{}, utils.NewStatusError(http.StatusUnauthorized, tokenconsts.RefreshTokenReused)
+	}
+
+	if existingToken.DeviceID != "" && existingToken.DeviceID != device.DeviceID {
+		if err := svc.revokeFamily(existingToken.FamilyID); err != nil {
+			return types.TokenResp{}, err
+		}
+		return types.TokenResp{}, utils.NewStatusError(http.StatusUnauthorized, tokenconsts.DeviceMismatch)
+	}
+
+	now := time.Now().UTC()
+	if !existingToken.ExpiresAt.After(now) {
+		return types.TokenResp{}, utils.NewStatusError(http.StatusUnauthorized, tokenconsts.RefreshTokenExpired)
+	}
+
+	// The user may have been deleted since the session started
+	user, err := svc.userRepo.GetUser(existingToken.UserID)
+	if err != nil {
+		return types.TokenResp{}, utils.NewStatusError(http.StatusUnauthorized, tokenconsts.InvalidRefreshToken)
+	}
+
+	device.DeviceID = existingToken.DeviceID
+	refreshToken, refreshTokenString, err := newRefreshToken(existingToken.UserID, existingToken.FamilyID, now, device)
+	if err != nil {
+		return types.TokenResp{}, err
+	}
+	refreshToken.SessionCreatedAt = existingToken.SessionCreatedAt
+
+	accessToken, accessTokenID, accessExpiresAt, err := generateAccessToken(user, existingToken.FamilyID, now)
+	if err != nil {
+		return types.TokenResp{}, err
+	}
+	refreshToken.AccessTokenID = accessTokenID
+	refreshToken.AccessTokenExpiresAt = accessExpiresAt
+
+	if rotateErr := svc.repo.RotateRefreshToken(existingToken.ID, refreshToken); rotateErr != nil {
+		if rotateErr.Error() == tokenconsts.RefreshTokenReused {
+			if err := svc.revokeFamily(existingToken.FamilyID); err != nil {
+				return types.TokenResp{}, err
+			}
+			return types.TokenResp{}, utils.NewStatusError(http.StatusUnauthorized, tokenconsts.RefreshTokenReused)
+		}
+		return types.TokenResp{}, rotateErr
+	}
+
+	// The access token paired with the rotated refresh token is superseded
+	if existingToken.AccessTokenExpiresAt.After(now) {
+		if err := svc.revocationStore.Revoke(existingToken.AccessTokenID, existingToken.UserID, existingToken.AccessTokenExpiresAt); err != nil {
+			return types.TokenResp{}, err
+		}
+	}
+
+	return convertToTokenResp(accessToken, accessExpiresAt, refreshTokenString, refreshToken), nil
+}
+
+// GetSessions implements domain.TokenService.
+func (svc *tokenService) GetSessions(userID string, currentSessionID string) ([]types.SessionResp, error) {
+
+	sessions := []types.SessionResp{}
+
+	tokens, err := svc.repo.GetActiveRefreshTokensOfUser(userID)
+	if err != nil {
+		return sessions, err
+	}
+
+	for _, token := range tokens {
+		sessions = append(sessions, types.SessionResp{
+			SessionID:  token.FamilyID,
+			DeviceID:   token.DeviceID,
+			UserAgent:  token.UserAgent,
+			IPAddress:  token.IPAddress,
+			CreatedAt:  token.SessionCreatedAt,
+			LastUsedAt: token.CreatedAt,
+			ExpiresAt:  token.ExpiresAt,
+			Current:    token.FamilyID == currentSessionID,
+		})
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession implements domain.TokenService.
+func (svc *tokenService) RevokeSession(userID string, sessionID string) error {
+
+	tokens, err := svc.repo.GetRefreshTokensOfFamily(sessionID)
+	if err != nil {
+		return err
+	}
+
+	if len(tokens) == 0 || tokens[0].UserID != userID {
+		return utils.NewStatusError(http.StatusNotFound, tokenconsts.SessionNotFound)
+	}
+
+	return svc.revokeFamily(sessionID)
+}
+
+// RevokeAllSessions implements domain.TokenService.
+func (svc *tokenService) RevokeAllSessions(userID string) error {
+
+	tokens, err := svc.repo.GetActiveRefreshTokensOfUser(userID)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		if err := svc.revokeFamily(token.FamilyID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IssueChallengeToken implements domain.TokenService.
+func (svc *tokenService) IssueChallengeToken(userID string, purpose string, ttl time.Duration) (string, time.Time, error) {
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(ttl)
+
+	jwtClaims := types.JWTClaims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        uuid.NewString(),
+			ExpiresAt: expiresAt.Unix(),
+			IssuedAt:  now.Unix(),
+			NotBefore: now.Unix(),
+		},
+		UserID:  userID,
+		Purpose: purpose,
+	}
+
+	tokenString, err := signing.LocalKeySet.Sign(jwtClaims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expiresAt, nil
+}
+
+// ParseChallengeToken implements domain.TokenService.
+func (svc *tokenService) ParseChallengeToken(tokenString string, purpose string) (types.JWTClaims, error) {
+
+	var claims types.JWTClaims
+
+	token, err := jwt.ParseWithClaims(tokenString, &claims, signing.LocalKeySet.Keyfunc)
+	if err != nil || !token.Valid || claims.Id == "" || claims.Purpose != purpose {
+		return types.JWTClaims{}, utils.NewStatusError(http.StatusUnauthorized, tokenconsts.InvalidChallengeToken)
+	}
+
+	revoked, err := svc.revocationStore.IsRevoked(claims.Id)
+	if err != nil {
+		return types.JWTClaims{}, err
+	}
+	if revoked {
+		return types.JWTClaims{}, utils.NewStatusError(http.StatusUnauthorized, tokenconsts.InvalidChallengeToken)
+	}
+
+	return claims, nil
+}
+
+// ConsumeChallengeToken implements domain.TokenService.
+func (svc *tokenService) ConsumeChallengeToken(claims types.JWTClaims) error {
+	return svc.revocationStore.Revoke(claims.Id, claims.UserID, time.Unix(claims.ExpiresAt, 0))
+}
+
+// revokeFamily revokes every refresh token of a session and the access tokens still valid in it
+func (svc *tokenService) revokeFamily(familyID string) error {
+
+	tokens, err := svc.repo.GetRefreshTokensOfFamily(familyID)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.repo.RevokeFamily(familyID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, token := range tokens {
+		if token.AccessTokenID == "" || !token.AccessTokenExpiresAt.After(now) {
+			continue
+		}
+		if err := svc.revocationStore.Revoke(token.AccessTokenID, token.UserID, token.AccessTokenExpiresAt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newRefreshToken(userID string, familyID string, now time.Time, device types.DeviceInfo) (models.RefreshToken, string, error) {
+
+	tokenString, err := utils.GenerateRandomToken(tokenconsts.RefreshTokenBytes)
+	if err != nil {
+		return models.RefreshToken{}, "", err
+	}
+
+	token := models.RefreshToken{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(tokenString),
+		DeviceID:  device.DeviceID,
+		UserAgent: device.UserAgent,
+		IPAddress: device.IPAddress,
+		ExpiresAt: now.Add(tokenconsts.RefreshTokenTTL),
+	}
+
+	return token, tokenString, nil
+}
+
+func generateAccessToken(user models.User, sessionID string, now time.Time) (string, string, time.Time, error) {
+
+	tokenID := uuid.NewString()
+	expiresAt := now.Add(tokenconsts.AccessTokenTTL)
+
+	jwtClaims := types.JWTClaims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        tokenID,
+			ExpiresAt: expiresAt.Unix(),
+			IssuedAt:  now.Unix(),
+			NotBefore: now.Unix(),
+		},
+		UserID:    user.ID,
+		UserEmail: user.Email,
+		SessionID: sessionID,
+		Role:      permissions.Normalize(user.Role),
+	}
+
+	tokenString, tokenErr := signing.LocalKeySet.Sign(jwtClaims)
+	if tokenErr != nil {
+		return "", "", time.Time{}, tokenErr
+	}
+
+	return tokenString, tokenID, expiresAt, nil
+}
+
+func convertToTokenResp(accessToken string, accessExpiresAt time.Time, refreshTokenString string, refreshToken models.RefreshToken) types.TokenResp {
+	return types.TokenResp{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     refreshTokenString,
+		RefreshExpiresAt: refreshToken.ExpiresAt,
+		TokenType:        tokenconsts.TokenType,
+		SessionID:        refreshToken.FamilyID,
+	}
+}
@@ -3,34 +3,160 @@ package services
 import (
 	"Blog_API/pkg/domain"
 	"Blog_API/pkg/models"
+	"Blog_API/pkg/permissions"
+	"Blog_API/pkg/ratelimit"
 	"Blog_API/pkg/types"
 	"Blog_API/pkg/utils"
 	userconsts "Blog_API/pkg/utils/consts/user"
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"time"
 )
 
 // Parent struct to implement interface binding
 type userService struct {
-	repo domain.Repository
+	repo            domain.Repository
+	revocationStore domain.RevocationStore
+	verificationSvc domain.VerificationService
+	loginPolicy     ratelimit.LoginPolicy
+	ipBackoff       *ratelimit.Backoff
 }
 
 // Interface binding
-func SetUserService(repo domain.Repository) domain.Service {
+func SetUserService(repo domain.Repository, revocationStore domain.RevocationStore, verificationSvc domain.VerificationService, loginPolicy ratelimit.LoginPolicy, ipBackoff *ratelimit.Backoff) domain.Service {
 	return &userService{
-		repo: repo,
+		repo:            repo,
+		revocationStore: revocationStore,
+		verificationSvc: verificationSvc,
+		loginPolicy:     loginPolicy,
+		ipBackoff:       ipBackoff,
 	}
 }
 
 // Login implements domain.Service.
-func (svc *userService) Login(email string, password string) (string, error) {
+func (svc *userService) Login(email string, password string, ipAddress string) (string, error) {
 
-	userID, err := svc.repo.Login(email, password)
+	now := time.Now()
+
+	if wait := svc.ipBackoff.RetryAfter(ipAddress, now); wait > 0 {
+		svc.auditFailedLogin("", email, ipAddress, userconsts.ReasonIPThrottled)
+		return "", tooManyLoginAttempts(wait)
+	}
+
+	user, err := svc.repo.GetUserByEmail(email)
 	if err != nil {
-		return "", errors.New(userconsts.LoginFailed)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			svc.ipBackoff.Fail(ipAddress, now)
+			svc.auditFailedLogin("", email, ipAddress, userconsts.ReasonUnknownEmail)
+			return "", utils.NewStatusError(http.StatusUnauthorized, userconsts.LoginFailed)
+		}
+		return "", err
+	}
+
+	if user.LockedUntil != nil && user.LockedUntil.After(now) {
+		svc.auditFailedLogin(user.ID, email, ipAddress, userconsts.ReasonAccountLocked)
+		return "", utils.NewStatusError(http.StatusLocked, fmt.Sprintf(userconsts.AccountLocked, user.LockedUntil.UTC().Format(time.RFC3339)))
+	}
+
+	// Failures spread out far enough in time start counting from zero again
+	if user.LastFailedLoginAt != nil && now.Sub(*user.LastFailedLoginAt) > svc.loginPolicy.Account.Forget {
+		if err := svc.repo.ResetFailedLogins(user.ID); err != nil {
+			return "", err
+		}
+		user.FailedLoginCount = 0
+		user.LastFailedLoginAt = nil
+	}
+
+	if user.LastFailedLoginAt != nil {
+		if wait := user.LastFailedLoginAt.Add(svc.loginPolicy.Account.Delay(user.FailedLoginCount)).Sub(now); wait > 0 {
+			svc.auditFailedLogin(user.ID, email, ipAddress, userconsts.ReasonAccountThrottled)
+			return "", tooManyLoginAttempts(wait)
+		}
+	}
+
+	if err := utils.ComparePassword(user.Password, password); err != nil {
+		svc.ipBackoff.Fail(ipAddress, now)
+		svc.auditFailedLogin(user.ID, email, ipAddress, userconsts.ReasonWrongPassword)
+
+		failures, recordErr := svc.repo.RecordFailedLogin(user.ID)
+		if recordErr != nil {
+			return "", recordErr
+		}
+
+		if failures >= svc.loginPolicy.LockoutThreshold {
+			if lockErr := svc.repo.LockUser(user.ID, now.Add(svc.loginPolicy.LockoutDuration)); lockErr != nil {
+				return "", lockErr
+			}
+		}
+
+		return "", utils.NewStatusError(http.StatusUnauthorized, userconsts.LoginFailed)
+	}
+
+	if user.FailedLoginCount > 0 || user.LockedUntil != nil {
+		if err := svc.repo.ResetFailedLogins(user.ID); err != nil {
+			return "", err
+		}
+	}
+	svc.ipBackoff.Reset(ipAddress)
+
+	return user.ID, nil
+}
+
+// UnlockUser implements domain.Service.
+func (svc *userService) UnlockUser(userID string) (types.UserResp, error) {
+
+	user, err := svc.repo.GetUser(userID)
+	if err != nil {
+		return types.UserResp{}, err
+	}
+
+	if err := svc.repo.ResetFailedLogins(user.ID); err != nil {
+		return types.UserResp{}, err
+	}
+
+	return convertUserToUserResp(user), nil
+}
+
+// auditFailedLogin stores the audit record of a failed login, a failing audit write does not block the login response
+func (svc *userService) auditFailedLogin(userID string, email string, ipAddress string, reason string) {
+
+	attempt := models.LoginAttempt{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Email:     email,
+		IPAddress: ipAddress,
+		Reason:    reason,
 	}
 
-	return userID, nil
+	if err := svc.repo.CreateLoginAttempt(attempt); err != nil {
+		log.Printf("error recording failed login of %s from %s: %v", email, ipAddress, err)
+	}
+}
+
+func tooManyLoginAttempts(wait time.Duration) error {
+	seconds := int(wait.Round(time.Second).Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	return utils.NewStatusError(http.StatusTooManyRequests, fmt.Sprintf(userconsts.TooManyLoginAttempts, seconds))
+}
+
+// Logout implements domain.Service.
+func (svc *userService) Logout(userID string, tokenID string, expiresAt time.Time) error {
+
+	if tokenID == "" {
+		return errors.New(userconsts.LogoutFailed)
+	}
+
+	if err := svc.revocationStore.Revoke(tokenID, userID, expiresAt); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 // CreateUser implements domain.Service.
@@ -44,11 +170,18 @@ func (svc *userService) CreateUser(reqUser types.SignUpRequest) (types.UserResp,
 		DateOfBirth: reqUser.DateOfBirth,
 		Phone:       reqUser.Phone,
 		Country:     reqUser.Country,
+		Role:        permissions.DefaultRole,
+		Version:     1,
 	}
 	if err := svc.repo.CreateUser(user); err != nil {
 		return types.UserResp{}, err
 	}
 
+	// The account exists at this point, a failed mail can be retried through the resend endpoint
+	if err := svc.verificationSvc.SendVerification(user); err != nil {
+		log.Printf("error sending verification email to user %s: %v", user.ID, err)
+	}
+
 	return convertUserToUserResp(user), nil
 }
 
@@ -96,17 +229,20 @@ func (svc *userService) GetUsers(pagination utils.Page) ([]types.UserResp, error
 }
 
 // UpdateUser implements domain.Service.
-func (svc *userService) UpdateUser(userID string, userReq types.UserUpdateRequest) (types.UserResp, error) {
+func (svc *userService) UpdateUser(userID string, version uint, userReq types.UserUpdateRequest) (types.UserResp, error) {
 
 	user, userErr := svc.repo.GetUser(userID)
 	if userErr != nil {
 		return types.UserResp{}, userErr
 	}
 
+	if err := utils.CheckVersion(version, user.Version); err != nil {
+		return types.UserResp{}, err
+	}
+
 	updateUser := models.User{
 		ID:             user.ID,
 		Email:          user.Email,
-		Password:       utils.HashPassword(userReq.Password),
 		FirstName:      userReq.FirstName,
 		LastName:       userReq.LastName,
 		Gender:         userReq.Gender,
@@ -121,13 +257,110 @@ func (svc *userService) UpdateUser(userID string, userReq types.UserUpdateReques
 		Latitude:       userReq.Latitude,
 		Longitude:      userReq.Longitude,
 		ProfilePicture: userReq.ProfilePicture,
+		Version:        user.Version,
+	}
+
+	// An empty password means "keep it", hashing it would lock the user out
+	if userReq.Password != "" {
+		updateUser.Password = utils.HashPassword(userReq.Password)
 	}
 
 	if err := svc.repo.UpdateUser(updateUser); err != nil {
 		return types.UserResp{}, err
 	}
 
-	return convertUserToUserResp(updateUser), nil
+	// Read back, the update only carries the profile and empty fields of it are not written
+	updatedUser, err := svc.repo.GetUser(user.ID)
+	if err != nil {
+		return types.UserResp{}, err
+	}
+
+	return convertUserToUserResp(updatedUser), nil
+}
+
+// PatchUser implements domain.Service.
+func (svc *userService) PatchUser(userID string, version uint, patch []byte) (types.UserResp, error) {
+
+	user, err := svc.repo.GetUser(userID)
+	if err != nil {
+		return types.UserResp{}, err
+	}
+
+	if err := utils.CheckVersion(version, user.Version); err != nil {
+		return types.UserResp{}, err
+	}
+
+	profile := types.UserProfilePatch{
+		Gender:         user.Gender,
+		DateOfBirth:    user.DateOfBirth,
+		Job:            user.Job,
+		City:           user.City,
+		ZipCode:        user.ZipCode,
+		ProfilePicture: user.ProfilePicture,
+		FirstName:      user.FirstName,
+		LastName:       user.LastName,
+		Phone:          user.Phone,
+		Street:         user.Street,
+		State:          user.State,
+		Country:        user.Country,
+		Latitude:       user.Latitude,
+		Longitude:      user.Longitude,
+	}
+
+	fields, err := utils.ApplyMergePatch(&profile, patch)
+	if err != nil {
+		return types.UserResp{}, err
+	}
+
+	// The merged profile is validated as a whole, not only the fields of the patch
+	if err := profile.Validate(); err != nil {
+		return types.UserResp{}, utils.NewStatusError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	user.Gender = profile.Gender
+	user.DateOfBirth = profile.DateOfBirth
+	user.Job = profile.Job
+	user.City = profile.City
+	user.ZipCode = profile.ZipCode
+	user.ProfilePicture = profile.ProfilePicture
+	user.FirstName = profile.FirstName
+	user.LastName = profile.LastName
+	user.Phone = profile.Phone
+	user.Street = profile.Street
+	user.State = profile.State
+	user.Country = profile.Country
+	user.Latitude = profile.Latitude
+	user.Longitude = profile.Longitude
+
+	if len(fields) > 0 {
+		if err := svc.repo.PatchUser(user, fields); err != nil {
+			return types.UserResp{}, err
+		}
+		user.Version++
+	}
+
+	return convertUserToUserResp(user), nil
+}
+
+// UpdateUserRole implements domain.Service.
+func (svc *userService) UpdateUserRole(userID string, role string) (types.UserResp, error) {
+
+	if !permissions.IsValidRole(role) {
+		return types.UserResp{}, errors.New(userconsts.InvalidRole)
+	}
+
+	user, err := svc.repo.GetUser(userID)
+	if err != nil {
+		return types.UserResp{}, err
+	}
+
+	if err := svc.repo.UpdateUserRole(user.ID, role); err != nil {
+		return types.UserResp{}, err
+	}
+
+	user.Role = role
+
+	return convertUserToUserResp(user), nil
 }
 
 func convertUserToUserResp(user models.User) types.UserResp {
@@ -148,5 +381,9 @@ func convertUserToUserResp(user models.User) types.UserResp {
 		Latitude:       user.Latitude,
 		Longitude:      user.Longitude,
 		ProfilePicture: user.ProfilePicture,
+		Role:           permissions.Normalize(user.Role),
+		EmailVerified:  user.EmailVerified,
+		TOTPEnabled:    user.TOTPEnabled,
+		Version:        user.Version,
 	}
 }
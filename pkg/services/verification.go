@@ -0,0 +1,120 @@
+package services
+
+import (
+	"Blog_API/pkg/domain"
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/utils"
+	verificationconsts "Blog_API/pkg/utils/consts/verification"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Parent struct to implement interface binding
+type verificationService struct {
+	userRepo      domain.Repository
+	userTokenRepo domain.UserTokenRepository
+	mailer        domain.Mailer
+	appBaseURL    string
+}
+
+// Interface binding
+func NewVerificationService(userRepo domain.Repository, userTokenRepo domain.UserTokenRepository, mailer domain.Mailer, appBaseURL string) domain.VerificationService {
+	return &verificationService{
+		userRepo:      userRepo,
+		userTokenRepo: userTokenRepo,
+		mailer:        mailer,
+		appBaseURL:    appBaseURL,
+	}
+}
+
+// SendVerification implements domain.VerificationService.
+func (svc *verificationService) SendVerification(user models.User) error {
+
+	if user.EmailVerified {
+		return utils.NewStatusError(http.StatusConflict, verificationconsts.EmailAlreadyVerified)
+	}
+
+	// Only the most recently mailed link works
+	if err := svc.userTokenRepo.InvalidateUserTokens(user.ID, verificationconsts.VerificationTokenPurpose); err != nil {
+		return err
+	}
+
+	tokenString, err := utils.GenerateRandomToken(verificationconsts.VerificationTokenByteSize)
+	if err != nil {
+		return err
+	}
+
+	verificationToken := models.UserToken{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		Purpose:   verificationconsts.VerificationTokenPurpose,
+		TokenHash: utils.HashToken(tokenString),
+		ExpiresAt: time.Now().Add(verificationconsts.VerificationTokenTTL),
+	}
+
+	if err := svc.userTokenRepo.CreateUserToken(verificationToken); err != nil {
+		return err
+	}
+
+	link := svc.appBaseURL + verificationconsts.VerificationPath + url.QueryEscape(tokenString)
+	body := fmt.Sprintf(verificationconsts.VerificationMailBody, link, tokenString, int(verificationconsts.VerificationTokenTTL.Hours()))
+
+	return svc.mailer.Send(user.Email, verificationconsts.VerificationMailSubject, body)
+}
+
+// ResendVerification implements domain.VerificationService.
+func (svc *verificationService) ResendVerification(userID string) error {
+
+	user, err := svc.userRepo.GetUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerified {
+		return utils.NewStatusError(http.StatusConflict, verificationconsts.EmailAlreadyVerified)
+	}
+
+	latest, err := svc.userTokenRepo.GetLatestUserToken(user.ID, verificationconsts.VerificationTokenPurpose)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if err == nil && time.Since(latest.CreatedAt) < verificationconsts.ResendCooldown {
+		return utils.NewStatusError(http.StatusTooManyRequests, verificationconsts.TooManyVerificationRequests)
+	}
+
+	sent, err := svc.userTokenRepo.CountUserTokensSince(user.ID, verificationconsts.VerificationTokenPurpose, time.Now().Add(-verificationconsts.ResendWindow))
+	if err != nil {
+		return err
+	}
+
+	if sent >= verificationconsts.MaxResendsPerWindow {
+		return utils.NewStatusError(http.StatusTooManyRequests, verificationconsts.TooManyVerificationRequests)
+	}
+
+	return svc.SendVerification(user)
+}
+
+// VerifyEmail implements domain.VerificationService.
+func (svc *verificationService) VerifyEmail(tokenString string) error {
+
+	verificationToken, err := svc.userTokenRepo.GetUserTokenByHash(verificationconsts.VerificationTokenPurpose, utils.HashToken(tokenString))
+	if err != nil {
+		return utils.NewStatusError(http.StatusBadRequest, verificationconsts.InvalidVerificationToken)
+	}
+
+	if verificationToken.UsedAt != nil || !verificationToken.ExpiresAt.After(time.Now()) {
+		return utils.NewStatusError(http.StatusBadRequest, verificationconsts.InvalidVerificationToken)
+	}
+
+	if err := svc.userTokenRepo.ConsumeUserToken(verificationToken.ID); err != nil {
+		return utils.NewStatusError(http.StatusBadRequest, verificationconsts.InvalidVerificationToken)
+	}
+
+	return svc.userRepo.MarkEmailVerified(verificationToken.UserID)
+}
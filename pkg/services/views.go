@@ -0,0 +1,118 @@
+package services
+
+import (
+	"Blog_API/pkg/models"
+	"Blog_API/pkg/poststatus"
+	"Blog_API/pkg/types"
+	"Blog_API/pkg/utils"
+	blogconsts "Blog_API/pkg/utils/consts/blog"
+	"Blog_API/pkg/views"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// RecordView implements domain.BlogService.
+// Only published posts are counted, and neither the author reading their own post nor bots count.
+func (svc *blogService) RecordView(blogPost types.BlogResp, viewer types.Viewer) {
+
+	if blogPost.Status != poststatus.Published || views.IsBot(viewer.UserAgent) {
+		return
+	}
+
+	if viewer.UserID != "" && viewer.UserID == blogPost.UserID {
+		return
+	}
+
+	svc.viewRecorder.Record(blogPost.ID, views.Visitor(viewer.UserID, viewer.IP, viewer.UserAgent), time.Now())
+}
+
+// FlushViews implements domain.BlogService.
+func (svc *blogService) FlushViews() error {
+
+	svc.viewRecorder.Prune(time.Now())
+
+	counts := svc.viewRecorder.Drain()
+	if len(counts) == 0 {
+		return nil
+	}
+
+	dailyViews := make([]models.BlogPostDailyView, len(counts))
+	for i, count := range counts {
+		dailyViews[i] = models.BlogPostDailyView{BlogPostID: count.BlogID, Day: count.Day, Views: count.Views}
+	}
+
+	// The views stay buffered until a flush gets them written
+	if err := svc.repo.AddBlogPostViews(dailyViews); err != nil {
+		svc.viewRecorder.Restore(counts)
+		return err
+	}
+
+	return nil
+}
+
+// GetBlogPostViews implements domain.BlogService.
+func (svc *blogService) GetBlogPostViews(userID string, blogID string, query types.ViewHistoryQuery) (types.ViewHistoryResp, error) {
+
+	from, to, err := viewRange(query, time.Now())
+	if err != nil {
+		return types.ViewHistoryResp{}, err
+	}
+
+	// Who reads a post is as private as its history
+	blogPost, err := svc.revisionsOf(userID, blogID, blogconsts.YouAreNotAuthorizedToSeeViews)
+	if err != nil {
+		return types.ViewHistoryResp{}, err
+	}
+
+	dailyViews, err := svc.repo.GetBlogPostDailyViews(blogPost.ID, from.Format(views.DayLayout), to.Format(views.DayLayout))
+	if err != nil {
+		return types.ViewHistoryResp{}, err
+	}
+
+	byDay := make(map[string]uint, len(dailyViews))
+	for _, dailyView := range dailyViews {
+		byDay[dailyView.Day] = dailyView.Views
+	}
+
+	resp := types.ViewHistoryResp{
+		BlogPostID: blogPost.ID,
+		From:       from.Format(views.DayLayout),
+		To:         to.Format(views.DayLayout),
+		Days:       []types.DailyViewsResp{},
+	}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		key := day.Format(views.DayLayout)
+		resp.Days = append(resp.Days, types.DailyViewsResp{Day: key, Views: byDay[key]})
+		resp.Total += byDay[key]
+	}
+
+	return resp, nil
+}
+
+// viewRange reads the days of a view history, the last DefaultViewHistoryDays up to today by default
+func viewRange(query types.ViewHistoryQuery, now time.Time) (time.Time, time.Time, error) {
+
+	invalid := utils.NewStatusError(http.StatusBadRequest, fmt.Sprintf(blogconsts.InvalidViewRange, blogconsts.MaxViewHistoryDays))
+
+	var err error
+	to := now.UTC().Truncate(24 * time.Hour)
+	if query.To != "" {
+		if to, err = time.Parse(views.DayLayout, query.To); err != nil {
+			return time.Time{}, time.Time{}, invalid
+		}
+	}
+
+	from := to.AddDate(0, 0, -(blogconsts.DefaultViewHistoryDays - 1))
+	if query.From != "" {
+		if from, err = time.Parse(views.DayLayout, query.From); err != nil {
+			return time.Time{}, time.Time{}, invalid
+		}
+	}
+
+	if from.After(to) || to.Sub(from) >= blogconsts.MaxViewHistoryDays*24*time.Hour {
+		return time.Time{}, time.Time{}, invalid
+	}
+
+	return from, to, nil
+}
@@ -0,0 +1,190 @@
+package signing
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// DefaultKeyID identifies the HMAC key built from JWTSecret when no key file is configured
+const DefaultKeyID = "default"
+
+var (
+	ErrNoSigningKey = errors.New("no signing key is valid at this time")
+	ErrUnknownKey   = errors.New("token signed with an unknown key")
+	ErrKeyExpired   = errors.New("token signed with an expired key")
+)
+
+// Key is a single JWT key, keys without a private part are only used for verification
+type Key struct {
+	ID         string
+	Algorithm  string
+	Method     jwt.SigningMethod
+	Private    interface{}
+	Public     interface{}
+	NotBefore  time.Time
+	NotAfter   time.Time
+	VerifyOnly bool
+}
+
+// CanSign reports whether the key may sign tokens at the given time
+func (k Key) CanSign(now time.Time) bool {
+	if k.Private == nil || k.VerifyOnly {
+		return false
+	}
+	if !k.NotBefore.IsZero() && now.Before(k.NotBefore) {
+		return false
+	}
+	return k.NotAfter.IsZero() || now.Before(k.NotAfter)
+}
+
+// CanVerify reports whether tokens signed by the key are still accepted, tokens signed just before
+// NotAfter stay valid for the grace period so rotation does not log anybody out
+func (k Key) CanVerify(now time.Time, grace time.Duration) bool {
+	return k.NotAfter.IsZero() || now.Before(k.NotAfter.Add(grace))
+}
+
+// KeySet holds every key that signs or verifies tokens, it is safe for concurrent use
+type KeySet struct {
+	mu          sync.RWMutex
+	keys        []Key
+	verifyGrace time.Duration
+	legacyKey   *Key
+}
+
+// NewKeySet returns a key set, verifyGrace should be at least the lifetime of the tokens it signs
+func NewKeySet(keys []Key, verifyGrace time.Duration) (*KeySet, error) {
+
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, errors.New("key without kid")
+		}
+		if seen[key.ID] {
+			return nil, fmt.Errorf("duplicate kid %q", key.ID)
+		}
+		seen[key.ID] = true
+	}
+
+	sorted := append([]Key(nil), keys...)
+
+	// Newest keys first so the most recent valid key signs
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].NotBefore.After(sorted[j].NotBefore)
+	})
+
+	keySet := &KeySet{
+		keys:        sorted,
+		verifyGrace: verifyGrace,
+	}
+
+	// Tokens issued before kids were introduced carry no kid and were signed with the HMAC secret
+	for i := range sorted {
+		if sorted[i].ID == DefaultKeyID && sorted[i].Algorithm == AlgorithmHS256 {
+			keySet.legacyKey = &sorted[i]
+		}
+	}
+
+	return keySet, nil
+}
+
+// SigningKey returns the key new tokens are signed with
+func (ks *KeySet) SigningKey(now time.Time) (Key, error) {
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	for _, key := range ks.keys {
+		if key.CanSign(now) {
+			return key, nil
+		}
+	}
+
+	return Key{}, ErrNoSigningKey
+}
+
+// Sign signs the claims with the current signing key and records its kid in the header
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+
+	key, err := ks.SigningKey(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.Private)
+}
+
+// Keyfunc resolves the verification key of a token, for use with jwt.ParseWithClaims
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, err := ks.lookup(token)
+	if err != nil {
+		return nil, err
+	}
+
+	// The alg header must match the key, otherwise a public key could be used as an HMAC secret
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	if !key.CanVerify(time.Now(), ks.verifyGrace) {
+		return nil, ErrKeyExpired
+	}
+
+	if key.Algorithm == AlgorithmHS256 {
+		return key.Private, nil
+	}
+
+	return key.Public, nil
+}
+
+// Keys returns the keys that are still accepted for verification
+func (ks *KeySet) Keys() []Key {
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	now := time.Now()
+	keys := make([]Key, 0, len(ks.keys))
+	for _, key := range ks.keys {
+		if key.CanVerify(now, ks.verifyGrace) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+func (ks *KeySet) lookup(token *jwt.Token) (Key, error) {
+
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if ks.legacyKey != nil {
+			return *ks.legacyKey, nil
+		}
+		return Key{}, ErrUnknownKey
+	}
+
+	for _, key := range ks.keys {
+		if key.ID == kid {
+			return key, nil
+		}
+	}
+
+	return Key{}, ErrUnknownKey
+}
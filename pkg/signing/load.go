@@ -0,0 +1,185 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// keyFile is the JSON manifest pointed to by JWTKEYSFILE
+//
+//	{"keys": [{"kid": "2024-10", "alg": "RS256", "private_key_file": "keys/2024-10.pem",
+//	           "not_before": "2024-10-01T00:00:00Z", "not_after": "2025-01-01T00:00:00Z"}]}
+//
+// Relative key paths are resolved against the directory of the manifest. A key with only
+// public_key_file verifies tokens but never signs, which is how a retired key is kept around.
+type keyFile struct {
+	Keys []keyFileEntry `json:"keys"`
+}
+
+type keyFileEntry struct {
+	KeyID          string    `json:"kid"`
+	Algorithm      string    `json:"alg"`
+	PrivateKeyFile string    `json:"private_key_file"`
+	PublicKeyFile  string    `json:"public_key_file"`
+	NotBefore      time.Time `json:"not_before"`
+	NotAfter       time.Time `json:"not_after"`
+}
+
+// Global var to access from any package
+var LocalKeySet *KeySet
+
+// InitKeySet builds the key set from the key manifest, or from the HMAC secret when there is none
+func InitKeySet(keysFile string, secret string, verifyGrace time.Duration) *KeySet {
+
+	var keys []Key
+	var err error
+
+	if keysFile != "" {
+		keys, err = LoadKeyFile(keysFile)
+		if err != nil {
+			log.Fatal("Error reading JWT keys file ", err)
+		}
+	}
+
+	// With a key file the HMAC secret only verifies tokens issued before the file was configured
+	if secret != "" {
+		keys = append(keys, Key{
+			ID:         DefaultKeyID,
+			Algorithm:  AlgorithmHS256,
+			Method:     jwt.SigningMethodHS256,
+			Private:    []byte(secret),
+			NotBefore:  time.Unix(0, 0),
+			VerifyOnly: keysFile != "",
+		})
+	}
+
+	if len(keys) == 0 {
+		log.Fatal("Either JWTSECRET or JWTKEYSFILE must be configured")
+	}
+
+	keySet, err := NewKeySet(keys, verifyGrace)
+	if err != nil {
+		log.Fatal("Invalid JWT keys ", err)
+	}
+
+	return keySet
+}
+
+// SetKeySet initializes the global key set
+func SetKeySet(keysFile string, secret string, verifyGrace time.Duration) {
+	LocalKeySet = InitKeySet(keysFile, secret, verifyGrace)
+}
+
+// LoadKeyFile reads the key manifest and every key file it references
+func LoadKeyFile(path string) ([]Key, error) {
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest keyFile
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(path)
+	keys := make([]Key, 0, len(manifest.Keys))
+
+	for _, entry := range manifest.Keys {
+		key, err := loadKey(dir, entry)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", entry.KeyID, err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func loadKey(dir string, entry keyFileEntry) (Key, error) {
+
+	key := Key{
+		ID:        entry.KeyID,
+		Algorithm: entry.Algorithm,
+		NotBefore: entry.NotBefore,
+		NotAfter:  entry.NotAfter,
+	}
+
+	if entry.PrivateKeyFile == "" && entry.PublicKeyFile == "" {
+		return key, errors.New("private_key_file or public_key_file is required")
+	}
+
+	privatePEM, err := readKeyPEM(dir, entry.PrivateKeyFile)
+	if err != nil {
+		return key, err
+	}
+
+	publicPEM, err := readKeyPEM(dir, entry.PublicKeyFile)
+	if err != nil {
+		return key, err
+	}
+
+	switch entry.Algorithm {
+	case AlgorithmRS256:
+		key.Method = jwt.SigningMethodRS256
+		if privatePEM != nil {
+			private, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return key, err
+			}
+			key.Private = private
+			key.Public = &private.PublicKey
+		} else {
+			public, err := jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+			if err != nil {
+				return key, err
+			}
+			key.Public = public
+		}
+	case AlgorithmEdDSA:
+		key.Method = jwt.SigningMethodEdDSA
+		if privatePEM != nil {
+			private, err := jwt.ParseEdPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return key, err
+			}
+			key.Private = private
+			key.Public = private.(crypto.Signer).Public()
+		} else {
+			public, err := jwt.ParseEdPublicKeyFromPEM(publicPEM)
+			if err != nil {
+				return key, err
+			}
+			key.Public = public
+		}
+		if _, ok := key.Public.(ed25519.PublicKey); !ok {
+			return key, errors.New("EdDSA keys must be Ed25519")
+		}
+	default:
+		return key, fmt.Errorf("unsupported algorithm %q, use %s or %s", entry.Algorithm, AlgorithmRS256, AlgorithmEdDSA)
+	}
+
+	return key, nil
+}
+
+func readKeyPEM(dir string, path string) ([]byte, error) {
+
+	if path == "" {
+		return nil, nil
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	return os.ReadFile(path)
+}
@@ -0,0 +1,144 @@
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 key of the RFC 6238 test vectors, "12345678901234567890" in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeAt(t *testing.T) {
+
+	// RFC 6238 appendix B, the vectors there have 8 digits and these are their last 6
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := CodeAt(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("CodeAt(%d) error: %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("CodeAt(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestCodeAtLowerCaseSecret(t *testing.T) {
+
+	want, _ := CodeAt(rfcSecret, 1)
+	got, err := CodeAt(strings.ToLower(rfcSecret), 1)
+	if err != nil || got != want {
+		t.Fatalf("CodeAt of the lower case secret = %s, %v, want %s", got, err, want)
+	}
+}
+
+func TestCodeAtInvalidSecret(t *testing.T) {
+	if _, err := CodeAt("not base32!", 1); err == nil {
+		t.Fatal("CodeAt of an invalid secret did not fail")
+	}
+}
+
+func TestValidate(t *testing.T) {
+
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+	codeAt := func(step int64) string {
+		code, err := CodeAt(rfcSecret, step)
+		if err != nil {
+			t.Fatalf("CodeAt(%d): %v", step, err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		skew     int
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", code: codeAt(current), skew: 0, wantStep: current, wantOK: true},
+		{name: "surrounding spaces are ignored", code: " " + codeAt(current) + "\n", skew: 0, wantStep: current, wantOK: true},
+		{name: "previous step within the skew", code: codeAt(current - 1), skew: 1, wantStep: current - 1, wantOK: true},
+		{name: "next step within the skew", code: codeAt(current + 1), skew: 1, wantStep: current + 1, wantOK: true},
+		{name: "previous step without skew", code: codeAt(current - 1), skew: 0},
+		{name: "step beyond the skew", code: codeAt(current + 2), skew: 1},
+		{name: "wrong code", code: "000000", skew: 1},
+		{name: "too short", code: codeAt(current)[:Digits-1], skew: 1},
+		{name: "too long", code: codeAt(current) + "0", skew: 1},
+		{name: "empty", code: "", skew: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now, tt.skew)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Fatalf("Validate(%q, skew %d) = %d, %v, want %d, %v", tt.code, tt.skew, step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+
+	first, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error: %v", err)
+	}
+
+	key, err := encoding.DecodeString(first)
+	if err != nil {
+		t.Fatalf("GenerateSecret() = %q, not base32: %v", first, err)
+	}
+	if len(key) != SecretSize {
+		t.Fatalf("GenerateSecret() holds %d bytes, want %d", len(key), SecretSize)
+	}
+
+	second, _ := GenerateSecret()
+	if first == second {
+		t.Fatal("GenerateSecret() returned the same secret twice")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+
+	uri := ProvisioningURI("Blog API", "jane@example.com", rfcSecret)
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("ProvisioningURI() = %q, not a URI: %v", uri, err)
+	}
+	if parsed.Scheme != "otpauth" || parsed.Host != "totp" {
+		t.Fatalf("ProvisioningURI() = %q, want an otpauth://totp/ URI", uri)
+	}
+	if label := strings.TrimPrefix(parsed.Path, "/"); label != "Blog API:jane@example.com" {
+		t.Fatalf("ProvisioningURI() label = %q, want %q", label, "Blog API:jane@example.com")
+	}
+
+	want := map[string]string{
+		"secret":    rfcSecret,
+		"issuer":    "Blog API",
+		"algorithm": "SHA1",
+		"digits":    "6",
+		"period":    "30",
+	}
+	query := parsed.Query()
+	for key, value := range want {
+		if got := query.Get(key); got != value {
+			t.Errorf("ProvisioningURI() %s = %q, want %q", key, got, value)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package types
+
+import (
+	validate "github.com/go-ozzo/ozzo-validation"
+	"time"
+)
+
+// APIKeyRequest
+type APIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes,omitempty"` // permissions the key is limited to, empty means all of the user's role
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Validate is a function that validates the request body for creating an api key
+func (req APIKeyRequest) Validate() error {
+	return validate.ValidateStruct(&req,
+		validate.Field(&req.Name, validate.Required, validate.Length(1, 100)),
+		validate.Field(&req.Scopes, validate.Length(0, 20)),
+	)
+}
+
+// APIKeyResp
+type APIKeyResp struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyCreatedResp carries the full key, returned once on creation
+type APIKeyCreatedResp struct {
+	APIKeyResp
+	Key string `json:"key"`
+}
+
+// APIKeyPrincipal is who an authenticated api key acts as
+type APIKeyPrincipal struct {
+	KeyID     string
+	UserID    string
+	UserEmail string
+	Role      string
+	Scopes    []string
+}
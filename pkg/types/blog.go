@@ -1,32 +1,57 @@
 package types
 
 import (
+	"Blog_API/pkg/listing"
+	"Blog_API/pkg/poststatus"
+	blogconsts "Blog_API/pkg/utils/consts/blog"
 	"github.com/go-ozzo/ozzo-validation"
+	"regexp"
+	"time"
 )
 
 type BlogPostRequest struct {
-	Title       string `json:"title"`
-	ContentText string `json:"content_text"`
-	PhotoURL    string `json:"photo_url"`
-	Description string `json:"description"`
-	Category    string `json:"category"`
-	IsPublished bool   `json:"is_published"`
+	Title       string     `json:"title"`
+	ContentText string     `json:"content_text"`
+	PhotoURL    string     `json:"photo_url"`
+	Description string     `json:"description"`
+	Category    string     `json:"category"` // slug or id of an existing category
+	Tags        []string   `json:"tags"`
+	IsPublished bool       `json:"is_published"`         // used when status is empty
+	Status      string     `json:"status,omitempty"`     // draft, scheduled or published
+	PublishAt   *time.Time `json:"publish_at,omitempty"` // required for scheduled
 }
 
 func (blogPost BlogPostRequest) Validate() error {
 	return validation.ValidateStruct(&blogPost,
 		validation.Field(&blogPost.Title, validation.Required, validation.Length(10, 255)),
-		validation.Field(&blogPost.Category, validation.Required, validation.Length(3, 100)),
+		validation.Field(&blogPost.Category, validation.Required, validation.Length(1, 255)),
+		validation.Field(&blogPost.Tags, validation.Length(0, blogconsts.MaxTagsPerPost)),
+		validation.Field(&blogPost.Status, validation.In(poststatus.Draft, poststatus.Scheduled, poststatus.Published)),
 	)
 }
 
 type UpdateBlogPostRequest struct {
-	Title       string `json:"title"`
-	ContentText string `json:"content_text"`
-	PhotoURL    string `json:"photo_url"`
-	Description string `json:"description"`
-	Category    string `json:"category"`
-	IsPublished bool   `json:"is_published"`
+	Title       string     `json:"title"`
+	ContentText string     `json:"content_text"`
+	PhotoURL    string     `json:"photo_url"`
+	Description string     `json:"description"`
+	Category    string     `json:"category"`
+	Tags        []string   `json:"tags"`         // replaces the tags of the post, leaving it out keeps them
+	IsPublished bool       `json:"is_published"` // publishes the post when status is empty, false leaves the status alone
+	Status      string     `json:"status,omitempty"`
+	PublishAt   *time.Time `json:"publish_at,omitempty"`
+}
+
+// BlogStatusRequest moves a post through its lifecycle
+type BlogStatusRequest struct {
+	Status    string     `json:"status"`
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+}
+
+func (req BlogStatusRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Status, validation.Required, validation.In(poststatus.Draft, poststatus.Scheduled, poststatus.Published, poststatus.Archived)),
+	)
 }
 
 type Comment struct {
@@ -42,28 +67,244 @@ func (comment Comment) Validate() error {
 func (blogPost UpdateBlogPostRequest) Validate() error {
 	return validation.ValidateStruct(&blogPost,
 		validation.Field(&blogPost.Title, validation.Required, validation.Length(10, 255)),
-		validation.Field(&blogPost.Category, validation.Required, validation.Length(3, 100)),
+		validation.Field(&blogPost.Category, validation.Required, validation.Length(1, 255)),
+		validation.Field(&blogPost.Tags, validation.Length(0, blogconsts.MaxTagsPerPost)),
+		validation.Field(&blogPost.Status, validation.In(poststatus.Draft, poststatus.Scheduled, poststatus.Published, poststatus.Archived)),
+	)
+}
+
+// BlogPostPatch is the document a JSON merge patch of a post applies to, only the fields it names are written
+type BlogPostPatch struct {
+	Title       string     `json:"title"`
+	ContentText string     `json:"content_text"`
+	PhotoURL    string     `json:"photo_url"`
+	Description string     `json:"description"`
+	Category    string     `json:"category"`
+	Tags        []string   `json:"tags"`
+	Status      string     `json:"status"`
+	PublishAt   *time.Time `json:"publish_at"`
+}
+
+func (blogPost BlogPostPatch) Validate() error {
+	return validation.ValidateStruct(&blogPost,
+		validation.Field(&blogPost.Title, validation.Required, validation.Length(10, 255)),
+		validation.Field(&blogPost.Category, validation.Required, validation.Length(1, 255)),
+		validation.Field(&blogPost.Tags, validation.Length(0, blogconsts.MaxTagsPerPost)),
+		validation.Field(&blogPost.Status, validation.Required, validation.In(poststatus.Draft, poststatus.Scheduled, poststatus.Published, poststatus.Archived)),
 	)
 }
 
 type BlogResp struct {
-	ID             string         `json:"id,omitempty"`
-	UserID         string         `json:"user_id,omitempty"`
-	Title          string         `json:"title,omitempty"`
-	ContentText    string         `json:"content_text,omitempty"`
-	PhotoURL       string         `json:"photo_url,omitempty"`
-	Description    string         `json:"description,omitempty"`
-	Category       string         `json:"category"`
-	Comments       []CommentResp  `json:"comments"`
-	CommentsCount  uint           `json:"comments_count"`
-	ReactionsCount uint           `json:"reactions_count"`
-	Reactions      []ReactionResp `json:"reactions"`
-	Views          uint           `json:"views"`
-	IsPublished    bool           `json:"is_published"`
-	PublishedAt    string         `json:"published_at"`
-	CreatedAt      string         `json:"created_at,omitempty"`
-	UpdatedAt      string         `json:"updated_at,omitempty"`
-	DeletedAt      string         `json:"deleted_at,omitempty"`
+	ID             string          `json:"id,omitempty"`
+	UserID         string          `json:"user_id,omitempty"`
+	Title          string          `json:"title,omitempty"`
+	Slug           string          `json:"slug,omitempty"`
+	ContentText    string          `json:"content_text,omitempty"`
+	PhotoURL       string          `json:"photo_url,omitempty"`
+	Description    string          `json:"description,omitempty"`
+	CategoryID     string          `json:"category_id,omitempty"`
+	Category       string          `json:"category"` // slug of the category
+	Tags           []string        `json:"tags"`
+	Comments       []CommentResp   `json:"comments"`
+	CommentsCount  uint            `json:"comments_count"`
+	ReactionsCount uint            `json:"reactions_count"`
+	ReactionCounts map[string]uint `json:"reaction_counts"` // per reaction type, only the types used
+	Reactions      []ReactionResp  `json:"reactions"`
+	MyReaction     *ReactionResp   `json:"my_reaction,omitempty"` // reaction of the requesting user, if any
+	Views          uint            `json:"views"`
+	Version        uint            `json:"version"`
+	Status         string          `json:"status"`
+	IsPublished    bool            `json:"is_published"`
+	PublishAt      string          `json:"publish_at,omitempty"`
+	PublishedAt    string          `json:"published_at"`
+	CreatedAt      string          `json:"created_at,omitempty"`
+	UpdatedAt      string          `json:"updated_at,omitempty"`
+	DeletedAt      string          `json:"deleted_at,omitempty"`
+	LastModified   time.Time       `json:"-"` // latest change to the post, its comments or its reactions
+}
+
+// BlogListQuery pages, sorts and filters a listing of posts, it is bound from the query string
+type BlogListQuery struct {
+	Offset   int    `query:"offset"`
+	Limit    int    `query:"limit"`    // defaults to 20, at most 100
+	Cursor   string `query:"cursor"`   // next_cursor of the previous page, instead of offset
+	Sort     string `query:"sort"`     // published_at (default), views, reactions_count or comments_count
+	Order    string `query:"order"`    // desc (default) or asc
+	Category string `query:"category"` // slug or id, its subcategories are included
+	Author   string `query:"author"`   // user id of the author
+	From     string `query:"from"`     // published on or after, a date or an RFC 3339 time
+	To       string `query:"to"`       // published on or before, a date counts as its whole day
+	Status   string `query:"status"`
+	Tags     string `query:"tags"`  // comma separated tag names
+	Match    string `query:"match"` // any (default) or all of the tags
+}
+
+func (query BlogListQuery) Validate() error {
+	return validation.ValidateStruct(&query,
+		validation.Field(&query.Offset, validation.Min(0)),
+		validation.Field(&query.Limit, validation.Min(0), validation.Max(100)),
+		validation.Field(&query.Sort, validation.In(listing.SortPublishedAt, listing.SortViews, listing.SortReactionsCount, listing.SortCommentsCount)),
+		validation.Field(&query.Order, validation.In(listing.Ascending, listing.Descending)),
+		validation.Field(&query.Status, validation.In(poststatus.Draft, poststatus.Scheduled, poststatus.Published, poststatus.Archived)),
+		validation.Field(&query.Match, validation.In(listing.MatchAny, listing.MatchAll)),
+	)
+}
+
+// BlogPage is one page of a listing. Total counts every post matching the filters, and
+// next_cursor is left out on the last page.
+type BlogPage struct {
+	Items      []BlogResp `json:"items"`
+	Total      int64      `json:"total"`
+	Offset     int        `json:"offset"`
+	Limit      int        `json:"limit"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// TagListQuery pages the tags in use, the most used first
+type TagListQuery struct {
+	Q      string `query:"q"` // only the tags starting with it
+	Offset int    `query:"offset"`
+	Limit  int    `query:"limit"`
+}
+
+func (query TagListQuery) Validate() error {
+	return validation.ValidateStruct(&query,
+		validation.Field(&query.Offset, validation.Min(0)),
+		validation.Field(&query.Limit, validation.Min(0), validation.Max(100)),
+	)
+}
+
+// TagResp is a tag with the number of published posts carrying it
+type TagResp struct {
+	Name       string `json:"name"`
+	PostsCount uint   `json:"posts_count"`
+}
+
+type TagPage struct {
+	Items  []TagResp `json:"items"`
+	Total  int64     `json:"total"`
+	Offset int       `json:"offset"`
+	Limit  int       `json:"limit"`
+}
+
+// MergeTagsRequest moves the posts of the From tags to Into and deletes the From tags
+type MergeTagsRequest struct {
+	From []string `json:"from"`
+	Into string   `json:"into"`
+}
+
+func (req MergeTagsRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.From, validation.Required, validation.Length(1, 50)),
+		validation.Field(&req.Into, validation.Required),
+	)
+}
+
+type MergeTagsResp struct {
+	Tag     TagResp  `json:"tag"`
+	Merged  []string `json:"merged"`   // normalized names of the tags merged away
+	BlogIDs []string `json:"blog_ids"` // posts that were retagged
+}
+
+// CategoryRequest creates or updates a category. The slug is made from the name when left out, and
+// a category without a parent sits at the top level.
+type CategoryRequest struct {
+	Name        string  `json:"name"`
+	Slug        string  `json:"slug"`
+	Description string  `json:"description"`
+	ParentID    *string `json:"parent_id"`
+}
+
+func (req CategoryRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Name, validation.Required, validation.Length(2, 100)),
+		validation.Field(&req.Slug, validation.Length(0, 255)),
+		validation.Field(&req.Description, validation.Length(0, 500)),
+	)
+}
+
+// CategoryResp is a category with its subcategories. PostsCount counts the published posts filed
+// directly under it, TotalPostsCount those of its subcategories too.
+type CategoryResp struct {
+	ID              string         `json:"id"`
+	ParentID        *string        `json:"parent_id"`
+	Name            string         `json:"name"`
+	Slug            string         `json:"slug"`
+	Description     string         `json:"description"`
+	PostsCount      uint           `json:"posts_count"`
+	TotalPostsCount uint           `json:"total_posts_count"`
+	Children        []CategoryResp `json:"children"`
+}
+
+type CategoryChangeResp struct {
+	Category CategoryResp `json:"category"`
+	BlogIDs  []string     `json:"blog_ids"` // posts that were moved or whose category slug changed
+}
+
+// SearchQuery is a full-text search, bound from the query string
+type SearchQuery struct {
+	Q      string `query:"q"` // words and "quoted phrases", all of them have to match
+	Offset int    `query:"offset"`
+	Limit  int    `query:"limit"`
+}
+
+func (query SearchQuery) Validate() error {
+	return validation.ValidateStruct(&query,
+		validation.Field(&query.Q, validation.Required, validation.Length(1, 200)),
+		validation.Field(&query.Offset, validation.Min(0)),
+		validation.Field(&query.Limit, validation.Min(0), validation.Max(100)),
+	)
+}
+
+// SearchHitResp is a post found by a search. Highlights holds a snippet per matching field
+// (title, description, content, comments) with the matches wrapped in <mark>.
+type SearchHitResp struct {
+	ID         string            `json:"id"`
+	Slug       string            `json:"slug"`
+	Title      string            `json:"title"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+// SearchPage is one page of search results, best match first
+type SearchPage struct {
+	Items  []SearchHitResp `json:"items"`
+	Total  int             `json:"total"`
+	Offset int             `json:"offset"`
+	Limit  int             `json:"limit"`
+}
+
+// Viewer is who is reading a post, as far as view counting needs to know
+type Viewer struct {
+	UserID    string // empty for anonymous visitors
+	IP        string
+	UserAgent string
+}
+
+// ViewHistoryQuery picks the days of the view history, bound from the query string
+type ViewHistoryQuery struct {
+	From string `query:"from"` // first day, 2006-01-02 in UTC, 29 days before to by default
+	To   string `query:"to"`   // last day, today by default
+}
+
+// ViewHistoryResp has one entry per day of the range, days without views included
+type ViewHistoryResp struct {
+	BlogPostID string           `json:"blog_post_id"`
+	From       string           `json:"from"`
+	To         string           `json:"to"`
+	Total      uint             `json:"total"` // views within the range
+	Days       []DailyViewsResp `json:"days"`
+}
+
+type DailyViewsResp struct {
+	Day   string `json:"day"`
+	Views uint   `json:"views"`
+}
+
+// CacheValidators are the ETag and Last-Modified of a read, they can be had without building the response
+type CacheValidators struct {
+	ETag         string
+	LastModified time.Time
 }
 
 type ReactionResp struct {
@@ -73,9 +314,120 @@ type ReactionResp struct {
 	Type       uint64 `json:"type"`
 }
 
+// ReactorListQuery pages the users that reacted to a post with one type
+type ReactorListQuery struct {
+	Offset int `query:"offset"`
+	Limit  int `query:"limit"`
+}
+
+func (query ReactorListQuery) Validate() error {
+	return validation.ValidateStruct(&query,
+		validation.Field(&query.Offset, validation.Min(0)),
+		validation.Field(&query.Limit, validation.Min(0), validation.Max(100)),
+	)
+}
+
+// ReactorResp is a user that reacted, DisplayName is empty once the user is gone
+type ReactorResp struct {
+	UserID      string `json:"user_id"`
+	DisplayName string `json:"display_name"`
+	Type        uint64 `json:"type"`
+	Code        string `json:"code"`
+	ReactedAt   string `json:"reacted_at"`
+}
+
+type ReactorPage struct {
+	Items  []ReactorResp `json:"items"`
+	Total  int64         `json:"total"`
+	Offset int           `json:"offset"`
+	Limit  int           `json:"limit"`
+}
+
+// reactionCode is the form of a reaction code, it is stored in lowercase and never looks like an id
+var reactionCode = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{1,31}$`)
+
+// ReactionTypeRequest adds a type to the reaction catalog. It is placed after the others and
+// enabled unless told otherwise.
+type ReactionTypeRequest struct {
+	Code     string `json:"code"`
+	Label    string `json:"label"`
+	Emoji    string `json:"emoji"`
+	Position *int   `json:"position"`
+	Enabled  *bool  `json:"enabled"`
+}
+
+func (req ReactionTypeRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Code, validation.Required, validation.Match(reactionCode)),
+		validation.Field(&req.Label, validation.Required, validation.Length(1, 64)),
+		validation.Field(&req.Emoji, validation.Length(0, 32)),
+	)
+}
+
+// UpdateReactionTypeRequest changes the fields that are set, the code of a type stays as it is
+type UpdateReactionTypeRequest struct {
+	Label    *string `json:"label"`
+	Emoji    *string `json:"emoji"`
+	Position *int    `json:"position"`
+	Enabled  *bool   `json:"enabled"`
+}
+
+func (req UpdateReactionTypeRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Label, validation.NilOrNotEmpty, validation.Length(1, 64)),
+		validation.Field(&req.Emoji, validation.Length(0, 32)),
+	)
+}
+
+type ReactionTypeResp struct {
+	ID       uint64 `json:"id"`
+	Code     string `json:"code"`
+	Label    string `json:"label"`
+	Emoji    string `json:"emoji"`
+	Position int    `json:"position"`
+	Enabled  bool   `json:"enabled"`
+}
+
 type CommentResp struct {
 	ID         string `json:"id"`
 	UserID     string `json:"user_id"`
 	BlogPostID string `json:"blog_post_id"`
 	Content    string `json:"content"`
+	Version    uint   `json:"version"`
+}
+
+type BlogRevisionResp struct {
+	Number       uint   `json:"number"`
+	BlogPostID   string `json:"blog_post_id"`
+	AuthorID     string `json:"author_id"`
+	Action       string `json:"action"`
+	RestoredFrom *uint  `json:"restored_from,omitempty"`
+	Title        string `json:"title"`
+	Slug         string `json:"slug,omitempty"`
+	ContentText  string `json:"content_text,omitempty"`
+	PhotoURL     string `json:"photo_url,omitempty"`
+	Description  string `json:"description,omitempty"`
+	Category     string `json:"category,omitempty"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type BlogRevisionDiffResp struct {
+	BlogPostID string          `json:"blog_post_id"`
+	From       uint            `json:"from"`
+	To         uint            `json:"to"`
+	Fields     []FieldDiffResp `json:"fields"` // only the fields that differ
+}
+
+type FieldDiffResp struct {
+	Field   string         `json:"field"`
+	Added   int            `json:"added"`
+	Removed int            `json:"removed"`
+	Lines   []DiffLineResp `json:"lines"`
+}
+
+type DiffLineResp struct {
+	Op       string `json:"op"` // equal, insert or delete
+	Text     string `json:"text"`
+	FromLine int    `json:"from_line,omitempty"`
+	ToLine   int    `json:"to_line,omitempty"`
 }
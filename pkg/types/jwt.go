@@ -6,4 +6,7 @@ type JWTClaims struct {
 	jwt.StandardClaims
 	UserID    string `json:"user_id"`
 	UserEmail string `json:"user_email"`
+	SessionID string `json:"sid,omitempty"`
+	Role      string `json:"role,omitempty"`
+	Purpose   string `json:"purpose,omitempty"` // set on challenge tokens, which are not access tokens
 }
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"errors"
+	validate "github.com/go-ozzo/ozzo-validation"
+	"time"
+)
+
+// TOTPEnrollmentResp is returned when 2FA enrollment starts
+type TOTPEnrollmentResp struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodesResp holds recovery codes, they are only ever shown once
+type RecoveryCodesResp struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAChallengeResp is returned by login instead of a token pair when 2FA is enabled
+type MFAChallengeResp struct {
+	MFARequired bool      `json:"mfa_required"`
+	MFAToken    string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// MFACodeRequest carries a TOTP code or a recovery code
+type MFACodeRequest struct {
+	Code         string `json:"code,omitempty"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+// Validate is a function that validates the request body for a second factor
+func (req MFACodeRequest) Validate() error {
+	if req.Code == "" && req.RecoveryCode == "" {
+		return errors.New("either code or recovery_code is required")
+	}
+	return validate.ValidateStruct(&req,
+		validate.Field(&req.Code, validate.Length(6, 6)),
+		validate.Field(&req.RecoveryCode, validate.Length(10, 20)),
+	)
+}
+
+// TOTPConfirmRequest carries the first code from a freshly enrolled authenticator
+type TOTPConfirmRequest struct {
+	Code string `json:"code"`
+}
+
+// Validate is a function that validates the request body for the 2FA enrollment confirmation
+func (req TOTPConfirmRequest) Validate() error {
+	return validate.ValidateStruct(&req,
+		validate.Field(&req.Code, validate.Required, validate.Length(6, 6)),
+	)
+}
+
+// MFALoginRequest completes a login that returned an MFA challenge
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token"`
+	DeviceID string `json:"device_id,omitempty"`
+	MFACodeRequest
+}
+
+// Validate is a function that validates the request body for the second login step
+func (req MFALoginRequest) Validate() error {
+	if err := validate.ValidateStruct(&req,
+		validate.Field(&req.MFAToken, validate.Required),
+		validate.Field(&req.DeviceID, validate.Length(0, 255)),
+	); err != nil {
+		return err
+	}
+	return req.MFACodeRequest.Validate()
+}
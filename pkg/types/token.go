@@ -0,0 +1,49 @@
+package types
+
+import (
+	validate "github.com/go-ozzo/ozzo-validation"
+	"time"
+)
+
+// DeviceInfo describes the client a token pair is issued to
+type DeviceInfo struct {
+	DeviceID  string
+	UserAgent string
+	IPAddress string
+}
+
+// RefreshTokenRequest
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+	DeviceID     string `json:"device_id,omitempty"`
+}
+
+// Validate is a function that validates the request body for the token refresh
+func (req RefreshTokenRequest) Validate() error {
+	return validate.ValidateStruct(&req,
+		validate.Field(&req.RefreshToken, validate.Required, validate.Length(20, 255)),
+		validate.Field(&req.DeviceID, validate.Length(0, 255)),
+	)
+}
+
+// TokenResp is returned on login and token refresh
+type TokenResp struct {
+	AccessToken      string    `json:"access_token"`
+	AccessExpiresAt  time.Time `json:"access_expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	TokenType        string    `json:"token_type"`
+	SessionID        string    `json:"session_id"`
+}
+
+// SessionResp describes an active login session of a user
+type SessionResp struct {
+	SessionID  string    `json:"session_id"`
+	DeviceID   string    `json:"device_id,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
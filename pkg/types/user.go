@@ -1,11 +1,17 @@
 package types
 
 import (
+	"Blog_API/pkg/permissions"
 	validate "github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
 	"regexp"
 	"time"
 )
 
+// emailRules are shared by every request naming an account by its email, an address accepted at
+// sign-up has to be accepted at login and password reset too
+var emailRules = []validate.Rule{validate.Required, validate.Length(6, 100), is.Email}
+
 // SignUp UserRequest
 type SignUpRequest struct {
 	Email       string    `json:"email"`
@@ -19,7 +25,7 @@ type SignUpRequest struct {
 // Validate is a function that validates the request body for the user
 func (user SignUpRequest) Validate() error {
 	return validate.ValidateStruct(&user,
-		validate.Field(&user.Email, validate.Required, validate.Length(10, 100)),
+		validate.Field(&user.Email, emailRules...),
 		validate.Field(&user.Password, validate.Required, validate.Length(6, 100)),
 	)
 }
@@ -58,17 +64,54 @@ func (user UserUpdateRequest) Validate() error {
 	)
 }
 
+// UserProfilePatch is the document a JSON merge patch of a profile applies to, only the fields it names are written
+type UserProfilePatch struct {
+	Gender         string    `json:"gender"`
+	DateOfBirth    time.Time `json:"date_of_birth"`
+	Job            string    `json:"job"`
+	City           string    `json:"city"`
+	ZipCode        string    `json:"zipcode"`
+	ProfilePicture string    `json:"profile_picture"`
+	FirstName      string    `json:"first_name"`
+	LastName       string    `json:"last_name"`
+	Phone          string    `json:"phone"`
+	Street         string    `json:"street"`
+	State          string    `json:"state"`
+	Country        string    `json:"country"`
+	Latitude       float64   `json:"latitude"`
+	Longitude      float64   `json:"longitude"`
+}
+
+func (user UserProfilePatch) Validate() error {
+	return validate.ValidateStruct(&user,
+		validate.Field(&user.FirstName, validate.Length(2, 255)),
+		validate.Field(&user.LastName, validate.Length(2, 255)),
+		validate.Field(&user.Phone, validate.Match(regexp.MustCompile(`^\+?[1-9]\d{1,14}$`))),
+		validate.Field(&user.Street, validate.Length(5, 255)),
+		validate.Field(&user.City, validate.Length(3, 255)),
+		validate.Field(&user.State, validate.Length(3, 255)),
+		validate.Field(&user.Country, validate.Length(3, 255)),
+		validate.Field(&user.ZipCode, validate.Length(4, 100), validate.Match(regexp.MustCompile(`^\d{5}(-\d{4})?$`))),
+		validate.Field(&user.Job, validate.Length(1, 100)),
+		validate.Field(&user.ProfilePicture, validate.Length(10, 255)),
+		validate.Field(&user.Latitude, validate.Min(-90.0), validate.Max(90.0)),
+		validate.Field(&user.Longitude, validate.Min(-180.0), validate.Max(180.0)),
+	)
+}
+
 // Login UserRequest
 type LoginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	DeviceID string `json:"device_id,omitempty"`
 }
 
 // Validate is a function that validates the request body for the user
 func (user LoginRequest) Validate() error {
 	return validate.ValidateStruct(&user,
-		validate.Field(&user.Email, validate.Required, validate.Length(10, 100)),
+		validate.Field(&user.Email, emailRules...),
 		validate.Field(&user.Password, validate.Required, validate.Length(6, 100)),
+		validate.Field(&user.DeviceID, validate.Length(0, 255)),
 	)
 }
 
@@ -90,4 +133,58 @@ type UserResp struct {
 	Country        string    `json:"country,omitempty" default:"Bangladesh"`
 	Latitude       float64   `json:"latitude,omitempty"`
 	Longitude      float64   `json:"longitude,omitempty"`
+	Role           string    `json:"role,omitempty"`
+	EmailVerified  bool      `json:"email_verified"`
+	TOTPEnabled    bool      `json:"totp_enabled"`
+	Version        uint      `json:"version"`
+}
+
+// UserRoleRequest
+type UserRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// Validate is a function that validates the request body for the role change
+func (req UserRoleRequest) Validate() error {
+	return validate.ValidateStruct(&req,
+		validate.Field(&req.Role, validate.Required, validate.In(permissions.RoleReader, permissions.RoleAuthor, permissions.RoleEditor, permissions.RoleAdmin)),
+	)
+}
+
+// ForgotPasswordRequest
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// Validate is a function that validates the request body for the forgotten password
+func (req ForgotPasswordRequest) Validate() error {
+	return validate.ValidateStruct(&req,
+		validate.Field(&req.Email, emailRules...),
+	)
+}
+
+// ResetPasswordRequest
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// Validate is a function that validates the request body for the password reset
+func (req ResetPasswordRequest) Validate() error {
+	return validate.ValidateStruct(&req,
+		validate.Field(&req.Token, validate.Required, validate.Length(20, 255)),
+		validate.Field(&req.Password, validate.Required, validate.Length(6, 100)),
+	)
+}
+
+// VerifyEmailRequest
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// Validate is a function that validates the request body for the email verification
+func (req VerifyEmailRequest) Validate() error {
+	return validate.ValidateStruct(&req,
+		validate.Field(&req.Token, validate.Required, validate.Length(20, 255)),
+	)
 }
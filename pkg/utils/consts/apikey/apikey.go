@@ -0,0 +1,34 @@
+package apikeyconsts
+
+import "time"
+
+const (
+	ErrorCreatingAPIKey = "error creating api key"
+	ErrorGettingAPIKeys = "error getting api keys"
+	ErrorRevokingAPIKey = "error revoking api key"
+	InvalidAPIKey       = "invalid api key"
+	APIKeyExpired       = "api key expired"
+	APIKeyRevoked       = "api key has been revoked"
+	APIKeyNotFound      = "api key not found"
+	APIKeyIDRequired    = "required api key id"
+	InvalidScope        = "invalid scope %q"
+	ExpiryInPast        = "expires_at must be in the future"
+	TooManyAPIKeys      = "at most %d active api keys are allowed"
+	ErrorCheckingAPIKey = "error checking api key"
+)
+
+const (
+	APIKeyCreatedSuccessfully = "api key created, copy it now, it is not shown again"
+	APIKeysFetchSuccessfully  = "api keys fetched successfully"
+	APIKeyRevokedSuccessfully = "api key revoked successfully"
+	APIKeyIDParam             = "key_id"
+)
+
+const (
+	// KeyPrefix marks API keys so they can be told apart from JWTs in the Authorization header
+	KeyPrefix         = "bk_"
+	PrefixBytes       = 6
+	SecretBytes       = 32
+	MaxActivePerUser  = 25
+	LastUsedPrecision = time.Minute // last_used_at is written at most once per minute per key
+)
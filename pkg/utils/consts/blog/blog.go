@@ -1,9 +1,12 @@
 package blogconsts
 
+import "time"
+
 const (
 	ErrorCreatingBlog           = "error creating blog"
 	ErrorGettingBlog            = "error getting blog"
 	ErrorGettingBlogs           = "error getting blogs"
+	ErrorSearchingBlogs         = "error searching blogs"
 	ErrorUpdatingBlog           = "error updating blog"
 	ErrorDeletingBlog           = "error deleting blog"
 	ErrorAddingRemovingReaction = "error adding or removing reaction"
@@ -11,52 +14,190 @@ const (
 	ErrorGettingComments        = "error getting comments"
 	ErrorDeletingComment        = "error deleting comment"
 	ErrorUpdatingComment        = "error updating comment"
+	ErrorChangingBlogStatus     = "error changing blog status"
+	ErrorGettingRevisions       = "error getting revisions"
+	ErrorDiffingRevisions       = "error comparing revisions"
+	ErrorRestoringRevision      = "error restoring revision"
+	ErrorGettingTags            = "error getting tags"
+	ErrorMergingTags            = "error merging tags"
+	ErrorGettingCategories      = "error getting categories"
+	ErrorCreatingCategory       = "error creating category"
+	ErrorUpdatingCategory       = "error updating category"
+	ErrorDeletingCategory       = "error deleting category"
+	ErrorGettingViews           = "error getting views"
+	ErrorGettingReactors        = "error getting reactors"
+	ErrorGettingReactionTypes   = "error getting reaction types"
+	ErrorCreatingReactionType   = "error creating reaction type"
+	ErrorUpdatingReactionType   = "error updating reaction type"
+	ErrorRetiringReactionType   = "error retiring reaction type"
+)
+
+const (
+	BlogIDRequired          = "required blog id"
+	ReactionIDRequired      = "required reaction id"
+	InvalidReactionID       = "invalid reaction id"
+	ReactionTypeRetired     = "the reaction type %s is retired"
+	ReactionCodeTaken       = "the code is taken by another reaction type"
+	InvalidCommentID        = "invalid comment id"
+	CategoryRequired        = "required category"
+	BlogNotFound            = "blog not found"
+	SlugRequired            = "required slug"
+	InvalidStatusTransition = "a %s post can not be moved to %s"
+	PublishAtMustBeInFuture = "publish_at in the future is required to schedule a post"
+	InvalidRevisionNumber   = "invalid revision number"
+	RevisionNotFound        = "revision not found"
+	InvalidCursor           = "invalid cursor, it belongs to another sort or order"
+	CursorWithOffset        = "use either offset or cursor, not both"
+	InvalidDateFilter       = "from and to must be dates (2006-01-02) or RFC 3339 times"
+	OnlyPublishedListed     = "only published posts are listed here"
+	AuthorOfOwnListing      = "the listing of your own posts can not be filtered by another author"
+	TagsRequired            = "required tags"
+	InvalidTag              = "tag %q has no letter or digit"
+	TooManyTagsInFilter     = "filter by at most %d tags"
+	TagNotFound             = "tag not found"
+	NothingToMerge          = "name at least one tag other than the one merged into"
+	CategoryIDRequired      = "required category id"
+	CategoryNotFound        = "category not found"
+	UnknownCategory         = "category %q does not exist"
+	ParentCategoryNotFound  = "parent category not found"
+	CategoryCycle           = "a category can not be placed under itself or one of its subcategories"
+	CategorySlugTaken       = "the slug %q is taken by another category"
+	InvalidCategorySlug     = "the slug of a category needs a letter or digit"
+	CategoryInUse           = "the category has posts, name the category to move them to with move_to"
+	MoveToDeletedCategory   = "posts can not be moved to the category being deleted"
+	InvalidViewRange        = "from and to must be dates (2006-01-02), from not after to, at most %d days apart"
+)
+
+// Paging of the post listings
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+	DateLayout      = "2006-01-02" // of the from and to filters
+)
+
+// Tags of a post, and paging of the tag listings
+const (
+	MaxTagsPerPost        = 10
+	MaxTagsInFilter       = 10
+	DefaultSuggestionSize = 10
+	MaxSuggestionSize     = 50
+)
+
+// Posts loaded at a time while the search index is built
+const SearchIndexBatchSize = 500
+
+const (
+	BlogCreatedSuccessfully         = "blog created successfully"
+	BlogFetchSuccessfully           = "blog fetched successfully"
+	BlogsFetchSuccessfully          = "blogs fetched successfully"
+	BlogsFoundSuccessfully          = "search completed successfully"
+	BlogsFetchSuccessfullyOfUser    = "blogs fetched successfully of user"
+	BlogUpdatedSuccessfully         = "blog updated successfully"
+	BlogDeletedSuccessfully         = "blog deleted successfully"
+	ReactionAddedSuccessfully       = "reaction added successfully"
+	CommentAddedSuccessfully        = "comment added successfully"
+	CommentsFetchSuccessfully       = "comments fetched successfully"
+	CommentDeletedSuccessfully      = "comment deleted successfully"
+	CommentUpdatedSuccessfully      = "comment updated successfully"
+	BlogStatusChanged               = "blog status changed successfully"
+	RevisionsFetchSuccessfully      = "revisions fetched successfully"
+	RevisionFetchSuccessfully       = "revision fetched successfully"
+	RevisionsDiffSuccessfully       = "revisions compared successfully"
+	RevisionRestoredSuccessfully    = "revision restored successfully"
+	TagsFetchSuccessfully           = "tags fetched successfully"
+	TagsMergedSuccessfully          = "tags merged successfully"
+	CategoriesFetchSuccessfully     = "categories fetched successfully"
+	CategoryCreatedSuccessfully     = "category created successfully"
+	CategoryUpdatedSuccessfully     = "category updated successfully"
+	CategoryDeletedSuccessfully     = "category deleted successfully"
+	ViewsFetchSuccessfully          = "views fetched successfully"
+	ReactorsFetchSuccessfully       = "reactors fetched successfully"
+	ReactionTypesFetchSuccessfully  = "reaction types fetched successfully"
+	ReactionTypeCreated             = "reaction type created successfully"
+	ReactionTypeUpdated             = "reaction type updated successfully"
+	ReactionTypeRetiredSuccessfully = "reaction type retired successfully"
 )
 
 const (
-	BlogIDRequired     = "required blog id"
-	ReactionIDRequired = "required reaction id"
-	InvalidReactionID  = "invalid reaction id"
-	InvalidCommentID   = "invalid comment id"
-	CategoryRequired   = "required category"
+	BlogID         = "blog_id"
+	BlogIDs        = "blog_ids"
+	ReactionID     = "reaction_id"
+	IncludeRetired = "include_retired"
+	CommentID      = "comment_id"
+	CommentIDs     = "comment_ids"
+	Category       = "category"
+	CategoryID     = "category_id"
+	MoveTo         = "move_to"
+	Slug           = "slug"
+	Query          = "q"
+	Limit          = "limit"
+	Revision       = "revision"
+	From           = "from"
+	To             = "to"
 )
 
+// Actions of a revision
 const (
-	BlogCreatedSuccessfully      = "blog created successfully"
-	BlogFetchSuccessfully        = "blog fetched successfully"
-	BlogsFetchSuccessfully       = "blogs fetched successfully"
-	BlogsFetchSuccessfullyOfUser = "blogs fetched successfully of user"
-	BlogUpdatedSuccessfully      = "blog updated successfully"
-	BlogDeletedSuccessfully      = "blog deleted successfully"
-	ReactionAddedSuccessfully    = "reaction added successfully"
-	CommentAddedSuccessfully     = "comment added successfully"
-	CommentsFetchSuccessfully    = "comments fetched successfully"
-	CommentDeletedSuccessfully   = "comment deleted successfully"
-	CommentUpdatedSuccessfully   = "comment updated successfully"
+	RevisionCreate   = "create"
+	RevisionUpdate   = "update"
+	RevisionRestore  = "restore"
+	RevisionBaseline = "baseline" // the text of a post from before revisions, saved on its first edit
 )
 
+// Category of the posts written before categories, or without one
 const (
-	BlogID     = "blog_id"
-	BlogIDs    = "blog_ids"
-	ReactionID = "reaction_id"
-	CommentID  = "comment_id"
-	CommentIDs = "comment_ids"
-	Category   = "category"
+	DefaultCategorySlug = "uncategorized"
+	DefaultCategoryName = "Uncategorized"
 )
 
+const PublishSchedulerInterval = 30 * time.Second
+
+// How often the comment and reaction counters of the posts are checked against their rows
+const CounterReconcileInterval = 6 * time.Hour
+
+// Counting of views. A visitor counts once per post within the window, and the counts are written
+// once per flush interval.
 const (
+	ViewDedupWindow        = 30 * time.Minute
+	ViewFlushInterval      = 15 * time.Second
+	DefaultViewHistoryDays = 30
+	MaxViewHistoryDays     = 366
+)
+
+// Keys of the response cache, list keys carry the generation that writes replace
+const (
+	CacheKeyPrefix     = "blog_api:blog:"
+	CacheGenerationKey = CacheKeyPrefix + "generation"
+	CachePostKey       = CacheKeyPrefix + "post:%s"
+	CachePostETagKey   = CacheKeyPrefix + "validators:post:%s"
+	CacheSlugKey       = CacheKeyPrefix + "%s:slug:%s"
+	CacheAllKey        = CacheKeyPrefix + "%s:all:%s"
+	CacheCategoryKey   = CacheKeyPrefix + "%s:category:%s:%s"
+	CacheUserKey       = CacheKeyPrefix + "%s:user:%s:%s:%s"
+	CacheListETagKey   = CacheKeyPrefix + "%s:validators:category:%s"
+	CacheTaggedKey     = CacheKeyPrefix + "%s:tagged:%s"
+	CacheTagsKey       = CacheKeyPrefix + "%s:tags:%s"
+	CacheSuggestKey    = CacheKeyPrefix + "%s:tags:suggest:%s:%d"
+	CacheCategoriesKey = CacheKeyPrefix + "%s:categories"
+)
+
+const (
+	DefaultSlug       = "post" // for titles without a single letter or digit
+	MaxSlugCollisions = 1000
+)
+
+const (
+	YouAreNotAuthorizedToCreateBlog        = "you are not authorized to create blogs"
+	YouAreNotAuthorizedToUpdateThisBlog    = "you are not authorized to update this blog"
+	YouAreNotAuthorizedToComment           = "you are not authorized to comment"
+	YouAreNotAuthorizedToReact             = "you are not authorized to react"
 	YouAreNotAuthorizedToDeleteThisBlog    = "you are not authorized to delete this blog"
 	YouAreNotAuthorizedToGetComments       = "you are not authorized to get comments"
 	YouAreNotAuthorizedToDeleteThisComment = "you are not authorized to delete this comment"
 	YouAreNotAuthorizedToUpdateThisComment = "you are not authorized to update this comment"
+	YouAreNotAuthorizedToSeeRevisions      = "you are not authorized to see the revisions of this blog"
+	YouAreNotAuthorizedToManageTags        = "you are not authorized to manage tags"
+	YouAreNotAuthorizedToManageCategories  = "you are not authorized to manage categories"
+	YouAreNotAuthorizedToSeeViews          = "you are not authorized to see the views of this blog"
+	YouAreNotAuthorizedToManageReactions   = "you are not authorized to manage reaction types"
 )
-
-var ReactionTypes = map[uint64]string{
-	1: "like",
-	2: "love",
-	3: "care",
-	4: "haha",
-	5: "wow",
-	6: "sad",
-	7: "angry",
-}
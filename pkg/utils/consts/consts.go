@@ -1,26 +1,79 @@
 package consts
 
+import "time"
+
 // Error messages
 const (
 	Authorization = "Authorization"
 	Bearer        = "bearer"
 	InvalidToken  = "invalid token"
+	TokenRevoked  = "token has been revoked"
 )
 
 const (
 	AuthorizationHeaderRequired = "authorization header is required"
 	InvalidDataRequest          = "invalid data request"
 	ValidationError             = "validation error"
+	ErrorCheckingToken          = "error checking token"
+	PermissionDenied            = "permission denied"
 )
 
 const (
-	REACTIONS      = "Reactions"
-	COMMENTS       = "Comments"
-	ReactionCounts = "reactions_count"
-	CommentCounts  = "comments_count"
+	REACTIONS          = "Reactions"
+	COMMENTS           = "Comments"
+	TAGS               = "Tags"
+	REACTIONTYPECOUNTS = "ReactionTypeCounts"
+	ReactionCounts     = "reactions_count"
+	CommentCounts      = "comments_count"
+	ReactionTypeCount  = "reaction_type_count" // counter name of the per type counts in the reconciliation
 )
 
-const ExpiredTokenLimit = 60
+const RevokedTokenPurgeInterval = 10 * time.Minute
+const ShutdownTimeout = 15 * time.Second // for the requests in flight when the server is stopped
+const MemoryStore = "memory"
+const FileMailer = "file"
+const MemoryMailer = "memory"
+const DefaultMailDir = "mail"
+const APIKeyHeader = "X-API-Key"
 const AppKey = "blog-app-key"
 const AppKeyRequired = "app key is required"
 const InvalidAppKey = "invalid app key"
+
+const MergePatchContentType = "application/merge-patch+json"
+const MaxMergePatchSize = 1 << 20
+const UnsupportedPatchContentType = "merge patch must be sent as application/merge-patch+json"
+const MergePatchTooLarge = "merge patch is too large"
+
+const (
+	IfMatchRequired = "If-Match header with the version of the resource is required"
+	VersionMismatch = "the resource was changed by someone else, fetch it again"
+	InvalidIfMatch  = "If-Match must name a single version"
+)
+
+const (
+	HeaderETag        = "ETag"
+	HeaderIfMatch     = "If-Match"
+	HeaderIfNoneMatch = "If-None-Match"
+)
+
+// Cache-Control of the reads when CACHECONTROLPUBLIC and CACHECONTROLPRIVATE are not set
+const (
+	DefaultPublicCacheControl  = "public, max-age=60, stale-while-revalidate=300"
+	DefaultPrivateCacheControl = "private, no-cache"
+)
+const PreconditionFailed = "precondition failed"
+
+// Backends of the response cache, CACHEBACKEND defaults to MemoryStore
+const (
+	RedisCache = "redis"
+	NoCache    = "none"
+)
+
+const (
+	DefaultCacheSize = 10000
+	DefaultCacheTTL  = 5 * time.Minute
+	DefaultCacheAddr = "localhost:6379"
+)
+
+// Backends of the full-text search, SEARCHBACKEND defaults to MemoryStore
+const MySQLSearch = "mysql"
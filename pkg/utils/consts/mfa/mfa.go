@@ -0,0 +1,36 @@
+package mfaconsts
+
+import "time"
+
+const (
+	ErrorEnrollingTOTP          = "error enrolling two-factor authentication"
+	ErrorConfirmingTOTP         = "error confirming two-factor authentication"
+	ErrorDisablingTOTP          = "error disabling two-factor authentication"
+	ErrorGeneratingRecoveryCode = "error generating recovery codes"
+	ErrorVerifyingMFA           = "error verifying two-factor code"
+	TOTPAlreadyEnabled          = "two-factor authentication is already enabled"
+	TOTPNotEnabled              = "two-factor authentication is not enabled"
+	TOTPNotEnrolled             = "start two-factor enrollment first"
+	InvalidMFACode              = "invalid two-factor code"
+	TooManyMFAAttempts          = "too many invalid two-factor codes, try again in %d seconds"
+)
+
+const (
+	TOTPEnrollmentStarted    = "scan the provisioning uri with an authenticator app and confirm with a code"
+	TOTPEnabledSuccessfully  = "two-factor authentication enabled, store the recovery codes safely"
+	TOTPDisabledSuccessfully = "two-factor authentication disabled"
+	RecoveryCodesRegenerated = "recovery codes regenerated, the previous codes no longer work"
+	MFARequired              = "two-factor code required to complete login"
+	LoginChallengePurpose    = "mfa_login"
+	TOTPIssuer               = "Blog API"
+	MFAChallengeTTL          = 5 * time.Minute
+	TOTPSkew                 = 1
+	RecoveryCodeCount        = 10
+	RecoveryCodeBytes        = 10
+	RecoveryCodeGroupSize    = 8
+	MFABackoffPruneInterval  = 10 * time.Minute
+	MFAFreeAttempts          = 5
+	MFABaseDelay             = time.Second
+	MFAMaxDelay              = 5 * time.Minute
+	MFAForgetAfter           = 15 * time.Minute
+)
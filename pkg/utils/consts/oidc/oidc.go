@@ -0,0 +1,24 @@
+package oidcconsts
+
+import "time"
+
+const (
+	UnknownProvider          = "unknown login provider"
+	InvalidState             = "invalid or expired login state"
+	ProviderLoginFailed      = "login with the provider failed"
+	ProviderEmailNotVerified = "the provider did not verify the email address"
+	LocalEmailNotVerified    = "an account with this email exists but its email is not verified, log in with the password to link the provider"
+	ErrorStartingLogin       = "error starting provider login"
+	ErrorCompletingLogin     = "error completing provider login"
+	ProviderRequired         = "required provider"
+	CodeAndStateRequired     = "required code and state"
+)
+
+const (
+	ProvidersFetchSuccessfully = "login providers fetched successfully"
+	Provider                   = "provider"
+	AuthStateTTL               = 10 * time.Minute
+	AuthStatePurgeInterval     = time.Hour
+	StateBytes                 = 32
+	CodeVerifierBytes          = 32
+)
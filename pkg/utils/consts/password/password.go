@@ -0,0 +1,20 @@
+package passwordconsts
+
+import "time"
+
+const (
+	ErrorRequestingPasswordReset = "error requesting password reset"
+	ErrorResettingPassword       = "error resetting password"
+	InvalidResetToken            = "invalid or expired reset token"
+)
+
+const (
+	PasswordResetRequested     = "if the email is registered, a password reset link has been sent"
+	PasswordResetSuccessfully  = "password reset successfully, all sessions have been signed out"
+	PasswordResetMailSubject   = "Reset your Blog API password"
+	PasswordResetMailBody      = "Someone asked to reset the password of your Blog API account.\n\nOpen %s to choose a new password, or use this reset token: %s\n\nThe link expires in %d minutes and works once. If you did not ask for it, ignore this mail."
+	PasswordResetTokenPurpose  = "password_reset"
+	PasswordResetPath          = "/reset-password?token="
+	PasswordResetTokenTTL      = 30 * time.Minute
+	PasswordResetTokenByteSize = 32
+)
@@ -0,0 +1,35 @@
+package tokenconsts
+
+import "time"
+
+const (
+	InvalidRefreshToken   = "invalid refresh token"
+	RefreshTokenExpired   = "refresh token expired"
+	RefreshTokenReused    = "refresh token reuse detected, session revoked"
+	DeviceMismatch        = "refresh token was issued to another device"
+	SessionNotFound       = "session not found"
+	ErrorRefreshingToken  = "error refreshing token"
+	ErrorGettingSessions  = "error getting sessions"
+	ErrorRevokingSession  = "error revoking session"
+	SessionIDRequired     = "required session id"
+	ErrorIssuingTokenPair = "error issuing token pair"
+	TokenAlreadyUsed      = "token has already been used"
+	InvalidChallengeToken = "invalid or expired challenge token"
+)
+
+const (
+	TokenRefreshedSuccessfully = "token refreshed successfully"
+	SessionsFetchSuccessfully  = "sessions fetched successfully"
+	SessionRevokedSuccessfully = "session revoked successfully"
+)
+
+const (
+	SessionID = "session_id"
+	TokenType = "Bearer"
+)
+
+const (
+	AccessTokenTTL    = 15 * time.Minute
+	RefreshTokenTTL   = 30 * 24 * time.Hour
+	RefreshTokenBytes = 32
+)
@@ -1,5 +1,7 @@
 package userconsts
 
+import "time"
+
 const (
 	InvalidEmailOrPassword  = "user invalid email or password"
 	ErrorGeneratingToken    = "error generating token"
@@ -13,6 +15,33 @@ const (
 	ErrorDeletingUser       = "error deleting user"
 	UserNotFound            = "user not found"
 	LogoutFailed            = "user log out Failed"
+	ErrorRevokingToken      = "error revoking token"
+	ErrorUpdatingUserRole   = "error updating user role"
+	InvalidRole             = "invalid role"
+	TooManyLoginAttempts    = "too many failed login attempts, retry in %d seconds"
+	AccountLocked           = "account locked until %s after too many failed login attempts"
+	ErrorUnlockingUser      = "error unlocking user"
+)
+
+// Failed login audit reasons
+const (
+	ReasonUnknownEmail     = "unknown_email"
+	ReasonWrongPassword    = "wrong_password"
+	ReasonAccountLocked    = "account_locked"
+	ReasonAccountThrottled = "account_throttled"
+	ReasonIPThrottled      = "ip_throttled"
+)
+
+// Failed login protection defaults
+const (
+	FreeLoginAttempts       = 3
+	LoginBackoffBaseDelay   = time.Second
+	LoginBackoffMaxDelay    = 5 * time.Minute
+	LoginFailuresForgetTime = time.Hour
+	IPFreeLoginAttempts     = 10
+	LockoutThreshold        = 10
+	LockoutDuration         = 15 * time.Minute
+	LoginBackoffPruneTime   = 10 * time.Minute
 )
 
 const (
@@ -20,16 +49,24 @@ const (
 )
 
 const (
-	UserFetchSuccessfully   = "user fetched successfully"
-	UsersFetchSuccessfully  = "users fetched successfully"
-	UserUpdatedSuccessfully = "user updated successfully"
-	UserDeletedSuccessfully = "user deleted successfully"
-	LogoutSuccessful        = "user log out successful"
-	LoginSuccessful         = "user login successful"
+	UserFetchSuccessfully    = "user fetched successfully"
+	UsersFetchSuccessfully   = "users fetched successfully"
+	UserUpdatedSuccessfully  = "user updated successfully"
+	UserDeletedSuccessfully  = "user deleted successfully"
+	LogoutSuccessful         = "user log out successful"
+	LoginSuccessful          = "user login successful"
+	UserRoleUpdated          = "user role updated successfully"
+	UserUnlockedSuccessfully = "user unlocked successfully"
 )
 
 const (
 	UserID         = "user_id"
 	UserEmail      = "user_email"
+	TokenID        = "token_id"
+	TokenExpiresAt = "token_expires_at"
+	SessionID      = "session_id"
+	UserRole       = "user_role"
+	APIKeyID       = "api_key_id"
+	APIKeyScopes   = "api_key_scopes"
 	ProfilePicture = "profile_picture"
 )
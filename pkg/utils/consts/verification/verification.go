@@ -0,0 +1,30 @@
+package verificationconsts
+
+import "time"
+
+const (
+	ErrorVerifyingEmail         = "error verifying email"
+	ErrorSendingVerification    = "error sending verification email"
+	InvalidVerificationToken    = "invalid or expired verification token"
+	EmailAlreadyVerified        = "email is already verified"
+	TooManyVerificationRequests = "too many verification emails requested, try again later"
+	EmailNotVerified            = "verify your email address first"
+)
+
+const (
+	EmailVerifiedSuccessfully = "email verified successfully"
+	VerificationSent          = "verification email sent"
+	VerificationMailSubject   = "Verify your Blog API email address"
+	VerificationMailBody      = "Welcome to Blog API!\n\nOpen %s to verify your email address, or use this verification token: %s\n\nThe link expires in %d hours."
+	VerificationTokenPurpose  = "email_verification"
+	VerificationPath          = "/verify-email?token="
+	VerificationTokenTTL      = 24 * time.Hour
+	VerificationTokenByteSize = 32
+)
+
+// Resend throttling, per user
+const (
+	ResendCooldown      = time.Minute
+	ResendWindow        = time.Hour
+	MaxResendsPerWindow = 5
+)
@@ -0,0 +1,151 @@
+package utils
+
+import "strings"
+
+// Operations of a DiffLine
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// DiffLine is one line of a line diff, FromLine and ToLine are 1-based and 0 when the line is not on that side
+type DiffLine struct {
+	Op       string
+	Text     string
+	FromLine int
+	ToLine   int
+}
+
+// LineDiff returns the shortest edit script turning a into b line by line (Myers' algorithm), so
+// the memory stays proportional to the square of the number of changes and not to the size of the texts.
+func LineDiff(a, b string) []DiffLine {
+
+	x, y := splitLines(a), splitLines(b)
+
+	// Common ends are the usual case for edits and do not need the search
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]DiffLine, 0, len(x)+len(y))
+	for i := 0; i < prefix; i++ {
+		lines = append(lines, DiffLine{Op: DiffEqual, Text: x[i], FromLine: i + 1, ToLine: i + 1})
+	}
+
+	middle := myers(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix])
+	for _, line := range middle {
+		if line.FromLine > 0 {
+			line.FromLine += prefix
+		}
+		if line.ToLine > 0 {
+			line.ToLine += prefix
+		}
+		lines = append(lines, line)
+	}
+
+	for i := suffix; i > 0; i-- {
+		lines = append(lines, DiffLine{Op: DiffEqual, Text: x[len(x)-i], FromLine: len(x) - i + 1, ToLine: len(y) - i + 1})
+	}
+
+	return lines
+}
+
+func myers(x, y []string) []DiffLine {
+
+	n, m := len(x), len(y)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	// v[k] is the furthest x reached on diagonal k, trace keeps v[-d..d] of every round for the walk back
+	max := n + m
+	v := make([]int, 2*max+2)
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= max && !found; d++ {
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[max-d:max+d+1])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var i int
+			if k == -d || (k != d && v[max+k-1] < v[max+k+1]) {
+				i = v[max+k+1]
+			} else {
+				i = v[max+k-1] + 1
+			}
+			j := i - k
+			for i < n && j < m && x[i] == y[j] {
+				i++
+				j++
+			}
+			v[max+k] = i
+			if i >= n && j >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	var reversed []DiffLine
+	i, j := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		prev := trace[d]
+		at := func(k int) int { return prev[k+d] }
+
+		k := i - j
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevI := 0
+		if d > 0 {
+			prevI = at(prevK)
+		}
+		prevJ := prevI - prevK
+		if d == 0 {
+			prevJ = 0
+		}
+
+		for i > prevI && j > prevJ {
+			reversed = append(reversed, DiffLine{Op: DiffEqual, Text: x[i-1], FromLine: i, ToLine: j})
+			i--
+			j--
+		}
+
+		if d > 0 {
+			if i == prevI {
+				reversed = append(reversed, DiffLine{Op: DiffInsert, Text: y[j-1], ToLine: j})
+			} else {
+				reversed = append(reversed, DiffLine{Op: DiffDelete, Text: x[i-1], FromLine: i})
+			}
+		}
+		i, j = prevI, prevJ
+	}
+
+	lines := make([]DiffLine, len(reversed))
+	for idx, line := range reversed {
+		lines[len(reversed)-1-idx] = line
+	}
+
+	return lines
+}
+
+func splitLines(text string) []string {
+
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLineDiff(t *testing.T) {
+
+	tests := []struct {
+		name string
+		a, b string
+		want []DiffLine
+	}{
+		{
+			name: "both empty",
+			a:    "",
+			b:    "",
+			want: []DiffLine{},
+		},
+		{
+			name: "same text",
+			a:    "one\ntwo",
+			b:    "one\ntwo",
+			want: []DiffLine{
+				{Op: DiffEqual, Text: "one", FromLine: 1, ToLine: 1},
+				{Op: DiffEqual, Text: "two", FromLine: 2, ToLine: 2},
+			},
+		},
+		{
+			name: "from nothing",
+			a:    "",
+			b:    "one\ntwo",
+			want: []DiffLine{
+				{Op: DiffInsert, Text: "one", ToLine: 1},
+				{Op: DiffInsert, Text: "two", ToLine: 2},
+			},
+		},
+		{
+			name: "to nothing",
+			a:    "one\ntwo",
+			b:    "",
+			want: []DiffLine{
+				{Op: DiffDelete, Text: "one", FromLine: 1},
+				{Op: DiffDelete, Text: "two", FromLine: 2},
+			},
+		},
+		{
+			name: "line changed in the middle",
+			a:    "one\ntwo\nthree",
+			b:    "one\n2\nthree",
+			want: []DiffLine{
+				{Op: DiffEqual, Text: "one", FromLine: 1, ToLine: 1},
+				{Op: DiffDelete, Text: "two", FromLine: 2},
+				{Op: DiffInsert, Text: "2", ToLine: 2},
+				{Op: DiffEqual, Text: "three", FromLine: 3, ToLine: 3},
+			},
+		},
+		{
+			name: "line added at the end",
+			a:    "one\ntwo",
+			b:    "one\ntwo\nthree",
+			want: []DiffLine{
+				{Op: DiffEqual, Text: "one", FromLine: 1, ToLine: 1},
+				{Op: DiffEqual, Text: "two", FromLine: 2, ToLine: 2},
+				{Op: DiffInsert, Text: "three", ToLine: 3},
+			},
+		},
+		{
+			name: "line removed at the start",
+			a:    "zero\none\ntwo",
+			b:    "one\ntwo",
+			want: []DiffLine{
+				{Op: DiffDelete, Text: "zero", FromLine: 1},
+				{Op: DiffEqual, Text: "one", FromLine: 2, ToLine: 1},
+				{Op: DiffEqual, Text: "two", FromLine: 3, ToLine: 2},
+			},
+		},
+		{
+			name: "windows line endings are the same lines",
+			a:    "one\r\ntwo",
+			b:    "one\ntwo",
+			want: []DiffLine{
+				{Op: DiffEqual, Text: "one", FromLine: 1, ToLine: 1},
+				{Op: DiffEqual, Text: "two", FromLine: 2, ToLine: 2},
+			},
+		},
+		{
+			name: "trailing newline is an empty last line",
+			a:    "one",
+			b:    "one\n",
+			want: []DiffLine{
+				{Op: DiffEqual, Text: "one", FromLine: 1, ToLine: 1},
+				{Op: DiffInsert, Text: "", ToLine: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LineDiff(tt.a, tt.b)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("LineDiff(%q, %q) =\n%+v\nwant\n%+v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLineDiffIsShortest checks random texts: both sides can be read back from the diff, the line
+// numbers count up on each side and the number of edits is the smallest possible.
+func TestLineDiffIsShortest(t *testing.T) {
+
+	random := rand.New(rand.NewSource(1))
+	randomText := func() string {
+		lines := make([]string, random.Intn(12))
+		for i := range lines {
+			lines[i] = string(rune('a' + random.Intn(4)))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	for round := 0; round < 500; round++ {
+		a, b := randomText(), randomText()
+		diff := LineDiff(a, b)
+
+		var from, to []string
+		edits := 0
+		for _, line := range diff {
+			switch line.Op {
+			case DiffEqual:
+				from, to = append(from, line.Text), append(to, line.Text)
+			case DiffDelete:
+				from = append(from, line.Text)
+				edits++
+			case DiffInsert:
+				to = append(to, line.Text)
+				edits++
+			}
+			if line.Op != DiffInsert && line.FromLine != len(from) {
+				t.Fatalf("LineDiff(%q, %q): %+v has FromLine %d, want %d", a, b, line, line.FromLine, len(from))
+			}
+			if line.Op != DiffDelete && line.ToLine != len(to) {
+				t.Fatalf("LineDiff(%q, %q): %+v has ToLine %d, want %d", a, b, line, line.ToLine, len(to))
+			}
+		}
+
+		if strings.Join(from, "\n") != a || strings.Join(to, "\n") != b {
+			t.Fatalf("LineDiff(%q, %q) reads back as %q and %q", a, b, strings.Join(from, "\n"), strings.Join(to, "\n"))
+		}
+
+		x, y := splitLines(a), splitLines(b)
+		if want := len(x) + len(y) - 2*longestCommon(x, y); edits != want {
+			t.Fatalf("LineDiff(%q, %q) has %d edits, want %d", a, b, edits, want)
+		}
+	}
+}
+
+// longestCommon is the length of the longest common subsequence of x and y
+func longestCommon(x, y []string) int {
+
+	lengths := make([][]int, len(x)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(y)+1)
+	}
+
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] > lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	return lengths[0][0]
+}
@@ -6,11 +6,33 @@ import (
 	"net/http"
 )
 
+// StatusError is an error that carries the HTTP status code it should be reported with
+type StatusError struct {
+	Code    int
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return e.Message
+}
+
+// NewStatusError returns an error that is reported with the given HTTP status code
+func NewStatusError(code int, message string) error {
+	return &StatusError{
+		Code:    code,
+		Message: message,
+	}
+}
+
 // StatusCode returns the appropriate HTTP status code based on the error type.
 func StatusCode(err error) int {
 	var he *echo.HTTPError
 	if errors.As(err, &he) {
 		return he.Code
 	}
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.Code
+	}
 	return http.StatusInternalServerError
 }
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"Blog_API/pkg/utils/consts"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AnyVersion is what IfMatchVersion returns for "If-Match: *"
+const AnyVersion uint = 0
+
+// VersionETag is the strong entity tag of a version of a resource
+func VersionETag(version uint) string {
+	return fmt.Sprintf(`"v%d"`, version)
+}
+
+// SetVersionETag sends the version of the resource of the response as its ETag
+func SetVersionETag(c echo.Context, version uint) {
+	c.Response().Header().Set(consts.HeaderETag, VersionETag(version))
+}
+
+// IfMatchVersion returns the version named by the If-Match header of a write. A missing header is
+// refused with 428, so that clients can not overwrite changes they have not seen by leaving it out.
+func IfMatchVersion(c echo.Context) (uint, error) {
+
+	header := strings.TrimSpace(c.Request().Header.Get(consts.HeaderIfMatch))
+	if header == "" {
+		return 0, NewStatusError(http.StatusPreconditionRequired, consts.IfMatchRequired)
+	}
+
+	if header == "*" {
+		return AnyVersion, nil
+	}
+
+	if strings.Contains(header, ",") {
+		return 0, NewStatusError(http.StatusBadRequest, consts.InvalidIfMatch)
+	}
+
+	// Weak tags never match for If-Match, and anything after a dash belongs to tags of reads that also carry a timestamp
+	tag := strings.Trim(header, `"`)
+	if strings.HasPrefix(header, "W/") || !strings.HasPrefix(tag, "v") {
+		return 0, NewStatusError(http.StatusPreconditionFailed, consts.VersionMismatch)
+	}
+
+	tag = strings.SplitN(tag[1:], "-", 2)[0]
+	version, err := strconv.ParseUint(tag, 10, 32)
+	if err != nil || version == 0 {
+		return 0, NewStatusError(http.StatusPreconditionFailed, consts.VersionMismatch)
+	}
+
+	return uint(version), nil
+}
+
+// CheckVersion refuses a write made against another version than the current one
+func CheckVersion(expected uint, current uint) error {
+
+	if expected != AnyVersion && expected != current {
+		return NewStatusError(http.StatusPreconditionFailed, consts.VersionMismatch)
+	}
+
+	return nil
+}
+
+// RepresentationETag is the entity tag of a read that also shows data without a version of its
+// own, like the comments of a post. It still starts with the version, so it works as If-Match.
+func RepresentationETag(version uint, lastModified time.Time) string {
+	return fmt.Sprintf(`"v%d-%d"`, version, lastModified.UnixMilli())
+}
+
+// ListETag is the weak entity tag of a list, made from the number of items and their latest change
+func ListETag(count int64, lastModified time.Time) string {
+	return fmt.Sprintf(`W/"%d-%d"`, count, lastModified.UnixMilli())
+}
+
+// NotModified sets the validators of a read and tells whether the client already holds the same
+// representation. If-None-Match is compared weakly and, when present, If-Modified-Since is ignored.
+func NotModified(c echo.Context, etag string, lastModified time.Time) bool {
+
+	header := c.Response().Header()
+	if etag != "" {
+		header.Set(consts.HeaderETag, etag)
+	}
+	if !lastModified.IsZero() {
+		header.Set(echo.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	request := c.Request()
+	if request.Method != http.MethodGet && request.Method != http.MethodHead {
+		return false
+	}
+
+	if ifNoneMatch := request.Header.Get(consts.HeaderIfNoneMatch); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || (etag != "" && strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/")) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ifModifiedSince := request.Header.Get(echo.HeaderIfModifiedSince); ifModifiedSince != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ifModifiedSince)
+		return err == nil && !lastModified.Truncate(time.Second).After(since)
+	}
+
+	return false
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Errors of ApplyMergePatch, all of them are reported as 400
+const (
+	InvalidMergePatch      = "merge patch must be a JSON object"
+	UnknownMergePatchField = "merge patch contains a field that can not be changed"
+)
+
+// MergePatch applies an RFC 7396 JSON merge patch to a decoded JSON document: members of the
+// patch replace those of the target, null removes them and objects are merged recursively.
+func MergePatch(target interface{}, patch interface{}) interface{} {
+
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = MergePatch(targetObject[name], value)
+	}
+
+	return targetObject
+}
+
+// ApplyMergePatch merges the patch into doc, a pointer to a struct, and returns the Go names of
+// the fields the patch touched. Fields a patch removes with null are left at their zero value, and
+// members that doc has no field for are rejected rather than dropped silently.
+func ApplyMergePatch(doc interface{}, patch []byte) ([]string, error) {
+
+	var patchObject map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(patch))
+	decoder.UseNumber()
+	if err := decoder.Decode(&patchObject); err != nil || patchObject == nil {
+		return nil, NewStatusError(http.StatusBadRequest, InvalidMergePatch)
+	}
+
+	current, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var target map[string]interface{}
+	decoder = json.NewDecoder(bytes.NewReader(current))
+	decoder.UseNumber()
+	if err := decoder.Decode(&target); err != nil {
+		return nil, err
+	}
+
+	merged, err := json.Marshal(MergePatch(target, patchObject))
+	if err != nil {
+		return nil, err
+	}
+
+	// A fresh value, so that members removed by the patch end up as zero values
+	docValue := reflect.ValueOf(doc).Elem()
+	fresh := reflect.New(docValue.Type())
+	decoder = json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(fresh.Interface()); err != nil {
+		if strings.Contains(err.Error(), "unknown field") {
+			return nil, NewStatusError(http.StatusBadRequest, UnknownMergePatchField)
+		}
+		return nil, NewStatusError(http.StatusBadRequest, err.Error())
+	}
+	docValue.Set(fresh.Elem())
+
+	fields := make([]string, 0, len(patchObject))
+	for i := 0; i < docValue.NumField(); i++ {
+		field := docValue.Type().Field(i)
+		if _, ok := patchObject[jsonName(field)]; ok {
+			fields = append(fields, field.Name)
+		}
+	}
+
+	return fields, nil
+}
+
+func jsonName(field reflect.StructField) string {
+
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
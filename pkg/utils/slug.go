@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"golang.org/x/text/unicode/norm"
+	"strings"
+	"unicode"
+)
+
+// MaxSlugLength is counted in runes so that non-Latin titles get the same room as Latin ones
+const MaxSlugLength = 200
+
+// Slugify turns a title into a lowercase, hyphen separated URL segment. Letters and digits of
+// every script are kept; accents are dropped from Latin letters only ("Crème Brûlée" becomes
+// "creme-brulee"), since the combining marks of scripts like Bengali or Devanagari are part of
+// the word.
+func Slugify(title string) string {
+
+	var b strings.Builder
+	var lastBase rune
+	pendingHyphen := false
+	length := 0
+
+	for _, r := range norm.NFKD.String(title) {
+		switch {
+		case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Mc, r) || unicode.Is(unicode.Me, r):
+			if lastBase == 0 || unicode.Is(unicode.Latin, lastBase) {
+				continue
+			}
+			b.WriteRune(r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			// The hyphen starting a new word has to fit as well
+			hyphen := pendingHyphen && b.Len() > 0
+			needed := 1
+			if hyphen {
+				needed++
+			}
+			if length+needed > MaxSlugLength {
+				return finishSlug(b.String())
+			}
+			if hyphen {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(unicode.ToLower(r))
+			lastBase = r
+			length += needed
+		default:
+			pendingHyphen = true
+			lastBase = 0
+		}
+	}
+
+	return finishSlug(b.String())
+}
+
+func finishSlug(slug string) string {
+	return norm.NFC.String(strings.Trim(slug, "-"))
+}
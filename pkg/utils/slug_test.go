@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSlugify(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{name: "words", title: "Hello World", want: "hello-world"},
+		{name: "punctuation and runs of spaces", title: "  Hello,   World!  ", want: "hello-world"},
+		{name: "digits", title: "Go 1.21 Released", want: "go-1-21-released"},
+		{name: "latin accents are dropped", title: "Crème Brûlée", want: "creme-brulee"},
+		{name: "every letter accented", title: "Ünïcödé", want: "unicode"},
+		{name: "compatibility forms are unfolded", title: "ﬁle", want: "file"},
+		{name: "letters without a decomposition are kept", title: "Straße", want: "straße"},
+		{name: "cyrillic", title: "Привет мир", want: "привет-мир"},
+		{name: "marks of other scripts are kept", title: "বাংলা ব্লগ", want: "বাংলা-ব্লগ"},
+		{name: "kana voicing marks are recomposed", title: "データ", want: "データ"},
+		{name: "no letters or digits", title: "--- !!! ---", want: ""},
+		{name: "empty", title: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Slugify(tt.title); got != tt.want {
+				t.Fatalf("Slugify(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlugifyMaxLength(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{name: "one long word is cut", title: strings.Repeat("a", MaxSlugLength+50), want: strings.Repeat("a", MaxSlugLength)},
+		{name: "a long non-latin word is cut in runes", title: strings.Repeat("я", MaxSlugLength+50), want: strings.Repeat("я", MaxSlugLength)},
+		// 100 words of one letter take 199 runes, the hyphen and letter of the next one do not fit
+		{name: "the hyphen of a new word counts", title: strings.Repeat("a ", MaxSlugLength), want: strings.TrimSuffix(strings.Repeat("a-", MaxSlugLength/2), "-")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Slugify(tt.title)
+			if got != tt.want {
+				t.Fatalf("Slugify() = %q (%d runes), want %q (%d runes)", got, utf8.RuneCountInString(got), tt.want, utf8.RuneCountInString(tt.want))
+			}
+			if utf8.RuneCountInString(got) > MaxSlugLength {
+				t.Fatalf("Slugify() is %d runes long, more than %d", utf8.RuneCountInString(got), MaxSlugLength)
+			}
+		})
+	}
+}
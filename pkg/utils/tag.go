@@ -0,0 +1,19 @@
+package utils
+
+import "strings"
+
+// MaxTagLength is counted in runes, like MaxSlugLength
+const MaxTagLength = 50
+
+// NormalizeTag gives the form a tag is stored and matched under. It is the slug of the name, so
+// "Machine Learning", "machine-learning" and "MACHINE_LEARNING" are the same tag. A name without a
+// single letter or digit normalizes to the empty string.
+func NormalizeTag(name string) string {
+
+	tag := []rune(Slugify(name))
+	if len(tag) > MaxTagLength {
+		tag = tag[:MaxTagLength]
+	}
+
+	return strings.Trim(string(tag), "-")
+}
@@ -0,0 +1,94 @@
+package views
+
+import (
+	"sync"
+	"time"
+)
+
+// DayLayout is how the day of a view is kept, days are in UTC
+const DayLayout = "2006-01-02"
+
+// Count is a number of views of a post on one day
+type Count struct {
+	BlogID string
+	Day    string
+	Views  uint
+}
+
+type dayKey struct {
+	blogID string
+	day    string
+}
+
+// Recorder deduplicates views and buffers them in memory, so that a popular post costs one
+// write per flush instead of one per view. A visitor counts once per post within the window.
+type Recorder struct {
+	mu      sync.Mutex
+	window  time.Duration
+	seen    map[string]time.Time // post and visitor to the time their view was counted
+	pending map[dayKey]uint
+}
+
+func NewRecorder(window time.Duration) *Recorder {
+	return &Recorder{
+		window:  window,
+		seen:    make(map[string]time.Time),
+		pending: make(map[dayKey]uint),
+	}
+}
+
+// Record counts a view of the post by the visitor, unless the visitor was counted within the window
+func (r *Recorder) Record(blogID string, visitor string, now time.Time) bool {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := blogID + "|" + visitor
+	if last, ok := r.seen[key]; ok && now.Sub(last) < r.window {
+		return false
+	}
+	r.seen[key] = now
+
+	r.pending[dayKey{blogID: blogID, day: now.UTC().Format(DayLayout)}]++
+
+	return true
+}
+
+// Drain hands out the buffered views and starts a new buffer
+func (r *Recorder) Drain() []Count {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts := make([]Count, 0, len(r.pending))
+	for key, views := range r.pending {
+		counts = append(counts, Count{BlogID: key.blogID, Day: key.day, Views: views})
+	}
+	r.pending = make(map[dayKey]uint)
+
+	return counts
+}
+
+// Restore puts back views that were drained but could not be written, they go out with the next flush
+func (r *Recorder) Restore(counts []Count) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, count := range counts {
+		r.pending[dayKey{blogID: count.BlogID, day: count.Day}] += count.Views
+	}
+}
+
+// Prune forgets the visitors whose window is over, so the memory follows the recent traffic only
+func (r *Recorder) Prune(now time.Time) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, last := range r.seen {
+		if now.Sub(last) >= r.window {
+			delete(r.seen, key)
+		}
+	}
+}
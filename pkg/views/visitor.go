@@ -0,0 +1,45 @@
+package views
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// botMarkers are found in the user agents of crawlers, link previews, monitors and HTTP libraries
+var botMarkers = []string{
+	"bot", "crawl", "spider", "slurp", "scrape", "fetch", "preview", "monitor", "headless",
+	"facebookexternalhit", "embedly", "lighthouse", "pingdom", "uptime",
+	"curl", "wget", "python-requests", "python-urllib", "go-http-client", "okhttp", "java/", "libwww", "httpclient", "axios", "node-fetch",
+}
+
+// IsBot reports whether the user agent is obviously not a person reading the post. A browser always
+// sends a user agent, so an empty one counts as a bot.
+func IsBot(userAgent string) bool {
+
+	userAgent = strings.ToLower(strings.TrimSpace(userAgent))
+	if userAgent == "" {
+		return true
+	}
+
+	for _, marker := range botMarkers {
+		if strings.Contains(userAgent, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Visitor identifies who viewed a post: the user when signed in, otherwise the address and user
+// agent, hashed so the buffer holds no addresses.
+func Visitor(userID string, ip string, userAgent string) string {
+
+	if userID != "" {
+		return "user:" + userID
+	}
+
+	sum := sha256.Sum256([]byte(ip + "\n" + userAgent))
+
+	return "anon:" + hex.EncodeToString(sum[:16])
+}